@@ -0,0 +1,100 @@
+package checkend
+
+import (
+	"fmt"
+	"sync"
+)
+
+// MaxBatchSamples is the number of failed items a BatchReporter includes in
+// its summary notice.
+const MaxBatchSamples = 10
+
+// BatchError is reported by BatchReporter.Finish when items in a batch failed.
+type BatchError struct {
+	Operation string
+	Failed    int
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("batch %s: %d items failed", e.Operation, e.Failed)
+}
+
+// BatchReporter collects per-item failures of a batch operation and reports
+// them as a single summary notice, for jobs where a notice per item would be
+// overwhelming. It is safe for concurrent use.
+//
+//	batch := checkend.NewBatchReporter("import_users")
+//	for _, u := range users {
+//		if err := importUser(u); err != nil {
+//			batch.RecordFailure(u.ID, err)
+//		}
+//	}
+//	batch.Finish()
+type BatchReporter struct {
+	operation string
+
+	mu      sync.Mutex
+	failed  int
+	samples []map[string]interface{}
+}
+
+// NewBatchReporter creates a BatchReporter for the named operation.
+func NewBatchReporter(operation string) *BatchReporter {
+	return &BatchReporter{operation: operation}
+}
+
+// RecordFailure records that the item with the given ID failed with err.
+// The first MaxBatchSamples failures are kept as samples.
+func (b *BatchReporter) RecordFailure(itemID string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failed++
+	if len(b.samples) < MaxBatchSamples {
+		sample := map[string]interface{}{"item_id": itemID}
+		if err != nil {
+			sample["error"] = err.Error()
+		}
+		b.samples = append(b.samples, sample)
+	}
+}
+
+// Failed returns the number of failures recorded since the last Finish.
+func (b *BatchReporter) Failed() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failed
+}
+
+// Finish sends one notice tagged "batch" summarizing the recorded failures,
+// and reports whether it did; nothing is sent if no item failed. The
+// notice's "batch" context holds the operation, the failure count and the
+// sampled item IDs and errors. Notices for the same operation are grouped
+// together regardless of the count. Finish resets the reporter.
+func (b *BatchReporter) Finish(opts ...NotifyOption) bool {
+	b.mu.Lock()
+	failed, samples := b.failed, b.samples
+	b.failed, b.samples = 0, nil
+	b.mu.Unlock()
+
+	if failed == 0 {
+		return false
+	}
+
+	allOpts := append([]NotifyOption{WithFingerprint("batch:" + b.operation)}, opts...)
+	allOpts = append(allOpts,
+		func(o *notifyOptions) {
+			o.Tags = append(o.Tags[:len(o.Tags):len(o.Tags)], "batch")
+		},
+		withContextValues(map[string]interface{}{
+			"batch": map[string]interface{}{
+				"operation":    b.operation,
+				"failed_count": failed,
+				"samples":      samples,
+			},
+		}),
+	)
+
+	Notify(&BatchError{Operation: b.operation, Failed: failed}, allOpts...)
+	return true
+}
@@ -0,0 +1,80 @@
+package checkend
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestBatchReporterSummarizesFailures(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{APIKey: "test-key", Enabled: boolPtr(true)})
+
+	batch := NewBatchReporter("import_users")
+	for i := 1; i <= MaxBatchSamples+5; i++ {
+		batch.RecordFailure(fmt.Sprintf("user-%d", i), errors.New("invalid email"))
+	}
+	if batch.Failed() != MaxBatchSamples+5 {
+		t.Errorf("Expected %d failures, got %d", MaxBatchSamples+5, batch.Failed())
+	}
+
+	if !batch.Finish(WithTags("nightly")) {
+		t.Fatal("Expected Finish to report the batch")
+	}
+	if TestingNoticeCount() != 1 {
+		t.Fatalf("Expected one summary notice, got %d", TestingNoticeCount())
+	}
+
+	notice := TestingLastNotice()
+	if notice.Message != "batch import_users: 15 items failed" {
+		t.Errorf("Unexpected message %q", notice.Message)
+	}
+	if notice.Fingerprint != "batch:import_users" {
+		t.Errorf("Expected fingerprint batch:import_users, got %q", notice.Fingerprint)
+	}
+	if len(notice.Tags) != 2 || notice.Tags[0] != "nightly" || notice.Tags[1] != "batch" {
+		t.Errorf("Expected tags [nightly batch], got %v", notice.Tags)
+	}
+
+	summary, ok := notice.Context["batch"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected batch context, got %v", notice.Context)
+	}
+	if summary["operation"] != "import_users" {
+		t.Errorf("Expected operation import_users, got %v", summary["operation"])
+	}
+	if summary["failed_count"] != 15 {
+		t.Errorf("Expected failed_count 15, got %v", summary["failed_count"])
+	}
+	samples, _ := summary["samples"].([]interface{})
+	if len(samples) != MaxBatchSamples {
+		t.Fatalf("Expected %d samples, got %v", MaxBatchSamples, summary["samples"])
+	}
+	first, _ := samples[0].(map[string]interface{})
+	if first["item_id"] != "user-1" || first["error"] != "invalid email" {
+		t.Errorf("Unexpected first sample %v", samples[0])
+	}
+}
+
+func TestBatchReporterWithoutFailures(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{APIKey: "test-key", Enabled: boolPtr(true)})
+
+	batch := NewBatchReporter("import_users")
+	if batch.Finish() {
+		t.Error("Expected Finish to report nothing")
+	}
+
+	batch.RecordFailure("user-1", errors.New("invalid email"))
+	batch.Finish()
+	if batch.Finish() {
+		t.Error("Expected Finish to reset the reporter")
+	}
+	if TestingNoticeCount() != 1 {
+		t.Errorf("Expected one notice, got %d", TestingNoticeCount())
+	}
+}
@@ -0,0 +1,69 @@
+package checkend
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"time"
+)
+
+// BreadcrumbWriter is an io.Writer that records each line written to it as a
+// breadcrumb in the context it was created for. Set it as the output of a
+// log.Logger scoped to that context, such as one created per request, to
+// bridge existing log-based code into breadcrumbs.
+type BreadcrumbWriter struct {
+	buffer *breadcrumbBuffer
+	next   io.Writer
+}
+
+// NewBreadcrumbWriter returns a writer that records log lines as breadcrumbs
+// in ctx, along with the context the breadcrumbs are attached to. Writes are
+// passed through to next when it is non-nil.
+//
+// Every line written is attached to the returned context, whichever
+// goroutine or request wrote it, so don't make it the output of a logger
+// shared across requests, such as the standard logger. Create one per
+// request instead, most simply with NewBreadcrumbLogger.
+func NewBreadcrumbWriter(ctx context.Context, next io.Writer) (context.Context, *BreadcrumbWriter) {
+	ctx, buffer := withBreadcrumbBuffer(ctx)
+	return ctx, &BreadcrumbWriter{buffer: buffer, next: next}
+}
+
+// NewBreadcrumbLogger returns a log.Logger whose output is recorded as
+// breadcrumbs in the returned context. Create one per request and use it
+// for that request's logging:
+//
+//	func handle(w http.ResponseWriter, r *http.Request) {
+//	    ctx, logger := checkend.NewBreadcrumbLogger(r.Context(), os.Stderr, "", log.LstdFlags)
+//	    logger.Printf("handling %s", r.URL.Path)
+//	    if err := process(ctx); err != nil {
+//	        checkend.NotifyWithContext(ctx, err)
+//	    }
+//	}
+func NewBreadcrumbLogger(ctx context.Context, next io.Writer, prefix string, flag int) (context.Context, *log.Logger) {
+	ctx, w := NewBreadcrumbWriter(ctx, next)
+	return ctx, log.New(w, prefix, flag)
+}
+
+// Write records every non-empty line in p as a "log" breadcrumb.
+func (w *BreadcrumbWriter) Write(p []byte) (int, error) {
+	now := time.Now().UTC()
+	for _, line := range bytes.Split(p, []byte("\n")) {
+		line = bytes.TrimRight(line, "\r")
+		if len(line) == 0 {
+			continue
+		}
+		w.buffer.add(Breadcrumb{
+			Timestamp: now,
+			Category:  "log",
+			Message:   string(line),
+			Level:     "info",
+		})
+	}
+
+	if w.next != nil {
+		return w.next.Write(p)
+	}
+	return len(p), nil
+}
@@ -0,0 +1,57 @@
+package checkend
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestBreadcrumbWriterRecordsLines(t *testing.T) {
+	var out bytes.Buffer
+	ctx, w := NewBreadcrumbWriter(context.Background(), &out)
+
+	w.Write([]byte("first line\nsecond line\n"))
+
+	breadcrumbs := Breadcrumbs(ctx)
+	if len(breadcrumbs) != 2 {
+		t.Fatalf("Expected 2 breadcrumbs, got %d", len(breadcrumbs))
+	}
+	if breadcrumbs[1].Message != "second line" {
+		t.Errorf("Expected 'second line', got '%s'", breadcrumbs[1].Message)
+	}
+	if breadcrumbs[0].Category != "log" {
+		t.Errorf("Expected category 'log', got '%s'", breadcrumbs[0].Category)
+	}
+	if out.String() != "first line\nsecond line\n" {
+		t.Errorf("Expected output to be passed through, got '%s'", out.String())
+	}
+}
+
+func TestBreadcrumbLoggerAttachesToNotice(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{
+		APIKey:    "test-key",
+		Enabled:   boolPtr(true),
+		AsyncSend: false,
+	})
+
+	ctx, logger := NewBreadcrumbLogger(context.Background(), nil, "", 0)
+	logger.Println("fetching order 42")
+	logger.Printf("charging card for order %d", 42)
+
+	NotifyWithContext(ctx, errors.New("payment failed"))
+
+	notice := TestingLastNotice()
+	if len(notice.Breadcrumbs) != 2 {
+		t.Fatalf("Expected 2 breadcrumbs, got %d", len(notice.Breadcrumbs))
+	}
+	if notice.Breadcrumbs[0].Message != "fetching order 42" {
+		t.Errorf("Expected 'fetching order 42', got '%s'", notice.Breadcrumbs[0].Message)
+	}
+	if notice.Breadcrumbs[1].Message != "charging card for order 42" {
+		t.Errorf("Expected 'charging card for order 42', got '%s'", notice.Breadcrumbs[1].Message)
+	}
+}
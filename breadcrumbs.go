@@ -0,0 +1,105 @@
+package checkend
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultMaxBreadcrumbs is the default number of breadcrumbs kept per context.
+const DefaultMaxBreadcrumbs = 30
+
+// Breadcrumb records an event that happened before an error was reported.
+type Breadcrumb struct {
+	Timestamp time.Time              `json:"timestamp"`
+	Category  string                 `json:"category,omitempty"`
+	Message   string                 `json:"message"`
+	Level     string                 `json:"level,omitempty"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+}
+
+// breadcrumbBuffer is a bounded ring buffer of breadcrumbs. It is shared by
+// every context derived from the one it was attached to, so it is safe for
+// concurrent use.
+type breadcrumbBuffer struct {
+	mu    sync.Mutex
+	items []Breadcrumb
+	start int
+	count int
+}
+
+func newBreadcrumbBuffer(size int) *breadcrumbBuffer {
+	if size <= 0 {
+		size = DefaultMaxBreadcrumbs
+	}
+	return &breadcrumbBuffer{items: make([]Breadcrumb, size)}
+}
+
+// add appends a breadcrumb, evicting the oldest one when the buffer is full.
+func (b *breadcrumbBuffer) add(breadcrumb Breadcrumb) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.count < len(b.items) {
+		b.items[(b.start+b.count)%len(b.items)] = breadcrumb
+		b.count++
+		return
+	}
+
+	b.items[b.start] = breadcrumb
+	b.start = (b.start + 1) % len(b.items)
+}
+
+// list returns the buffered breadcrumbs, oldest first.
+func (b *breadcrumbBuffer) list() []Breadcrumb {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	result := make([]Breadcrumb, b.count)
+	for i := 0; i < b.count; i++ {
+		result[i] = b.items[(b.start+i)%len(b.items)]
+	}
+	return result
+}
+
+// AddBreadcrumb records a breadcrumb in the given context. The first call
+// attaches a new buffer to the context; subsequent calls on the returned
+// context (or any context derived from it) append to the same buffer.
+func AddBreadcrumb(ctx context.Context, breadcrumb Breadcrumb) context.Context {
+	if breadcrumb.Timestamp.IsZero() {
+		breadcrumb.Timestamp = time.Now().UTC()
+	}
+
+	ctx, buffer := withBreadcrumbBuffer(ctx)
+	buffer.add(breadcrumb)
+	return ctx
+}
+
+// withBreadcrumbBuffer returns the breadcrumb buffer attached to ctx,
+// attaching a new one if none exists yet.
+func withBreadcrumbBuffer(ctx context.Context) (context.Context, *breadcrumbBuffer) {
+	ctxData := contextData(ctx)
+	if ctxData.breadcrumbs != nil {
+		return ctx, ctxData.breadcrumbs
+	}
+
+	newData := ctxData.clone()
+	newData.breadcrumbs = newBreadcrumbBuffer(maxBreadcrumbs())
+	return WithContextData(ctx, newData), newData.breadcrumbs
+}
+
+// Breadcrumbs returns the breadcrumbs recorded in the given context, oldest first.
+func Breadcrumbs(ctx context.Context) []Breadcrumb {
+	ctxData := contextData(ctx)
+	if ctxData.breadcrumbs == nil {
+		return nil
+	}
+	return ctxData.breadcrumbs.list()
+}
+
+func maxBreadcrumbs() int {
+	if cfg := GetConfiguration(); cfg != nil {
+		return cfg.MaxBreadcrumbs
+	}
+	return DefaultMaxBreadcrumbs
+}
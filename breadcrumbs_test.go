@@ -0,0 +1,114 @@
+package checkend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestAddBreadcrumb(t *testing.T) {
+	ctx := AddBreadcrumb(context.Background(), Breadcrumb{
+		Category: "http",
+		Message:  "GET /orders",
+	})
+	ctx = AddBreadcrumb(ctx, Breadcrumb{Message: "loaded order"})
+
+	breadcrumbs := Breadcrumbs(ctx)
+	if len(breadcrumbs) != 2 {
+		t.Fatalf("Expected 2 breadcrumbs, got %d", len(breadcrumbs))
+	}
+	if breadcrumbs[0].Message != "GET /orders" {
+		t.Errorf("Expected first breadcrumb 'GET /orders', got '%s'", breadcrumbs[0].Message)
+	}
+	if breadcrumbs[0].Timestamp.IsZero() {
+		t.Error("Expected timestamp to be set")
+	}
+}
+
+func TestBreadcrumbsEvictOldest(t *testing.T) {
+	defer Reset()
+
+	Configure(Config{
+		APIKey:         "test-key",
+		Enabled:        boolPtr(false),
+		MaxBreadcrumbs: 3,
+	})
+
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		ctx = AddBreadcrumb(ctx, Breadcrumb{Message: fmt.Sprintf("event %d", i)})
+	}
+
+	breadcrumbs := Breadcrumbs(ctx)
+	if len(breadcrumbs) != 3 {
+		t.Fatalf("Expected 3 breadcrumbs, got %d", len(breadcrumbs))
+	}
+	if breadcrumbs[0].Message != "event 2" {
+		t.Errorf("Expected oldest breadcrumb 'event 2', got '%s'", breadcrumbs[0].Message)
+	}
+	if breadcrumbs[2].Message != "event 4" {
+		t.Errorf("Expected newest breadcrumb 'event 4', got '%s'", breadcrumbs[2].Message)
+	}
+}
+
+func TestBreadcrumbsSharedAcrossGoroutines(t *testing.T) {
+	ctx := AddBreadcrumb(context.Background(), Breadcrumb{Message: "start"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			AddBreadcrumb(ctx, Breadcrumb{Message: fmt.Sprintf("worker %d", i)})
+		}(i)
+	}
+	wg.Wait()
+
+	if len(Breadcrumbs(ctx)) != 11 {
+		t.Errorf("Expected 11 breadcrumbs, got %d", len(Breadcrumbs(ctx)))
+	}
+}
+
+func TestBreadcrumbsSurviveContextUpdates(t *testing.T) {
+	ctx := AddBreadcrumb(context.Background(), Breadcrumb{Message: "first"})
+	ctx = SetUser(ctx, map[string]interface{}{"id": "user-1"})
+	ctx = AddBreadcrumb(ctx, Breadcrumb{Message: "second"})
+
+	if len(Breadcrumbs(ctx)) != 2 {
+		t.Errorf("Expected 2 breadcrumbs, got %d", len(Breadcrumbs(ctx)))
+	}
+}
+
+func TestBreadcrumbsAttachedToNotice(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{
+		APIKey:    "test-key",
+		Enabled:   boolPtr(true),
+		AsyncSend: false,
+	})
+
+	ctx := AddBreadcrumb(context.Background(), Breadcrumb{
+		Category: "auth",
+		Message:  "user logged in",
+		Data:     map[string]interface{}{"password": "secret123"},
+	})
+
+	NotifyWithContext(ctx, errors.New("test error"))
+
+	notice := TestingLastNotice()
+	if len(notice.Breadcrumbs) != 1 {
+		t.Fatalf("Expected 1 breadcrumb, got %d", len(notice.Breadcrumbs))
+	}
+	if notice.Breadcrumbs[0].Data["password"] != "[FILTERED]" {
+		t.Errorf("Expected breadcrumb data to be filtered, got %v", notice.Breadcrumbs[0].Data["password"])
+	}
+
+	payload := notice.ToPayload()
+	if len(payload.Breadcrumbs) != 1 {
+		t.Errorf("Expected 1 breadcrumb in payload, got %d", len(payload.Breadcrumbs))
+	}
+}
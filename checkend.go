@@ -22,40 +22,72 @@ package checkend
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
 	"sync"
+	"time"
 )
 
 // Version is the SDK version.
 const Version = "0.1.0"
 
 var (
-	config      *Configuration
-	worker      *Worker
-	initialized bool
-	mu          sync.RWMutex
+	// std is the default notifier used by the package-level functions. It
+	// is nil until Configure is called.
+	std *Notifier
+	mu  sync.RWMutex
 )
 
 // Configure initializes the Checkend SDK with the given configuration.
+// Validation problems are logged when Debug is enabled; use ConfigureE to
+// handle them instead.
 func Configure(cfg Config) *Configuration {
-	mu.Lock()
-	defer mu.Unlock()
+	c := NewConfiguration(cfg)
+	if err := ValidateConfiguration(c); err != nil && c.Debug {
+		logMessage(c, "warning", fmt.Sprintf("Invalid configuration: %v", err))
+	}
 
-	config = NewConfiguration(cfg)
+	install(c)
+	return c
+}
 
-	if config.AsyncSend && config.Enabled {
-		worker = NewWorker(config)
-		worker.Start()
+// ConfigureE is like Configure, but validates the configuration first with
+// ValidateConfiguration. An invalid configuration is returned as an error
+// and leaves the SDK unchanged.
+func ConfigureE(cfg Config) (*Configuration, error) {
+	c := NewConfiguration(cfg)
+	if err := ValidateConfiguration(c); err != nil {
+		return nil, err
 	}
 
-	initialized = true
-	return config
+	install(c)
+	return c, nil
+}
+
+// install makes c the active configuration and starts the worker.
+func install(c *Configuration) {
+	n := newNotifier(c)
+
+	mu.Lock()
+	defer mu.Unlock()
+	std = n
 }
 
 // GetConfiguration returns the current configuration.
 func GetConfiguration() *Configuration {
+	if n := defaultNotifier(); n != nil {
+		return n.config
+	}
+	return nil
+}
+
+// defaultNotifier returns the notifier set up by Configure, or nil.
+func defaultNotifier() *Notifier {
 	mu.RLock()
 	defer mu.RUnlock()
-	return config
+	return std
 }
 
 // Notify sends an error to Checkend asynchronously.
@@ -65,43 +97,87 @@ func Notify(err error, opts ...NotifyOption) {
 
 // NotifyWithContext sends an error to Checkend asynchronously with context.
 func NotifyWithContext(ctx context.Context, err error, opts ...NotifyOption) {
-	mu.RLock()
-	defer mu.RUnlock()
-
-	if !initialized || config == nil || !config.Enabled {
-		return
+	if n := defaultNotifier(); n != nil {
+		n.NotifyWithContext(ctx, err, opts...)
 	}
+}
 
-	// Check if error should be ignored
-	if shouldIgnore(err) {
-		return
+// Report sends a non-nil error to Checkend asynchronously and returns it
+// unchanged, enabling one-liners such as:
+//
+//	return checkend.Report(doThing())
+func Report(err error, opts ...NotifyOption) error {
+	if err != nil {
+		Notify(err, opts...)
 	}
+	return err
+}
 
-	// Build notice
-	notice := buildNotice(ctx, err, opts...)
-
-	// Run before notify callbacks
-	if !runBeforeNotify(notice) {
-		return
+// NotifyContextCause reports why ctx was cancelled, for use in cleanup
+// paths. It does nothing unless ctx is done with a cause other than plain
+// context.Canceled, and reports whether a notice was sent. The notice
+// carries the context error and deadline; the cause itself may be set with
+// context.WithCancelCause or context.WithDeadlineCause.
+func NotifyContextCause(ctx context.Context, opts ...NotifyOption) bool {
+	if ctx.Err() == nil {
+		return false
+	}
+	cause := context.Cause(ctx)
+	if cause == nil || cause == context.Canceled {
+		return false
 	}
 
-	// Handle testing mode
-	if testingEnabled {
-		testingMu.Lock()
-		testingNotices = append(testingNotices, notice)
-		testingMu.Unlock()
-		return
+	metadata := map[string]interface{}{"context_error": ctx.Err().Error()}
+	if deadline, ok := ctx.Deadline(); ok {
+		metadata["context_deadline"] = deadline.UTC().Format(time.RFC3339Nano)
 	}
 
-	// Send asynchronously or synchronously
-	if config.AsyncSend && worker != nil {
-		worker.Push(notice)
-	} else {
-		client := NewClient(config)
-		client.Send(notice)
+	// ctx is already done, so report with its values only; otherwise
+	// PropagateContext would drop the notice before it is sent
+	allOpts := append(append([]NotifyOption{}, opts...), withContextValues(metadata))
+	NotifyWithContext(context.WithoutCancel(ctx), cause, allOpts...)
+	return true
+}
+
+// withContextValues merges values into the context data set by WithContext.
+func withContextValues(values map[string]interface{}) NotifyOption {
+	return func(o *notifyOptions) {
+		merged := make(map[string]interface{}, len(o.Context)+len(values))
+		for k, v := range o.Context {
+			merged[k] = v
+		}
+		for k, v := range values {
+			merged[k] = v
+		}
+		o.Context = merged
 	}
 }
 
+// NotifyMessage sends a message-level event that isn't backed by an error value.
+func NotifyMessage(message string, level string, opts ...NotifyOption) {
+	NotifyMessageWithContext(context.Background(), message, level, opts...)
+}
+
+// NotifyMessageWithContext sends a message-level event with context.
+// The notice's error class defaults to "Message"; override it with WithErrorClass.
+func NotifyMessageWithContext(ctx context.Context, message string, level string, opts ...NotifyOption) {
+	allOpts := append([]NotifyOption{
+		WithErrorClass("Message"),
+		WithSeverity(level),
+	}, opts...)
+
+	NotifyWithContext(ctx, &messageError{message: message}, allOpts...)
+}
+
+// messageError carries the text of a message-level notice.
+type messageError struct {
+	message string
+}
+
+func (e *messageError) Error() string {
+	return e.message
+}
+
 // NotifySync sends an error to Checkend synchronously and returns the response.
 func NotifySync(err error, opts ...NotifyOption) *APIResponse {
 	return NotifySyncWithContext(context.Background(), err, opts...)
@@ -109,98 +185,143 @@ func NotifySync(err error, opts ...NotifyOption) *APIResponse {
 
 // NotifySyncWithContext sends an error to Checkend synchronously with context.
 func NotifySyncWithContext(ctx context.Context, err error, opts ...NotifyOption) *APIResponse {
-	mu.RLock()
-	defer mu.RUnlock()
-
-	if !initialized || config == nil || !config.Enabled {
-		return nil
-	}
-
-	// Check if error should be ignored
-	if shouldIgnore(err) {
-		return nil
+	if n := defaultNotifier(); n != nil {
+		return n.NotifySyncWithContext(ctx, err, opts...)
 	}
+	return nil
+}
 
-	// Build notice
-	notice := buildNotice(ctx, err, opts...)
-
-	// Run before notify callbacks
-	if !runBeforeNotify(notice) {
-		return nil
-	}
+// Flush waits for all queued notices to be sent, giving up after
+// DefaultFlushTimeout.
+func Flush() {
+	_ = FlushWithTimeout(DefaultFlushTimeout)
+}
 
-	// Handle testing mode
-	if testingEnabled {
-		testingMu.Lock()
-		testingNotices = append(testingNotices, notice)
-		testingMu.Unlock()
-		return &APIResponse{ID: 0, ProblemID: 0}
+// FlushWithTimeout waits up to d for all queued notices to be sent. It
+// returns context.DeadlineExceeded if notices are still pending when d
+// elapses, leaving them queued. Use it to bound the wait in request-scoped
+// or serverless handlers.
+func FlushWithTimeout(d time.Duration) error {
+	if n := defaultNotifier(); n != nil {
+		return n.FlushWithTimeout(d)
 	}
-
-	client := NewClient(config)
-	return client.Send(notice)
+	return nil
 }
 
-// Flush waits for all queued notices to be sent.
-func Flush() {
-	mu.RLock()
-	w := worker
-	mu.RUnlock()
-
-	if w != nil {
-		w.Flush()
+// FlushCtx waits for all queued notices to be sent, returning early if ctx
+// is cancelled, e.g. by a server shutdown deadline. It returns the number of
+// notices still pending.
+func FlushCtx(ctx context.Context) int {
+	if n := defaultNotifier(); n != nil {
+		return n.FlushCtx(ctx)
 	}
+	return 0
 }
 
 // Stop stops the worker and waits for pending notices.
 func Stop() {
-	mu.Lock()
-	defer mu.Unlock()
+	if n := defaultNotifier(); n != nil {
+		n.Stop()
+	}
+}
 
-	if worker != nil {
-		worker.Stop()
-		worker = nil
+// Sanitize returns a copy of data with sensitive values redacted using the
+// configured filter settings, the same policy applied to notices, so data
+// can be redacted consistently before it is logged or sent elsewhere. Before
+// Configure is called, DefaultFilterKeys are used.
+func Sanitize(data map[string]interface{}) map[string]interface{} {
+	if n := defaultNotifier(); n != nil {
+		return n.Sanitize(data)
 	}
+	return NewSanitizeFilter(DefaultFilterKeys).Filter(data)
 }
 
 // Reset resets all state (useful for testing).
 func Reset() {
-	Stop()
-
 	mu.Lock()
-	config = nil
-	initialized = false
+	n := std
+	std = nil
 	mu.Unlock()
 
+	if n != nil {
+		n.Stop()
+	}
+
+	StopGoroutineMonitor()
 	ClearTesting()
+	ClearGlobalContext()
+	clearDebugState()
+	resetStats()
 }
 
-func shouldIgnore(err error) bool {
-	if config == nil {
-		return false
-	}
+// logReentrantNotify reports a notice dropped because it was triggered from
+// inside a callback of the same notifier while it built or encoded a notice.
+func logReentrantNotify(config *Configuration) {
+	logMessage(config, "warning", "Dropping notice reported while building another notice")
+}
 
-	filter := NewIgnoreFilter(config.IgnoredErrors)
-	return filter.ShouldIgnore(err)
+func shouldIgnore(config *Configuration, err error) bool {
+	return config.ignoreFilter.ShouldIgnore(err)
 }
 
-func buildNotice(ctx context.Context, err error, opts ...NotifyOption) *Notice {
+func buildNotice(config *Configuration, ctx context.Context, err error, opts ...NotifyOption) *Notice {
 	options := &notifyOptions{}
 	for _, opt := range opts {
 		opt(options)
 	}
 
 	// Get context data
-	ctxData := GetContextData(ctx)
+	ctxData := flatContextData(ctx)
 
-	// Merge context
+	// Merge context, starting from the global context
 	mergedContext := make(map[string]interface{})
+	for k, v := range globalContextValues() {
+		mergedContext[k] = v
+	}
 	for k, v := range ctxData.Context {
 		mergedContext[k] = v
 	}
+	if config.TraceExtractor != nil {
+		var traceID, spanID string
+		config.callbacks.run(func() {
+			traceID, spanID = config.TraceExtractor(ctx)
+		})
+		if traceID != "" {
+			mergedContext["trace_id"] = traceID
+		}
+		if spanID != "" {
+			mergedContext["span_id"] = spanID
+		}
+	}
 	for k, v := range options.Context {
 		mergedContext[k] = v
 	}
+	for k, v := range options.Typed {
+		mergedContext[k] = typedToContextValue(v)
+	}
+	if len(options.Metadata) > 0 {
+		metadata := make(map[string]interface{})
+		if existing, ok := mergedContext["metadata"].(map[string]interface{}); ok {
+			for k, v := range existing {
+				metadata[k] = v
+			}
+		}
+		for k, v := range options.Metadata {
+			metadata[k] = v
+		}
+		mergedContext["metadata"] = metadata
+	}
+
+	// Record the context deadline to tell timeout cascades apart
+	tags := options.Tags
+	if deadline, ok := ctx.Deadline(); ok {
+		remaining := time.Until(deadline)
+		mergedContext["context_deadline"] = deadline.UTC().Format(time.RFC3339Nano)
+		mergedContext["context_deadline_remaining_ms"] = remaining.Milliseconds()
+		if remaining <= 0 {
+			tags = append(append([]string{}, tags...), "context_deadline")
+		}
+	}
 
 	// Merge user
 	mergedUser := ctxData.User
@@ -215,39 +336,135 @@ func buildNotice(ctx context.Context, err error, opts ...NotifyOption) *Notice {
 	}
 
 	builder := NewNoticeBuilder(config)
-	return builder.Build(
+
+	// Use a pre-captured backtrace when supplied
+	switch {
+	case options.Callers != nil:
+		builder.frames = builder.callerFrames(options.Callers)
+	case options.StackTrace != nil:
+		builder.backtrace = builder.cleanStackTrace(options.StackTrace)
+		builder.frames = builder.parseStackTrace(builder.backtrace)
+	}
+
+	notice := builder.Build(
 		err,
 		mergedContext,
 		mergedUser,
 		mergedRequest,
 		options.Fingerprint,
-		options.Tags,
+		tags,
 	)
+
+	if options.ErrorClass != "" {
+		notice.ErrorClass = options.ErrorClass
+	}
+	notice.Severity = options.Severity
+	notice.Occurrences = options.Occurrences
+	notice.delivery = options.Delivery
+	if options.CallSite != nil {
+		notice.Source = &SourceLocation{
+			File: builder.cleanFilePath(options.CallSite.File),
+			Line: options.CallSite.Line,
+		}
+	}
+	if options.Environment != "" {
+		notice.Environment = options.Environment
+	}
+	if !options.Timestamp.IsZero() {
+		notice.OccurredAt = options.Timestamp.UTC()
+	}
+
+	// Group by normalized message when no fingerprint was supplied
+	if notice.Fingerprint == "" && config.NormalizeMessage != nil {
+		config.callbacks.run(func() {
+			notice.Fingerprint = normalizedFingerprint(notice.ErrorClass, notice.Message, config.NormalizeMessage)
+		})
+	}
+
+	// Attach wrapped and explicit causes
+	notice.Causes = builder.buildCauses(err, options.Causes)
+
+	if options.SkipBacktrace || skipBacktrace(config, notice.ErrorClass) {
+		notice.Backtrace = []string{}
+		notice.Frames = []BacktraceFrame{}
+	}
+
+	if config.SendSessionData {
+		notice.Session = ctxData.Session
+	}
+
+	// Attach breadcrumbs
+	if ctxData.breadcrumbs != nil {
+		notice.Breadcrumbs = builder.filterBreadcrumbs(ctxData.breadcrumbs.list())
+	}
+
+	// Let the configured fingerprinter group notices without an explicit
+	// fingerprint
+	if options.Fingerprint == "" && config.Fingerprinter != nil {
+		config.callbacks.run(func() {
+			notice.Fingerprint = config.Fingerprinter(notice)
+		})
+	}
+
+	return notice
 }
 
-func runBeforeNotify(notice *Notice) bool {
-	if config == nil || len(config.BeforeNotify) == 0 {
-		return true
+// skipBacktrace reports whether notices of the given class are configured
+// to omit their backtrace.
+func skipBacktrace(cfg *Configuration, class string) bool {
+	name := class
+	if i := strings.LastIndex(class, "."); i >= 0 {
+		name = class[i+1:]
 	}
 
-	for _, callback := range config.BeforeNotify {
-		if !callback(notice) {
-			return false
+	for _, skipped := range cfg.SkipBacktraceForClasses {
+		if skipped == class || skipped == name {
+			return true
 		}
 	}
+	return false
+}
 
-	return true
+func runBeforeNotify(config *Configuration, notice *Notice) bool {
+	if len(config.BeforeNotify) == 0 {
+		return true
+	}
+
+	allowed := true
+	config.callbacks.run(func() {
+		for _, callback := range config.BeforeNotify {
+			if !callback(notice) {
+				allowed = false
+				return
+			}
+		}
+	})
+
+	return allowed
 }
 
 // NotifyOption is a functional option for Notify.
 type NotifyOption func(*notifyOptions)
 
 type notifyOptions struct {
-	Context     map[string]interface{}
-	User        map[string]interface{}
-	Request     map[string]interface{}
-	Fingerprint string
-	Tags        []string
+	Context       map[string]interface{}
+	User          map[string]interface{}
+	Request       map[string]interface{}
+	Fingerprint   string
+	Tags          []string
+	ErrorClass    string
+	Severity      string
+	Occurrences   int
+	Environment   string
+	Typed         map[string]interface{}
+	Metadata      map[string]interface{}
+	Callers       []uintptr
+	StackTrace    []string
+	Causes        []error
+	Timestamp     time.Time
+	SkipBacktrace bool
+	Delivery      deliveryMode
+	CallSite      *SourceLocation
 }
 
 // WithContext sets additional context data.
@@ -284,3 +501,165 @@ func WithTags(tags ...string) NotifyOption {
 		o.Tags = tags
 	}
 }
+
+// WithEnvironment overrides the configured environment for a single notice.
+func WithEnvironment(env string) NotifyOption {
+	return func(o *notifyOptions) {
+		o.Environment = env
+	}
+}
+
+// WithTimestamp sets when the error occurred, for errors reported after the
+// fact, e.g. when replaying a log or reprocessing a queue. Defaults to now.
+func WithTimestamp(t time.Time) NotifyOption {
+	return func(o *notifyOptions) {
+		o.Timestamp = t
+	}
+}
+
+// WithErrorClass overrides the error class reported for the notice.
+func WithErrorClass(class string) NotifyOption {
+	return func(o *notifyOptions) {
+		o.ErrorClass = class
+	}
+}
+
+// WithSync makes Notify send the notice on the calling goroutine, even when
+// AsyncSend is enabled. It has no effect on NotifySync.
+func WithSync() NotifyOption {
+	return func(o *notifyOptions) {
+		o.Delivery = deliverySync
+	}
+}
+
+// WithAsync makes Notify send the notice in the background, even when
+// AsyncSend is disabled. Without a worker the notice is sent from its own
+// goroutine, which Stop waits for. It has no effect on NotifySync.
+func WithAsync() NotifyOption {
+	return func(o *notifyOptions) {
+		o.Delivery = deliveryAsync
+	}
+}
+
+// WithCallSite records file and line as the place the error was reported
+// from, shown alongside the backtrace even when it is trimmed or skipped.
+// It is meant for libraries that report errors on behalf of their callers.
+func WithCallSite(file string, line int) NotifyOption {
+	return func(o *notifyOptions) {
+		o.CallSite = &SourceLocation{File: file, Line: line}
+	}
+}
+
+// WithCaller records the call site skip frames above the caller of
+// WithCaller, as WithCallSite does. WithCaller(0) records the line calling
+// Notify; a wrapper passes 1 to record its own caller.
+func WithCaller(skip int) NotifyOption {
+	_, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return func(*notifyOptions) {}
+	}
+	return WithCallSite(file, line)
+}
+
+// WithBacktrace supplies the program counters of a stack captured earlier,
+// e.g. with runtime.Callers, to report instead of the stack at the Notify
+// call. The frames are formatted and cleaned with RootPath.
+func WithBacktrace(frames []uintptr) NotifyOption {
+	return func(o *notifyOptions) {
+		o.Callers = frames
+	}
+}
+
+// WithStackTrace supplies pre-formatted backtrace lines, such as those of a
+// stack recorded by another library, to report instead of the stack at the
+// Notify call. RootPath is trimmed from the start of each line.
+func WithStackTrace(stack []string) NotifyOption {
+	return func(o *notifyOptions) {
+		o.StackTrace = stack
+	}
+}
+
+// WithoutBacktrace sends the notice without a backtrace.
+func WithoutBacktrace() NotifyOption {
+	return func(o *notifyOptions) {
+		o.SkipBacktrace = true
+	}
+}
+
+// WithSeverity sets the severity level (e.g. "error", "warning", "info").
+func WithSeverity(severity string) NotifyOption {
+	return func(o *notifyOptions) {
+		o.Severity = severity
+	}
+}
+
+// WithOccurrences marks the notice as representing n occurrences of the
+// error, for callers that aggregate errors themselves, so the server can
+// weight it accordingly. Values below 1 are ignored.
+func WithOccurrences(n int) NotifyOption {
+	return func(o *notifyOptions) {
+		if n >= 1 {
+			o.Occurrences = n
+		}
+	}
+}
+
+// WithCause attaches an underlying error to the notice without replacing
+// the reported error, e.g. to keep a clean user-facing message while still
+// surfacing the technical root cause, or to record a cause from an error
+// library that doesn't support unwrapping. Errors wrapped by the reported
+// error are listed as causes automatically. It may be given more than once.
+func WithCause(err error) NotifyOption {
+	return func(o *notifyOptions) {
+		if err != nil {
+			o.Causes = append(o.Causes, err)
+		}
+	}
+}
+
+// WithTyped attaches a strongly-typed value to the notice context under key.
+// The value is converted to its JSON representation when the notice is
+// built, so struct tags apply and sensitive fields are filtered.
+func WithTyped[T any](key string, v T) NotifyOption {
+	return func(o *notifyOptions) {
+		if o.Typed == nil {
+			o.Typed = make(map[string]interface{})
+		}
+		o.Typed[key] = v
+	}
+}
+
+// WithMetadata attaches a single key/value annotation to the notice. Calls
+// accumulate under the "metadata" key of the notice context, keeping one-off
+// annotations apart from context set by integrations.
+func WithMetadata(key string, value interface{}) NotifyOption {
+	return func(o *notifyOptions) {
+		if o.Metadata == nil {
+			o.Metadata = make(map[string]interface{})
+		}
+		o.Metadata[key] = value
+	}
+}
+
+// WithExtra attaches several annotations at once, like calling WithMetadata
+// for each entry.
+func WithExtra(extra map[string]interface{}) NotifyOption {
+	return func(o *notifyOptions) {
+		for k, v := range extra {
+			WithMetadata(k, v)(o)
+		}
+	}
+}
+
+func typedToContextValue(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("[UNSERIALIZABLE: %v]", err)
+	}
+
+	var result interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Sprintf("[UNSERIALIZABLE: %v]", err)
+	}
+	return result
+}
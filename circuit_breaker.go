@@ -0,0 +1,138 @@
+package checkend
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by the client while the circuit breaker is open
+// and requests to the endpoint are being skipped.
+var ErrCircuitOpen = errors.New("checkend: circuit open")
+
+// DefaultCircuitBreakerThreshold is the default number of consecutive
+// failures that opens the circuit.
+const DefaultCircuitBreakerThreshold = 5
+
+// DefaultCircuitBreakerCooldown is the default time the circuit stays open
+// before a probe request is allowed.
+const DefaultCircuitBreakerCooldown = 30 * time.Second
+
+// CircuitBreakerConfig configures the circuit breaker that stops sending
+// to an endpoint that keeps failing.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive send failures that
+	// opens the circuit.
+	FailureThreshold int
+
+	// Cooldown is how long the circuit stays open before a single probe
+	// request is allowed through.
+	Cooldown time.Duration
+}
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// circuitBreaker tracks consecutive send failures. A nil breaker allows
+// every request.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     circuitState
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	now       func() time.Time
+}
+
+func newCircuitBreaker(cfg *CircuitBreakerConfig) *circuitBreaker {
+	if cfg == nil {
+		return nil
+	}
+
+	cb := &circuitBreaker{
+		threshold: DefaultCircuitBreakerThreshold,
+		cooldown:  DefaultCircuitBreakerCooldown,
+		now:       time.Now,
+	}
+	if cfg.FailureThreshold > 0 {
+		cb.threshold = cfg.FailureThreshold
+	}
+	if cfg.Cooldown > 0 {
+		cb.cooldown = cfg.Cooldown
+	}
+	return cb
+}
+
+// allow reports whether a request may be sent. Once the cooldown has
+// elapsed, the circuit half-opens and a single probe request is allowed.
+func (cb *circuitBreaker) allow() bool {
+	if cb == nil {
+		return true
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case circuitOpen:
+		if cb.now().Sub(cb.openedAt) < cb.cooldown {
+			return false
+		}
+		cb.state = circuitHalfOpen
+		cb.probing = true
+		return true
+	case circuitHalfOpen:
+		if cb.probing {
+			return false
+		}
+		cb.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// success records a successful request and closes the circuit.
+func (cb *circuitBreaker) success() {
+	if cb == nil {
+		return
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.state = circuitClosed
+	cb.failures = 0
+	cb.probing = false
+}
+
+// failure records a failed request, opening the circuit when the threshold
+// is reached or when a probe fails.
+func (cb *circuitBreaker) failure() {
+	if cb == nil {
+		return
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.failures++
+	cb.probing = false
+	if cb.state == circuitHalfOpen || cb.failures >= cb.threshold {
+		cb.state = circuitOpen
+		cb.openedAt = cb.now()
+	}
+}
+
+func (cb *circuitBreaker) currentState() circuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state
+}
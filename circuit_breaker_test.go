@@ -0,0 +1,161 @@
+package checkend
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	transport := NewTestTransport()
+	transport.RespondWith(503, `{"error": "unavailable"}`)
+
+	cfg := NewConfiguration(Config{
+		APIKey:    "test-key",
+		Transport: transport,
+		CircuitBreaker: &CircuitBreakerConfig{
+			FailureThreshold: 2,
+			Cooldown:         time.Minute,
+		},
+	})
+	now := time.Now()
+	cfg.breaker.now = func() time.Time { return now }
+
+	client := NewClient(cfg)
+	notice := newTestNotice(cfg)
+
+	// Closed: failures reach the network until the threshold is hit
+	for i := 0; i < 2; i++ {
+		if _, err := client.SendWithContext(context.Background(), notice); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Expected circuit to be closed on attempt %d", i+1)
+		}
+	}
+	if cfg.breaker.currentState() != circuitOpen {
+		t.Fatal("Expected circuit to open after reaching the failure threshold")
+	}
+
+	// Open: requests fail fast without touching the network
+	if _, err := client.SendWithContext(context.Background(), notice); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if len(transport.Requests()) != 2 {
+		t.Errorf("Expected 2 requests, got %d", len(transport.Requests()))
+	}
+
+	// Half-open: a failed probe reopens the circuit
+	now = now.Add(time.Minute)
+	if _, err := client.SendWithContext(context.Background(), notice); errors.Is(err, ErrCircuitOpen) {
+		t.Fatal("Expected a probe request after the cooldown")
+	}
+	if cfg.breaker.currentState() != circuitOpen {
+		t.Fatal("Expected failed probe to reopen the circuit")
+	}
+
+	// Half-open: a successful probe closes the circuit
+	transport.RespondWith(201, `{"id": 1, "problem_id": 1}`)
+	now = now.Add(time.Minute)
+	if _, err := client.SendWithContext(context.Background(), notice); err != nil {
+		t.Fatalf("Expected probe to succeed, got %v", err)
+	}
+	if cfg.breaker.currentState() != circuitClosed {
+		t.Error("Expected successful probe to close the circuit")
+	}
+	if len(transport.Requests()) != 4 {
+		t.Errorf("Expected 4 requests, got %d", len(transport.Requests()))
+	}
+}
+
+func TestCircuitBreakerAllowsSingleProbe(t *testing.T) {
+	cb := newCircuitBreaker(&CircuitBreakerConfig{FailureThreshold: 1, Cooldown: time.Second})
+	now := time.Now()
+	cb.now = func() time.Time { return now }
+
+	cb.failure()
+	now = now.Add(time.Second)
+
+	if !cb.allow() {
+		t.Fatal("Expected first probe to be allowed")
+	}
+	if cb.allow() {
+		t.Error("Expected concurrent probe to be rejected")
+	}
+}
+
+func TestCircuitBreakerIgnoresClientErrors(t *testing.T) {
+	transport := NewTestTransport()
+	transport.RespondWith(422, `{"error": "invalid"}`)
+
+	cfg := NewConfiguration(Config{
+		APIKey:         "test-key",
+		Transport:      transport,
+		CircuitBreaker: &CircuitBreakerConfig{FailureThreshold: 1},
+	})
+	client := NewClient(cfg)
+
+	client.SendWithContext(context.Background(), newTestNotice(cfg))
+	if cfg.breaker.currentState() != circuitClosed {
+		t.Error("Expected a rejected payload not to open the circuit")
+	}
+}
+
+func TestCircuitBreakerDisabledByDefault(t *testing.T) {
+	cfg := NewConfiguration(Config{APIKey: "test-key"})
+	if cfg.breaker != nil {
+		t.Error("Expected no circuit breaker by default")
+	}
+	if !cfg.breaker.allow() {
+		t.Error("Expected nil circuit breaker to allow requests")
+	}
+}
+
+// brokenBodyTransport responds with a body that fails to read.
+type brokenBodyTransport struct{}
+
+func (brokenBodyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusCreated,
+		Body:       io.NopCloser(&failingReader{}),
+		Request:    req,
+	}, nil
+}
+
+type failingReader struct{}
+
+func (*failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestCircuitBreakerProbeWithUnreadableResponse(t *testing.T) {
+	cfg := NewConfiguration(Config{
+		APIKey:    "test-key",
+		Transport: brokenBodyTransport{},
+		CircuitBreaker: &CircuitBreakerConfig{
+			FailureThreshold: 1,
+			Cooldown:         time.Minute,
+		},
+	})
+	now := time.Now()
+	cfg.breaker.now = func() time.Time { return now }
+	cfg.breaker.failure()
+
+	client := NewClient(cfg)
+	notice := newTestNotice(cfg)
+
+	// The probe fails while reading the response
+	now = now.Add(time.Minute)
+	if _, err := client.SendWithContext(context.Background(), notice); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected the probe to fail reading the response, got %v", err)
+	}
+	if cfg.breaker.currentState() != circuitOpen {
+		t.Fatal("Expected failed probe to reopen the circuit")
+	}
+
+	// The next cooldown allows another probe instead of staying stuck
+	now = now.Add(time.Minute)
+	if !cfg.breaker.allow() {
+		t.Error("Expected a new probe after the cooldown")
+	}
+}
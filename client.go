@@ -2,8 +2,11 @@ package checkend
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -20,16 +23,27 @@ type APIResponse struct {
 
 // Client is the HTTP client for the Checkend API.
 type Client struct {
-	config     *Configuration
-	endpoint   string
-	httpClient *http.Client
+	config        *Configuration
+	endpoint      string
+	batchEndpoint string
+	pingEndpoint  string
+	breaker       *circuitBreaker
+	httpClient    *http.Client
 }
 
 // NewClient creates a new API client.
 func NewClient(config *Configuration) *Client {
+	return newClient(config, config.Endpoint, config.breaker)
+}
+
+// newClient creates an API client for the given base URL.
+func newClient(config *Configuration, baseURL string, breaker *circuitBreaker) *Client {
 	return &Client{
-		config:   config,
-		endpoint: config.Endpoint + "/ingest/v1/errors",
+		config:        config,
+		endpoint:      baseURL + config.IngestPath,
+		batchEndpoint: baseURL + config.BatchIngestPath,
+		pingEndpoint:  baseURL + DefaultPingPath,
+		breaker:       breaker,
 		httpClient: &http.Client{
 			Timeout:   config.Timeout,
 			Transport: buildTransport(config),
@@ -39,6 +53,10 @@ func NewClient(config *Configuration) *Client {
 
 // buildTransport creates an HTTP transport with proxy, TLS, and timeout settings.
 func buildTransport(config *Configuration) http.RoundTripper {
+	if config.Transport != nil {
+		return config.Transport
+	}
+
 	transport := &http.Transport{
 		DialContext: (&net.Dialer{
 			Timeout:   config.ConnectTimeout,
@@ -70,54 +88,268 @@ func buildTransport(config *Configuration) http.RoundTripper {
 
 // Send sends a notice to Checkend.
 func (c *Client) Send(notice *Notice) *APIResponse {
+	resp, _ := c.SendWithContext(context.Background(), notice)
+	return resp
+}
+
+// SendWithContext sends a notice to Checkend, aborting the request if ctx is
+// cancelled or its deadline passes.
+func (c *Client) SendWithContext(ctx context.Context, notice *Notice) (*APIResponse, error) {
+	if c.config.DryRun {
+		if err := c.logDryRun(notice); err != nil {
+			return nil, err
+		}
+		// Nothing was created, so there is no ID to return
+		return &APIResponse{}, nil
+	}
+
 	if c.config.APIKey == "" {
 		c.log("error", "Cannot send notice: api_key not configured")
-		return nil
+		return nil, errors.New("checkend: api_key not configured")
+	}
+
+	data, err := c.encodeNotice(notice)
+	if err != nil {
+		return nil, err
 	}
 
+	body, err := c.post(ctx, c.endpoint, data)
+	if err != nil {
+		recordSendError(err)
+		return nil, err
+	}
+	countSent(notice)
+
+	var apiResp APIResponse
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		c.log("error", fmt.Sprintf("Failed to parse response: %v", err))
+		return nil, err
+	}
+
+	c.log("debug", fmt.Sprintf("Notice sent successfully: %+v", apiResp))
+	return &apiResp, nil
+}
+
+// SendBatch sends several notices to Checkend in a single request.
+func (c *Client) SendBatch(ctx context.Context, notices []*Notice) ([]APIResponse, error) {
+	if c.config.DryRun {
+		for _, notice := range notices {
+			if err := c.logDryRun(notice); err != nil {
+				return nil, err
+			}
+		}
+		return make([]APIResponse, len(notices)), nil
+	}
+
+	if c.config.APIKey == "" {
+		c.log("error", "Cannot send notices: api_key not configured")
+		return nil, errors.New("checkend: api_key not configured")
+	}
+
+	payloads := make([]json.RawMessage, 0, len(notices))
+	for _, notice := range notices {
+		data, err := c.encodeNotice(notice)
+		if err != nil {
+			return nil, err
+		}
+		payloads = append(payloads, data)
+	}
+
+	data, err := json.Marshal(payloads)
+	if err != nil {
+		c.log("error", fmt.Sprintf("Failed to marshal batch: %v", err))
+		return nil, err
+	}
+
+	body, err := c.post(ctx, c.batchEndpoint, data)
+	if err != nil {
+		recordSendError(err)
+		return nil, err
+	}
+	countSent(notices...)
+
+	apiResps, err := parseBatchResponse(body)
+	if err != nil {
+		c.log("error", fmt.Sprintf("Failed to parse response: %v", err))
+		return nil, err
+	}
+
+	c.log("debug", fmt.Sprintf("Batch of %d notices sent successfully", len(notices)))
+	return apiResps, nil
+}
+
+// parseBatchResponse parses an array of results, tolerating a single object.
+func parseBatchResponse(body []byte) ([]APIResponse, error) {
+	var apiResps []APIResponse
+	if err := json.Unmarshal(body, &apiResps); err == nil {
+		return apiResps, nil
+	}
+
+	var apiResp APIResponse
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, err
+	}
+	return []APIResponse{apiResp}, nil
+}
+
+// encodeNotice converts a notice into its JSON payload.
+func (c *Client) encodeNotice(notice *Notice) ([]byte, error) {
 	payload := notice.ToPayload()
-	data, err := json.Marshal(payload)
+	if c.config.TransformPayload != nil {
+		c.config.callbacks.run(func() {
+			c.config.TransformPayload(payload)
+		})
+	}
+
+	data, err := marshalPayload(payload, c.config.FieldNaming, c.config.FieldNames)
 	if err != nil {
 		c.log("error", fmt.Sprintf("Failed to marshal payload: %v", err))
-		return nil
+		return nil, err
 	}
+	return data, nil
+}
 
-	req, err := http.NewRequest("POST", c.endpoint, bytes.NewReader(data))
+// logDryRun writes the indented JSON payload of a notice to the debug log
+// in place of sending it.
+func (c *Client) logDryRun(notice *Notice) error {
+	data, err := c.encodeNotice(notice)
+	if err != nil {
+		return err
+	}
+
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, data, "", "  "); err != nil {
+		return err
+	}
+	c.log("debug", fmt.Sprintf("Dry run, not sending notice:\n%s", pretty.String()))
+	return nil
+}
+
+// post sends data to the given endpoint and returns the response body of a
+// successful (201 Created) response.
+func (c *Client) post(ctx context.Context, endpoint string, data []byte) ([]byte, error) {
+	checkTestingModeRequired(c.config)
+
+	if !c.breaker.allow() {
+		c.log("debug", "Circuit open, skipping request")
+		return nil, ErrCircuitOpen
+	}
+
+	// Every allowed request must record an outcome, or a half-open probe
+	// would never finish. Anything short of a healthy response counts as a
+	// failure.
+	healthy := false
+	defer func() {
+		if healthy {
+			c.breaker.success()
+		} else {
+			c.breaker.failure()
+		}
+	}()
+
+	compressed := false
+	if threshold := c.config.CompressMinBytes; threshold >= 0 && len(data) >= threshold {
+		if gzipped, err := gzipData(data); err == nil {
+			data = gzipped
+			compressed = true
+		} else {
+			c.log("error", fmt.Sprintf("Failed to compress payload: %v", err))
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(data))
 	if err != nil {
 		c.log("error", fmt.Sprintf("Failed to create request: %v", err))
-		return nil
+		return nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
 	req.Header.Set("Checkend-Ingestion-Key", c.config.APIKey)
 	req.Header.Set("User-Agent", fmt.Sprintf("checkend-go/%s", Version))
+	if err := c.prepareRequest(req); err != nil {
+		return nil, err
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		c.log("error", fmt.Sprintf("Failed to send request: %v", err))
-		return nil
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		c.log("error", fmt.Sprintf("Failed to read response: %v", err))
-		return nil
+		return nil, err
 	}
 
+	// Only server-side trouble counts towards opening the circuit; a
+	// rejected payload says nothing about the endpoint's health.
+	healthy = resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests
+
 	if resp.StatusCode != http.StatusCreated {
 		c.handleHTTPError(resp.StatusCode, body)
-		return nil
+		return nil, &statusError{code: resp.StatusCode}
 	}
 
-	var apiResp APIResponse
-	if err := json.Unmarshal(body, &apiResp); err != nil {
-		c.log("error", fmt.Sprintf("Failed to parse response: %v", err))
+	return body, nil
+}
+
+// statusError is returned when the API responds with a status other than
+// 201 Created.
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("checkend: unexpected response status %d", e.code)
+}
+
+// retryable reports whether a failed send may succeed later. Only server
+// errors and rate limiting are worth retrying; any other status means the
+// payload was rejected. Errors without a status, such as network failures
+// or an open circuit, are retryable.
+func retryable(err error) bool {
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.code >= 500 || se.code == http.StatusTooManyRequests
+	}
+	return true
+}
+
+// prepareRequest runs the PrepareRequest hook on req, then restores the
+// body in case the hook consumed or replaced it.
+func (c *Client) prepareRequest(req *http.Request) error {
+	if c.config.PrepareRequest == nil {
 		return nil
 	}
 
-	c.log("debug", fmt.Sprintf("Notice sent successfully: %+v", apiResp))
-	return &apiResp
+	getBody, contentLength := req.GetBody, req.ContentLength
+	c.config.PrepareRequest(req)
+
+	body, err := getBody()
+	if err != nil {
+		c.log("error", fmt.Sprintf("Failed to restore request body: %v", err))
+		return err
+	}
+	req.Body, req.GetBody, req.ContentLength = body, getBody, contentLength
+	return nil
+}
+
+// gzipData compresses data with gzip.
+func gzipData(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
 
 func (c *Client) handleHTTPError(statusCode int, body []byte) {
@@ -138,7 +370,13 @@ func (c *Client) handleHTTPError(statusCode int, body []byte) {
 }
 
 func (c *Client) log(level, message string) {
-	if !c.config.Debug && level == "debug" {
+	logMessage(c.config, level, message)
+}
+
+// logMessage prints an SDK log line. Debug messages are only printed when
+// debug logging is enabled.
+func logMessage(config *Configuration, level, message string) {
+	if level == "debug" && (config == nil || !config.Debug) {
 		return
 	}
 	fmt.Printf("[Checkend] [%s] %s\n", level, message)
@@ -0,0 +1,203 @@
+package checkend
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestNotice(cfg *Configuration) *Notice {
+	return NewNoticeBuilder(cfg).Build(errors.New("test error"), nil, nil, nil, "", nil)
+}
+
+func TestClientSend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Checkend-Ingestion-Key") != "test-key" {
+			t.Errorf("Expected ingestion key header, got '%s'", r.Header.Get("Checkend-Ingestion-Key"))
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": 1, "problem_id": 2}`))
+	}))
+	defer server.Close()
+
+	cfg := NewConfiguration(Config{APIKey: "test-key", Endpoint: server.URL})
+	resp, err := NewClient(cfg).SendWithContext(context.Background(), newTestNotice(cfg))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.ID != 1 || resp.ProblemID != 2 {
+		t.Errorf("Unexpected response %+v", resp)
+	}
+}
+
+func TestClientSendWithContextCancellation(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	cfg := NewConfiguration(Config{APIKey: "test-key", Endpoint: server.URL})
+	client := NewClient(cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	resp, err := client.SendWithContext(ctx, newTestNotice(cfg))
+	elapsed := time.Since(start)
+
+	if resp != nil {
+		t.Errorf("Expected nil response, got %+v", resp)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if elapsed > time.Second {
+		t.Errorf("Expected send to return promptly, took %v", elapsed)
+	}
+}
+
+func TestClientTransformPayload(t *testing.T) {
+	transport := NewTestTransport()
+	cfg := NewConfiguration(Config{
+		APIKey:    "test-key",
+		Transport: transport,
+		TransformPayload: func(p *Payload) {
+			p.Error.Message = "rewritten"
+			p.Context["tenant"] = "acme"
+		},
+	})
+
+	if _, err := NewClient(cfg).SendWithContext(context.Background(), newTestNotice(cfg)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	payload := transport.LastRequest().Payload
+	if payload["error"].(map[string]interface{})["message"] != "rewritten" {
+		t.Errorf("Expected rewritten message, got %v", payload["error"])
+	}
+	if payload["context"].(map[string]interface{})["tenant"] != "acme" {
+		t.Errorf("Expected tenant 'acme' in context, got %v", payload["context"])
+	}
+}
+
+func TestClientPrepareRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if len(body) == 0 {
+			t.Error("Expected the payload to be sent")
+		}
+		sum := sha256.Sum256(body)
+		if signature := r.Header.Get("X-Signature"); signature != hex.EncodeToString(sum[:]) {
+			t.Errorf("Expected the signature of the body, got '%s'", signature)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": 1, "problem_id": 2}`))
+	}))
+	defer server.Close()
+
+	cfg := NewConfiguration(Config{
+		APIKey:   "test-key",
+		Endpoint: server.URL,
+		PrepareRequest: func(req *http.Request) {
+			body, _ := io.ReadAll(req.Body)
+			sum := sha256.Sum256(body)
+			req.Header.Set("X-Signature", hex.EncodeToString(sum[:]))
+			// Dropping the body must not stop the payload from being sent
+			req.Body = nil
+		},
+	})
+
+	if _, err := NewClient(cfg).SendWithContext(context.Background(), newTestNotice(cfg)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestClientSendsClientInfo(t *testing.T) {
+	transport := NewTestTransport()
+	cfg := NewConfiguration(Config{
+		APIKey:     "test-key",
+		Transport:  transport,
+		ClientInfo: &ClientInfo{Name: "acme-platform", Version: "3.2.0"},
+	})
+
+	if _, err := NewClient(cfg).SendWithContext(context.Background(), newTestNotice(cfg)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	payload := transport.LastRequest().Payload
+	client, ok := payload["client"].(map[string]interface{})
+	if !ok || client["name"] != "acme-platform" || client["version"] != "3.2.0" {
+		t.Errorf("Expected the client block, got %v", payload["client"])
+	}
+	notifier := payload["notifier"].(map[string]interface{})
+	if notifier["name"] != "checkend-go" || notifier["version"] != Version {
+		t.Errorf("Expected the notifier block to describe the SDK, got %v", notifier)
+	}
+}
+
+func TestClientOmitsClientInfoByDefault(t *testing.T) {
+	transport := NewTestTransport()
+	cfg := NewConfiguration(Config{APIKey: "test-key", Transport: transport})
+
+	if _, err := NewClient(cfg).SendWithContext(context.Background(), newTestNotice(cfg)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if client, ok := transport.LastRequest().Payload["client"]; ok {
+		t.Errorf("Expected no client block, got %v", client)
+	}
+}
+
+func TestClientCompressesLargePayloads(t *testing.T) {
+	transport := NewTestTransport()
+	cfg := NewConfiguration(Config{APIKey: "test-key", Transport: transport, CompressMinBytes: 512})
+	client := NewClient(cfg)
+
+	small := newTestNotice(cfg)
+	small.Backtrace = nil
+	small.Frames = nil
+	if _, err := client.SendWithContext(context.Background(), small); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if encoding := transport.LastRequest().Header.Get("Content-Encoding"); encoding != "" {
+		t.Errorf("Expected small payload to be uncompressed, got Content-Encoding %q", encoding)
+	}
+
+	large := newTestNotice(cfg)
+	large.Context = map[string]interface{}{"blob": strings.Repeat("x", 2048)}
+	if _, err := client.SendWithContext(context.Background(), large); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	req := transport.LastRequest()
+	if encoding := req.Header.Get("Content-Encoding"); encoding != "gzip" {
+		t.Errorf("Expected large payload to be gzipped, got Content-Encoding %q", encoding)
+	}
+	if ctx, _ := req.Payload["context"].(map[string]interface{}); ctx["blob"] != strings.Repeat("x", 2048) {
+		t.Error("Expected gzipped payload to decode to the original notice")
+	}
+}
+
+func TestClientCompressionDisabled(t *testing.T) {
+	transport := NewTestTransport()
+	cfg := NewConfiguration(Config{APIKey: "test-key", Transport: transport, CompressMinBytes: -1})
+
+	notice := newTestNotice(cfg)
+	notice.Context = map[string]interface{}{"blob": strings.Repeat("x", 4096)}
+	if _, err := NewClient(cfg).SendWithContext(context.Background(), notice); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if encoding := transport.LastRequest().Header.Get("Content-Encoding"); encoding != "" {
+		t.Errorf("Expected compression to be disabled, got Content-Encoding %q", encoding)
+	}
+}
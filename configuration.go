@@ -1,7 +1,15 @@
 package checkend
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
 	"strings"
 	"time"
 )
@@ -9,6 +17,12 @@ import (
 // DefaultEndpoint is the default Checkend API endpoint.
 const DefaultEndpoint = "https://app.checkend.io"
 
+// DefaultIngestPath is the default API path for single notices.
+const DefaultIngestPath = "/ingest/v1/errors"
+
+// DefaultBatchIngestPath is the default API path for batched notices.
+const DefaultBatchIngestPath = "/ingest/v1/errors/batch"
+
 // DefaultTimeout is the default HTTP request timeout.
 const DefaultTimeout = 15 * time.Second
 
@@ -18,9 +32,34 @@ const DefaultConnectTimeout = 5 * time.Second
 // DefaultShutdownTimeout is the default graceful shutdown timeout.
 const DefaultShutdownTimeout = 5 * time.Second
 
+// DefaultFlushTimeout bounds how long Flush waits for the queue to drain.
+const DefaultFlushTimeout = 30 * time.Second
+
 // DefaultMaxQueueSize is the default maximum queue size for async sending.
 const DefaultMaxQueueSize = 1000
 
+// MinQueueSize is the smallest queue a worker will be created with.
+const MinQueueSize = 1
+
+// DefaultQueueFullTimeout is how long Push blocks under QueueFullBlock.
+const DefaultQueueFullTimeout = 100 * time.Millisecond
+
+// DefaultRetryBackoffBase is the default delay before the first retry of a
+// failed send.
+const DefaultRetryBackoffBase = 100 * time.Millisecond
+
+// DefaultRetryBackoffMax is the default cap on the delay between retries.
+const DefaultRetryBackoffMax = 10 * time.Second
+
+// DefaultMaxRequestBodyBytes is the default cap on captured request bodies.
+const DefaultMaxRequestBodyBytes = 10 * 1024
+
+// DefaultCompressMinBytes is the payload size from which requests are gzipped.
+const DefaultCompressMinBytes = 1024
+
+// DefaultBatchInterval is the default maximum time a partial batch waits before being sent.
+const DefaultBatchInterval = 5 * time.Second
+
 // DefaultFilterKeys are the default keys to filter from payloads.
 var DefaultFilterKeys = []string{
 	"password",
@@ -41,6 +80,46 @@ var DefaultFilterKeys = []string{
 	"social_security",
 }
 
+// DefaultSensitiveEnvPatterns are the default environment variable name
+// patterns that are never sent with SendEnvironment.
+var DefaultSensitiveEnvPatterns = []string{
+	"SECRET",
+	"PASSWORD",
+	"KEY",
+	"TOKEN",
+	"CREDENTIAL",
+	"AUTH",
+	"PRIVATE",
+}
+
+// FilterPolicy is a redaction policy applied to notice data.
+type FilterPolicy struct {
+	// FilterKeys are keys to filter, matched case-insensitively as substrings.
+	FilterKeys []string
+
+	// FilterKeyPatterns filter values whose key matches any of the patterns.
+	FilterKeyPatterns []*regexp.Regexp
+
+	// ValuePatterns redact matching parts of string values.
+	ValuePatterns []*regexp.Regexp
+
+	// Allowlist, when non-empty, keeps only values under these keys and
+	// filters everything else.
+	Allowlist []string
+
+	// Placeholder replaces filtered values. Defaults to "[FILTERED]".
+	Placeholder string
+}
+
+// DefaultValueFilters redact common secrets found inside string values:
+// credit card numbers, email addresses, and bearer tokens. Use them as
+// Config.ValueFilters, optionally extended with your own patterns.
+var DefaultValueFilters = []*regexp.Regexp{
+	regexp.MustCompile(`\b\d{4}[ -]?\d{4}[ -]?\d{4}[ -]?\d{3,4}\b`),
+	regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+	regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`),
+}
+
 // Config holds the configuration options for Checkend.
 type Config struct {
 	// APIKey is your Checkend ingestion API key (required).
@@ -49,6 +128,25 @@ type Config struct {
 	// Endpoint is the API endpoint URL.
 	Endpoint string
 
+	// FallbackEndpoint is a secondary API endpoint URL. When a notice fails
+	// every retry against Endpoint, the worker tries it once more here
+	// before spooling or giving up.
+	FallbackEndpoint string
+
+	// IngestPath is the API path single notices are posted to, relative to
+	// Endpoint. Defaults to DefaultIngestPath.
+	IngestPath string
+
+	// BatchIngestPath is the API path batches are posted to when BatchSize
+	// is above 1. Defaults to DefaultBatchIngestPath.
+	BatchIngestPath string
+
+	// CompressMinBytes is the payload size in bytes from which requests are
+	// gzip-compressed; smaller payloads are sent as-is, since compression
+	// overhead outweighs the savings. Zero uses DefaultCompressMinBytes and a
+	// negative value disables compression.
+	CompressMinBytes int
+
 	// Environment is the environment name (e.g., "production", "staging").
 	Environment string
 
@@ -58,12 +156,72 @@ type Config struct {
 	// AsyncSend controls whether errors are sent asynchronously.
 	AsyncSend bool
 
-	// MaxQueueSize is the maximum queue size for async sending.
+	// MaxQueueSize is the maximum queue size for async sending. Zero or a
+	// negative value uses DefaultMaxQueueSize.
 	MaxQueueSize int
 
+	// PropagateContext ties async notices to the context passed to
+	// NotifyWithContext: the worker sends with that context and drops the
+	// notice instead of sending or retrying it once the context is done.
+	PropagateContext bool
+
+	// QueueFullPolicy controls what happens to a notice reported while the
+	// queue is full. QueueFullBlock makes the reporting caller wait, so it
+	// adds latency to request handlers when sending falls behind.
+	QueueFullPolicy QueueFullPolicy
+
+	// QueueFullTimeout is the longest a caller blocks under QueueFullBlock.
+	// Defaults to DefaultQueueFullTimeout.
+	QueueFullTimeout time.Duration
+
+	// BatchSize is the number of queued notices sent together in a single
+	// request. Values <= 1 send each notice individually.
+	BatchSize int
+
+	// BatchInterval is the maximum time a partial batch waits before being sent.
+	BatchInterval time.Duration
+
+	// FlushInterval periodically sends whatever partial batch is queued, so
+	// low-volume services get timely delivery without reaching BatchSize.
+	// When set it takes precedence over BatchInterval.
+	FlushInterval time.Duration
+
+	// NoticeTTL drops queued notices that are older than this when the
+	// worker gets to them, e.g. after an outage. Zero keeps every notice.
+	NoticeTTL time.Duration
+
+	// StartupGracePeriod holds notices in the queue for this long after the
+	// worker starts, so errors from a cold start, such as dependencies that
+	// aren't ready yet, don't go out as a burst. Held notices are sent when
+	// the period ends, unless NoticeTTL has expired them by then. Flush
+	// waits for the period to end; Stop ends it early. Zero disables it.
+	StartupGracePeriod time.Duration
+
+	// SpoolDir is a directory where notices that could not be sent
+	// because of a network error, a server error or rate limiting are
+	// saved, to be replayed the next time the SDK is configured. Several
+	// processes may share it; each file is replayed by one of them.
+	// Spooling is disabled when empty.
+	SpoolDir string
+
+	// MaxSpoolFiles is the maximum number of notices kept in SpoolDir.
+	MaxSpoolFiles int
+
 	// Timeout is the HTTP request timeout.
 	Timeout time.Duration
 
+	// CircuitBreaker stops requests to the endpoint for a cooldown period
+	// after repeated send failures. Nil disables the circuit breaker.
+	CircuitBreaker *CircuitBreakerConfig
+
+	// RetryBackoffBase and RetryBackoffMax shape the delay between retries
+	// of a failed send: it doubles from the base on each attempt up to the
+	// max, and a random delay up to that value is used, so workers sharing
+	// an endpoint don't retry in lockstep after an outage. They default to
+	// DefaultRetryBackoffBase and DefaultRetryBackoffMax.
+	RetryBackoffBase time.Duration
+	RetryBackoffMax  time.Duration
+
 	// ConnectTimeout is the connection establishment timeout.
 	ConnectTimeout time.Duration
 
@@ -73,9 +231,67 @@ type Config struct {
 	// FilterKeys are additional keys to filter from payloads.
 	FilterKeys []string
 
-	// IgnoredErrors are error types or patterns to ignore.
+	// ExactFilterKeys makes FilterKeys match whole key names
+	// (case-insensitive) instead of substrings. Individual keys can opt into
+	// exact matching with an "=" prefix, e.g. "=key".
+	ExactFilterKeys bool
+
+	// ValueFilters redact matches inside string values, leaving the rest of
+	// the string intact. See DefaultValueFilters.
+	ValueFilters []*regexp.Regexp
+
+	// FilterPolicy bundles redaction settings into a single object. It is
+	// merged into the configuration's filter settings.
+	FilterPolicy *FilterPolicy
+
+	// SkipBacktraceForClasses lists error classes whose notices are sent
+	// without a backtrace. Entries match the resolved class exactly or its
+	// unqualified type name, e.g. "NotFoundError".
+	SkipBacktraceForClasses []string
+
+	// IgnoredErrors are error types or patterns to ignore. Entries may be
+	// type name strings, *regexp.Regexp values, reflect.Type values, or
+	// error instances.
 	IgnoredErrors []interface{}
 
+	// IgnoreMatchMessage makes *regexp.Regexp entries in IgnoredErrors also
+	// match against the error message.
+	IgnoreMatchMessage bool
+
+	// SampleRate is the fraction of notices sent, between 0 and 1.
+	// Zero means the default of 1 (send every notice); use Enabled to stop
+	// reporting entirely.
+	SampleRate float64
+
+	// SampleRatesByClass overrides SampleRate for specific error classes.
+	// Keys match the full error class or its unqualified type name.
+	SampleRatesByClass map[string]float64
+
+	// TargetNoticesPerSecond caps the sustained rate of notices sent. While
+	// notices arrive faster, the effective sample rate is lowered to match;
+	// at lower rates every notice is sent. Zero disables adaptive sampling.
+	TargetNoticesPerSecond float64
+
+	// SuppressConsecutiveDuplicates holds back a notice whose fingerprint
+	// matches the notice sent immediately before it, collapsing errors
+	// reported in a tight loop. When a different notice breaks the streak,
+	// or on Flush or Stop, the latest duplicate is sent with Occurrences
+	// set to the number held back. Notices without a fingerprint are
+	// compared by DefaultFingerprint.
+	SuppressConsecutiveDuplicates bool
+
+	// NormalizeMessage, when set, is applied to the message to compute a
+	// fingerprint for notices without an explicit one, so messages that only
+	// differ in IDs group together. The reported message is unchanged.
+	// DefaultNormalizeMessage is a ready-made normalizer.
+	NormalizeMessage func(string) string
+
+	// Fingerprinter, when set, computes the fingerprint for notices without
+	// an explicit WithFingerprint. It receives the assembled notice, whose
+	// Fingerprint already holds the NormalizeMessage result if configured.
+	// Returning an empty string leaves grouping to the server.
+	Fingerprinter func(*Notice) string
+
 	// BeforeNotify are callbacks to run before sending a notice.
 	// Return false to skip sending.
 	BeforeNotify []func(*Notice) bool
@@ -83,24 +299,103 @@ type Config struct {
 	// Debug enables debug logging.
 	Debug bool
 
+	// DryRun writes the JSON payload of each notice to the debug log
+	// instead of sending it, to see what would be reported without a valid
+	// API key or network access; set Debug as well to see the payloads.
+	// Notices still go through filtering, BeforeNotify callbacks and
+	// TransformPayload. Unless Enabled is set, it also enables reporting.
+	//
+	// Sends report success with a zero APIResponse, which can't be told
+	// apart from a response with ID 0; check DryRun rather than the ID.
+	DryRun bool
+
 	// AppName is the application identifier.
 	AppName string
 
 	// Revision is the code revision or commit hash.
 	Revision string
 
-	// RootPath is the application root path for cleaning backtraces.
+	// Hostname overrides the detected hostname sent with notices.
+	Hostname string
+
+	// SendHostname controls whether the hostname is included in notices
+	// (default: true).
+	SendHostname *bool
+
+	// ClientInfo identifies a product embedding the SDK, for white-label
+	// deployments that report to a shared Checkend account. It is sent as
+	// the payload's client block; the notifier block always describes the
+	// SDK itself.
+	ClientInfo *ClientInfo
+
+	// RootPath is the application root path for cleaning backtraces. A
+	// relative path is resolved against the working directory.
 	RootPath string
 
+	// BacktraceFilter, when set, decides which stack frames are reported:
+	// return true to keep a frame. It runs after the SDK's own frames are
+	// dropped and sees the frame's original, uncleaned file path. It does
+	// not apply to stacks supplied as text via WithStackTrace.
+	BacktraceFilter func(frame runtime.Frame) bool
+
+	// AppPackages lists the import paths of the application's own
+	// packages. When set, a backtrace frame is marked in-app if its
+	// function belongs to one of them or their subpackages, so library
+	// frames can be folded.
+	AppPackages []string
+
+	// InAppByPath marks backtrace frames outside the standard library, the
+	// module cache, and vendor directories as in-app when AppPackages is
+	// not set. Without either, no frame is marked in-app.
+	InAppByPath bool
+
+	// SourceContextLines is the number of source lines read before and
+	// after the line of each application backtrace frame and sent with it:
+	// in-app frames when AppPackages is set, otherwise frames outside the
+	// standard library, the module cache, and vendor directories. Zero
+	// disables source context. Files that can't be read, such as in a
+	// container without sources, are skipped, and at most
+	// MaxSourceContextBytes are read per notice.
+	SourceContextLines int
+
 	// SendRequestData controls whether request data is included in notices.
 	SendRequestData *bool
 
+	// CaptureRequestBody includes the request body in request data when
+	// SendRequestData is also enabled. JSON and form bodies are parsed so
+	// filter keys apply to their fields.
+	CaptureRequestBody bool
+
+	// CaptureRequestBodyOnError makes HTTPMiddleware buffer the request body
+	// and attach it only to notices for requests that panic, so bodies of
+	// successful requests are never parsed or sent.
+	CaptureRequestBodyOnError bool
+
+	// MaxRequestBodyBytes caps the captured request body size. Longer
+	// JSON and form bodies are replaced by "[TRUNCATED]", since a partial
+	// body can't be filtered; other bodies are cut off.
+	MaxRequestBodyBytes int
+
 	// SendSessionData controls whether session data is included in notices.
 	SendSessionData *bool
 
 	// SendEnvironment controls whether environment variables are included in notices.
 	SendEnvironment *bool
 
+	// EnvironmentAllowList, when set, limits the environment variables sent
+	// with SendEnvironment to those it names. Entries are exact names or
+	// globs such as "APP_*". Variables named exactly are always sent;
+	// variables matched by a glob are still dropped if they look sensitive.
+	// When empty, every variable that doesn't look sensitive is sent.
+	EnvironmentAllowList []string
+
+	// SensitiveEnvPatterns replaces DefaultSensitiveEnvPatterns as the
+	// environment variable names that look sensitive. Patterns match the
+	// name case-insensitively as substrings, like FilterKeys, and an "="
+	// prefix, e.g. "=DATABASE_URL", matches the whole name only. To extend
+	// the defaults, append to DefaultSensitiveEnvPatterns.
+	SensitiveEnvPatterns []string
+
 	// SendUserData controls whether user data is included in notices.
 	SendUserData *bool
 
@@ -109,52 +404,188 @@ type Config struct {
 
 	// SSLVerify controls TLS certificate verification.
 	SSLVerify *bool
+
+	// TransformPayload is called with each payload right before it is
+	// encoded, allowing fields to be added, changed, or removed.
+	TransformPayload func(*Payload)
+
+	// PrepareRequest is called with each HTTP request to the API right
+	// before it is sent, e.g. to sign it or add a short-lived token. The
+	// hook may read the body; it is restored afterwards, so the body sent
+	// is always the encoded payload.
+	PrepareRequest func(*http.Request)
+
+	// TraceExtractor returns the active trace and span IDs from a context,
+	// e.g. from an OpenTelemetry span. They are attached to the notice context.
+	TraceExtractor func(context.Context) (traceID, spanID string)
+
+	// Transport overrides the HTTP transport used to send notices.
+	// When set, Proxy, SSLVerify, and ConnectTimeout are not applied.
+	Transport http.RoundTripper
+
+	// MaxBreadcrumbs is the maximum number of breadcrumbs kept per context.
+	MaxBreadcrumbs int
+
+	// FieldNaming controls the casing of SDK-defined payload field names.
+	FieldNaming FieldNaming
+
+	// FieldNames renames individual SDK-defined payload fields
+	// (e.g. "occurred_at" to "timestamp"). Applied after FieldNaming.
+	FieldNames map[string]string
 }
 
 // Configuration is the resolved configuration for the SDK.
 type Configuration struct {
-	APIKey          string
-	Endpoint        string
-	Environment     string
-	Enabled         bool
-	AsyncSend       bool
-	MaxQueueSize    int
-	Timeout         time.Duration
-	ConnectTimeout  time.Duration
-	ShutdownTimeout time.Duration
-	FilterKeys      []string
-	IgnoredErrors   []interface{}
-	BeforeNotify    []func(*Notice) bool
-	Debug           bool
-	AppName         string
-	Revision        string
-	RootPath        string
-	SendRequestData bool
-	SendSessionData bool
-	SendEnvironment bool
-	SendUserData    bool
-	Proxy           string
-	SSLVerify       bool
+	APIKey                        string
+	Endpoint                      string
+	FallbackEndpoint              string
+	IngestPath                    string
+	BatchIngestPath               string
+	CompressMinBytes              int
+	Environment                   string
+	Enabled                       bool
+	AsyncSend                     bool
+	MaxQueueSize                  int
+	PropagateContext              bool
+	QueueFullPolicy               QueueFullPolicy
+	QueueFullTimeout              time.Duration
+	BatchSize                     int
+	BatchInterval                 time.Duration
+	FlushInterval                 time.Duration
+	NoticeTTL                     time.Duration
+	StartupGracePeriod            time.Duration
+	SpoolDir                      string
+	MaxSpoolFiles                 int
+	Timeout                       time.Duration
+	CircuitBreaker                *CircuitBreakerConfig
+	RetryBackoffBase              time.Duration
+	RetryBackoffMax               time.Duration
+	ConnectTimeout                time.Duration
+	ShutdownTimeout               time.Duration
+	FilterKeys                    []string
+	ExactFilterKeys               bool
+	FilterKeyPatterns             []*regexp.Regexp
+	ValueFilters                  []*regexp.Regexp
+	FilterAllowlist               []string
+	FilterPlaceholder             string
+	IgnoredErrors                 []interface{}
+	SkipBacktraceForClasses       []string
+	IgnoreMatchMessage            bool
+	SampleRate                    float64
+	SampleRatesByClass            map[string]float64
+	TargetNoticesPerSecond        float64
+	NormalizeMessage              func(string) string
+	Fingerprinter                 func(*Notice) string
+	SuppressConsecutiveDuplicates bool
+	BeforeNotify                  []func(*Notice) bool
+	Debug                         bool
+	DryRun                        bool
+	AppName                       string
+	Revision                      string
+	Hostname                      string
+	SendHostname                  bool
+	ClientInfo                    *ClientInfo
+	RootPath                      string
+	BacktraceFilter               func(runtime.Frame) bool
+	AppPackages                   []string
+	InAppByPath                   bool
+	SourceContextLines            int
+	SendRequestData               bool
+	CaptureRequestBody            bool
+	CaptureRequestBodyOnError     bool
+	MaxRequestBodyBytes           int
+	SendSessionData               bool
+	SendEnvironment               bool
+	EnvironmentAllowList          []string
+	SensitiveEnvPatterns          []string
+	SendUserData                  bool
+	Proxy                         string
+	SSLVerify                     bool
+	TraceExtractor                func(context.Context) (traceID, spanID string)
+	TransformPayload              func(*Payload)
+	PrepareRequest                func(*http.Request)
+	Transport                     http.RoundTripper
+	MaxBreadcrumbs                int
+	FieldNaming                   FieldNaming
+	FieldNames                    map[string]string
+
+	// ignoreFilter is compiled once from IgnoredErrors.
+	ignoreFilter *IgnoreFilter
+
+	// breaker is shared by every client created from this configuration.
+	breaker *circuitBreaker
+
+	// fallbackBreaker tracks the health of FallbackEndpoint separately.
+	fallbackBreaker *circuitBreaker
+
+	// sampler applies TargetNoticesPerSecond.
+	sampler *adaptiveSampler
+
+	// duplicates applies SuppressConsecutiveDuplicates.
+	duplicates *duplicateStreak
+
+	// callbacks detects notices reported from inside the callbacks that
+	// build or encode a notice.
+	callbacks *callbackGuard
 }
 
 // NewConfiguration creates a new Configuration from Config.
 func NewConfiguration(cfg Config) *Configuration {
 	c := &Configuration{
-		APIKey:          cfg.APIKey,
-		AsyncSend:       true,
-		MaxQueueSize:    DefaultMaxQueueSize,
-		Timeout:         DefaultTimeout,
-		ConnectTimeout:  DefaultConnectTimeout,
-		ShutdownTimeout: DefaultShutdownTimeout,
-		FilterKeys:      append([]string{}, DefaultFilterKeys...),
-		IgnoredErrors:   cfg.IgnoredErrors,
-		BeforeNotify:    cfg.BeforeNotify,
-		Debug:           cfg.Debug,
-		SendRequestData: true,
-		SendSessionData: true,
-		SendEnvironment: false,
-		SendUserData:    true,
-		SSLVerify:       true,
+		APIKey:                        cfg.APIKey,
+		AsyncSend:                     true,
+		MaxQueueSize:                  DefaultMaxQueueSize,
+		PropagateContext:              cfg.PropagateContext,
+		QueueFullPolicy:               cfg.QueueFullPolicy,
+		QueueFullTimeout:              DefaultQueueFullTimeout,
+		BatchSize:                     cfg.BatchSize,
+		BatchInterval:                 DefaultBatchInterval,
+		NoticeTTL:                     cfg.NoticeTTL,
+		StartupGracePeriod:            cfg.StartupGracePeriod,
+		SpoolDir:                      cfg.SpoolDir,
+		MaxSpoolFiles:                 DefaultMaxSpoolFiles,
+		Timeout:                       DefaultTimeout,
+		CircuitBreaker:                cfg.CircuitBreaker,
+		RetryBackoffBase:              DefaultRetryBackoffBase,
+		RetryBackoffMax:               DefaultRetryBackoffMax,
+		breaker:                       newCircuitBreaker(cfg.CircuitBreaker),
+		fallbackBreaker:               newCircuitBreaker(cfg.CircuitBreaker),
+		FallbackEndpoint:              cfg.FallbackEndpoint,
+		CompressMinBytes:              DefaultCompressMinBytes,
+		ConnectTimeout:                DefaultConnectTimeout,
+		ShutdownTimeout:               DefaultShutdownTimeout,
+		FilterKeys:                    append([]string{}, DefaultFilterKeys...),
+		ExactFilterKeys:               cfg.ExactFilterKeys,
+		IgnoredErrors:                 cfg.IgnoredErrors,
+		SkipBacktraceForClasses:       cfg.SkipBacktraceForClasses,
+		IgnoreMatchMessage:            cfg.IgnoreMatchMessage,
+		SampleRate:                    1,
+		SampleRatesByClass:            cfg.SampleRatesByClass,
+		TargetNoticesPerSecond:        cfg.TargetNoticesPerSecond,
+		sampler:                       newAdaptiveSampler(cfg.TargetNoticesPerSecond),
+		NormalizeMessage:              cfg.NormalizeMessage,
+		Fingerprinter:                 cfg.Fingerprinter,
+		SuppressConsecutiveDuplicates: cfg.SuppressConsecutiveDuplicates,
+		duplicates:                    newDuplicateStreak(cfg.SuppressConsecutiveDuplicates),
+		BeforeNotify:                  cfg.BeforeNotify,
+		Debug:                         cfg.Debug,
+		DryRun:                        cfg.DryRun,
+		SendRequestData:               true,
+		CaptureRequestBody:            cfg.CaptureRequestBody,
+		CaptureRequestBodyOnError:     cfg.CaptureRequestBodyOnError,
+		MaxRequestBodyBytes:           DefaultMaxRequestBodyBytes,
+		SendSessionData:               true,
+		SendHostname:                  true,
+		SendEnvironment:               false,
+		SendUserData:                  true,
+		SSLVerify:                     true,
+		TraceExtractor:                cfg.TraceExtractor,
+		TransformPayload:              cfg.TransformPayload,
+		PrepareRequest:                cfg.PrepareRequest,
+		Transport:                     cfg.Transport,
+		MaxBreadcrumbs:                DefaultMaxBreadcrumbs,
+		FieldNaming:                   cfg.FieldNaming,
+		FieldNames:                    cfg.FieldNames,
 	}
 
 	// API key from environment
@@ -171,6 +602,18 @@ func NewConfiguration(cfg Config) *Configuration {
 		c.Endpoint = DefaultEndpoint
 	}
 
+	c.callbacks = &callbackGuard{}
+
+	// Ingest paths
+	c.IngestPath = cfg.IngestPath
+	if c.IngestPath == "" {
+		c.IngestPath = DefaultIngestPath
+	}
+	c.BatchIngestPath = cfg.BatchIngestPath
+	if c.BatchIngestPath == "" {
+		c.BatchIngestPath = DefaultBatchIngestPath
+	}
+
 	// Environment
 	c.Environment = cfg.Environment
 	if c.Environment == "" {
@@ -180,11 +623,17 @@ func NewConfiguration(cfg Config) *Configuration {
 		c.Environment = detectEnvironment()
 	}
 
+	// DryRun from environment
+	if !c.DryRun {
+		dryRunEnv := strings.ToLower(os.Getenv("CHECKEND_DRY_RUN"))
+		c.DryRun = dryRunEnv == "true" || dryRunEnv == "1" || dryRunEnv == "yes"
+	}
+
 	// Enabled
 	if cfg.Enabled != nil {
 		c.Enabled = *cfg.Enabled
 	} else {
-		c.Enabled = c.Environment == "production" || c.Environment == "staging"
+		c.Enabled = c.DryRun || c.Environment == "production" || c.Environment == "staging"
 	}
 
 	// AsyncSend - use explicit value only if set, otherwise keep default (true)
@@ -198,6 +647,41 @@ func NewConfiguration(cfg Config) *Configuration {
 	// MaxQueueSize
 	if cfg.MaxQueueSize > 0 {
 		c.MaxQueueSize = cfg.MaxQueueSize
+	} else if cfg.MaxQueueSize < 0 {
+		logMessage(c, "warning", fmt.Sprintf("Invalid MaxQueueSize %d, using default %d", cfg.MaxQueueSize, DefaultMaxQueueSize))
+	}
+
+	// QueueFullTimeout
+	if cfg.QueueFullTimeout > 0 {
+		c.QueueFullTimeout = cfg.QueueFullTimeout
+	}
+
+	// Retry backoff
+	if cfg.RetryBackoffBase > 0 {
+		c.RetryBackoffBase = cfg.RetryBackoffBase
+	}
+	if cfg.RetryBackoffMax > 0 {
+		c.RetryBackoffMax = cfg.RetryBackoffMax
+	}
+
+	// BatchInterval
+	if cfg.BatchInterval > 0 {
+		c.BatchInterval = cfg.BatchInterval
+	}
+
+	// FlushInterval
+	if cfg.FlushInterval > 0 {
+		c.FlushInterval = cfg.FlushInterval
+	}
+
+	// MaxSpoolFiles
+	if cfg.MaxSpoolFiles > 0 {
+		c.MaxSpoolFiles = cfg.MaxSpoolFiles
+	}
+
+	// CompressMinBytes
+	if cfg.CompressMinBytes != 0 {
+		c.CompressMinBytes = cfg.CompressMinBytes
 	}
 
 	// Timeout
@@ -215,9 +699,40 @@ func NewConfiguration(cfg Config) *Configuration {
 		c.ShutdownTimeout = cfg.ShutdownTimeout
 	}
 
+	// SampleRate
+	if cfg.SampleRate > 0 {
+		c.SampleRate = cfg.SampleRate
+	}
+
+	// MaxRequestBodyBytes
+	if cfg.MaxRequestBodyBytes > 0 {
+		c.MaxRequestBodyBytes = cfg.MaxRequestBodyBytes
+	}
+
+	// MaxBreadcrumbs
+	if cfg.MaxBreadcrumbs > 0 {
+		c.MaxBreadcrumbs = cfg.MaxBreadcrumbs
+	}
+
 	// FilterKeys
 	c.FilterKeys = append(c.FilterKeys, cfg.FilterKeys...)
 
+	// ValueFilters
+	c.ValueFilters = append(c.ValueFilters, cfg.ValueFilters...)
+
+	// FilterPolicy
+	if p := cfg.FilterPolicy; p != nil {
+		c.FilterKeys = append(c.FilterKeys, p.FilterKeys...)
+		c.FilterKeyPatterns = append(c.FilterKeyPatterns, p.FilterKeyPatterns...)
+		c.ValueFilters = append(c.ValueFilters, p.ValuePatterns...)
+		c.FilterAllowlist = append(c.FilterAllowlist, p.Allowlist...)
+		c.FilterPlaceholder = p.Placeholder
+	}
+
+	// IgnoredErrors
+	c.ignoreFilter = NewIgnoreFilter(c.IgnoredErrors)
+	c.ignoreFilter.MatchMessage = c.IgnoreMatchMessage
+
 	// Debug from environment
 	if !c.Debug {
 		debugEnv := strings.ToLower(os.Getenv("CHECKEND_DEBUG"))
@@ -239,11 +754,43 @@ func NewConfiguration(cfg Config) *Configuration {
 		c.Revision = os.Getenv("GIT_COMMIT")
 	}
 
+	// Hostname
+	c.Hostname = cfg.Hostname
+	if c.Hostname == "" {
+		c.Hostname = os.Getenv("CHECKEND_HOSTNAME")
+	}
+
+	// SendHostname (default true, explicit false overrides)
+	if cfg.SendHostname != nil {
+		c.SendHostname = *cfg.SendHostname
+	}
+
+	// Client identity
+	if cfg.ClientInfo != nil {
+		client := *cfg.ClientInfo
+		c.ClientInfo = &client
+	}
+
+	// Backtrace frames
+	c.BacktraceFilter = cfg.BacktraceFilter
+	c.AppPackages = cfg.AppPackages
+	c.InAppByPath = cfg.InAppByPath
+	if cfg.SourceContextLines > 0 {
+		c.SourceContextLines = cfg.SourceContextLines
+	}
+
 	// RootPath
 	c.RootPath = cfg.RootPath
 	if c.RootPath == "" {
 		c.RootPath = os.Getenv("CHECKEND_ROOT_PATH")
 	}
+	// Frame files are absolute, so resolve a relative root against the
+	// working directory
+	if c.RootPath != "" && !filepath.IsAbs(c.RootPath) {
+		if abs, err := filepath.Abs(c.RootPath); err == nil {
+			c.RootPath = abs
+		}
+	}
 
 	// SendRequestData (default true, explicit false overrides)
 	if cfg.SendRequestData != nil {
@@ -260,6 +807,13 @@ func NewConfiguration(cfg Config) *Configuration {
 		c.SendEnvironment = *cfg.SendEnvironment
 	}
 
+	c.EnvironmentAllowList = cfg.EnvironmentAllowList
+
+	c.SensitiveEnvPatterns = append([]string{}, DefaultSensitiveEnvPatterns...)
+	if len(cfg.SensitiveEnvPatterns) > 0 {
+		c.SensitiveEnvPatterns = append([]string{}, cfg.SensitiveEnvPatterns...)
+	}
+
 	// SendUserData (default true, explicit false overrides)
 	if cfg.SendUserData != nil {
 		c.SendUserData = *cfg.SendUserData
@@ -287,6 +841,76 @@ func NewConfiguration(cfg Config) *Configuration {
 	return c
 }
 
+// ValidateConfiguration checks c for settings that would otherwise only
+// fail later, when notices are sent. All problems found are returned
+// together.
+func ValidateConfiguration(c *Configuration) error {
+	if c == nil {
+		return errors.New("checkend: configuration is nil")
+	}
+
+	var errs []error
+	if c.Enabled && c.APIKey == "" && !c.DryRun {
+		errs = append(errs, errors.New("checkend: api_key is required when enabled"))
+	}
+	if err := validateEndpoint("endpoint", c.Endpoint); err != nil {
+		errs = append(errs, err)
+	}
+	if c.FallbackEndpoint != "" {
+		if err := validateEndpoint("fallback_endpoint", c.FallbackEndpoint); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := validateIngestPath("ingest_path", c.IngestPath); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateIngestPath("batch_ingest_path", c.BatchIngestPath); err != nil {
+		errs = append(errs, err)
+	}
+	if c.MaxQueueSize <= 0 {
+		errs = append(errs, fmt.Errorf("checkend: max_queue_size must be positive, got %d", c.MaxQueueSize))
+	}
+	for _, timeout := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"timeout", c.Timeout},
+		{"connect_timeout", c.ConnectTimeout},
+		{"shutdown_timeout", c.ShutdownTimeout},
+	} {
+		if timeout.value <= 0 {
+			errs = append(errs, fmt.Errorf("checkend: %s must be positive, got %s", timeout.name, timeout.value))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateEndpoint checks that endpoint is an absolute http or https URL.
+func validateEndpoint(name, endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("checkend: invalid %s %q: %w", name, endpoint, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("checkend: invalid %s %q: must be an http or https URL", name, endpoint)
+	}
+	return nil
+}
+
+// validateIngestPath checks that path is an absolute URL path without a
+// scheme, host, or query.
+func validateIngestPath(name, path string) error {
+	u, err := url.Parse(path)
+	if err != nil {
+		return fmt.Errorf("checkend: invalid %s %q: %w", name, path, err)
+	}
+	if !strings.HasPrefix(path, "/") || u.Scheme != "" || u.Host != "" || u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("checkend: invalid %s %q: must be a path starting with /", name, path)
+	}
+	return nil
+}
+
 func detectEnvironment() string {
 	envVars := []string{
 		"GO_ENV",
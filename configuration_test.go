@@ -2,6 +2,7 @@ package checkend
 
 import (
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -155,6 +156,60 @@ func TestConfigurationCustomMaxQueueSize(t *testing.T) {
 	}
 }
 
+func TestConfigurationInvalidMaxQueueSize(t *testing.T) {
+	for _, size := range []int{0, -1, -1000} {
+		cfg := NewConfiguration(Config{
+			APIKey:       "test-key",
+			MaxQueueSize: size,
+		})
+
+		if cfg.MaxQueueSize != DefaultMaxQueueSize {
+			t.Errorf("MaxQueueSize %d: expected default %d, got %d", size, DefaultMaxQueueSize, cfg.MaxQueueSize)
+		}
+	}
+}
+
+func TestValidateConfiguration(t *testing.T) {
+	valid := NewConfiguration(Config{APIKey: "test-key", Enabled: boolPtr(true)})
+	if err := ValidateConfiguration(valid); err != nil {
+		t.Errorf("Expected valid configuration, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*Configuration)
+		want   string
+	}{
+		{"missing api key", func(c *Configuration) { c.APIKey = "" }, "api_key is required"},
+		{"malformed endpoint", func(c *Configuration) { c.Endpoint = "://bad" }, "invalid endpoint"},
+		{"non-http endpoint", func(c *Configuration) { c.Endpoint = "ftp://example.com" }, "must be an http or https URL"},
+		{"bad fallback endpoint", func(c *Configuration) { c.FallbackEndpoint = "localhost" }, "invalid fallback_endpoint"},
+		{"relative ingest path", func(c *Configuration) { c.IngestPath = "ingest/errors" }, "invalid ingest_path"},
+		{"absolute batch ingest path", func(c *Configuration) { c.BatchIngestPath = "https://example.com/batch" }, "invalid batch_ingest_path"},
+		{"zero queue size", func(c *Configuration) { c.MaxQueueSize = 0 }, "max_queue_size must be positive"},
+		{"negative timeout", func(c *Configuration) { c.Timeout = -time.Second }, "timeout must be positive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewConfiguration(Config{APIKey: "test-key", Enabled: boolPtr(true)})
+			tt.modify(cfg)
+
+			err := ValidateConfiguration(cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestValidateConfigurationAllowsMissingAPIKeyWhenDisabled(t *testing.T) {
+	cfg := NewConfiguration(Config{Enabled: boolPtr(false)})
+	if err := ValidateConfiguration(cfg); err != nil {
+		t.Errorf("Expected disabled configuration to be valid, got %v", err)
+	}
+}
+
 func TestConfigurationDebugFromEnv(t *testing.T) {
 	os.Setenv("CHECKEND_DEBUG", "true")
 	defer os.Unsetenv("CHECKEND_DEBUG")
@@ -165,3 +220,98 @@ func TestConfigurationDebugFromEnv(t *testing.T) {
 		t.Error("Expected Debug to be true from env")
 	}
 }
+
+func TestConfigurationExactFilterKeys(t *testing.T) {
+	cfg := NewConfiguration(Config{
+		APIKey:          "test-key",
+		FilterKeys:      []string{"key"},
+		ExactFilterKeys: true,
+	})
+
+	result := NewNoticeBuilder(cfg).sanitizeFilter.Filter(map[string]interface{}{
+		"key":           "secret",
+		"public_key_id": "pk_1",
+	})
+
+	if result["key"] != "[FILTERED]" {
+		t.Error("Expected key to be filtered")
+	}
+	if result["public_key_id"] != "pk_1" {
+		t.Errorf("Expected public_key_id to be kept, got %v", result["public_key_id"])
+	}
+}
+
+func TestConfigurationHostnameFromEnv(t *testing.T) {
+	os.Setenv("CHECKEND_HOSTNAME", "web-1")
+	defer os.Unsetenv("CHECKEND_HOSTNAME")
+
+	cfg := NewConfiguration(Config{APIKey: "test-key"})
+
+	if hostname := NewNoticeBuilder(cfg).getHostname(); hostname != "web-1" {
+		t.Errorf("Expected hostname 'web-1' from env, got '%s'", hostname)
+	}
+}
+
+func TestConfigurationHostnameOverride(t *testing.T) {
+	cfg := NewConfiguration(Config{APIKey: "test-key", Hostname: "api-7"})
+
+	notice := newTestNotice(cfg)
+	payload := notice.ToPayload()
+	if payload.Server == nil || payload.Server.Hostname != "api-7" {
+		t.Errorf("Expected hostname 'api-7', got %+v", payload.Server)
+	}
+}
+
+func TestConfigurationSendHostnameDisabled(t *testing.T) {
+	disabled := false
+	cfg := NewConfiguration(Config{APIKey: "test-key", Hostname: "api-7", SendHostname: &disabled})
+
+	notice := newTestNotice(cfg)
+	if notice.Hostname != "" {
+		t.Errorf("Expected no hostname, got '%s'", notice.Hostname)
+	}
+	if payload := notice.ToPayload(); payload.Server != nil {
+		t.Errorf("Expected the server block to be omitted, got %+v", payload.Server)
+	}
+}
+
+func TestConfigurationResolvesRelativeRootPath(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	cfg := NewConfiguration(Config{APIKey: "test-key", RootPath: "./"})
+
+	if cfg.RootPath != wd {
+		t.Errorf("Expected RootPath '%s', got '%s'", wd, cfg.RootPath)
+	}
+
+	notice := newTestNotice(cfg)
+	var found bool
+	for _, line := range notice.Backtrace {
+		if strings.HasPrefix(line, wd) {
+			t.Errorf("Expected backtrace paths to be cleaned, got %s", line)
+		}
+		if strings.HasPrefix(line, "configuration_test.go:") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected a cleaned frame for this test file, got %v", notice.Backtrace)
+	}
+}
+
+func TestConfigurationDryRunFromEnv(t *testing.T) {
+	t.Setenv("CHECKEND_DRY_RUN", "1")
+	t.Setenv("GO_ENV", "development")
+
+	cfg := NewConfiguration(Config{})
+
+	if !cfg.DryRun {
+		t.Error("Expected DryRun to be true from env")
+	}
+	if !cfg.Enabled {
+		t.Error("Expected dry run to enable reporting")
+	}
+}
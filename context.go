@@ -2,6 +2,7 @@ package checkend
 
 import (
 	"context"
+	"sync"
 )
 
 // Context keys for storing Checkend data.
@@ -16,6 +17,43 @@ type ContextData struct {
 	Context map[string]interface{}
 	User    map[string]interface{}
 	Request map[string]interface{}
+	Session string
+
+	// layers holds the context added by SetContext, merged into Context
+	// only when it is read, so a chain of middleware each adding a key
+	// doesn't copy the whole map at every step.
+	layers *contextLayer
+
+	breadcrumbs *breadcrumbBuffer
+}
+
+// contextLayer is the context data added by one SetContext call on top of
+// its parent's.
+type contextLayer struct {
+	parent *contextLayer
+	values map[string]interface{}
+
+	once sync.Once
+	flat map[string]interface{}
+}
+
+// flatten merges the layer with its ancestors, later layers winning. The
+// result is computed once and shared.
+func (l *contextLayer) flatten() map[string]interface{} {
+	l.once.Do(func() {
+		var chain []*contextLayer
+		for layer := l; layer != nil; layer = layer.parent {
+			chain = append(chain, layer)
+		}
+
+		l.flat = make(map[string]interface{})
+		for i := len(chain) - 1; i >= 0; i-- {
+			for k, v := range chain[i].values {
+				l.flat[k] = v
+			}
+		}
+	})
+	return l.flat
 }
 
 // WithContextData returns a new context with Checkend data.
@@ -23,8 +61,32 @@ func WithContextData(ctx context.Context, data *ContextData) context.Context {
 	return context.WithValue(ctx, contextDataKey, data)
 }
 
-// GetContextData retrieves Checkend data from the context.
+// GetContextData retrieves Checkend data from the context. The maps are
+// copies, so changing them doesn't affect ctx or the notices reported with
+// it.
 func GetContextData(ctx context.Context) *ContextData {
+	data := flatContextData(ctx)
+	data.Context = copyMap(data.Context)
+	data.User = copyMap(data.User)
+	data.Request = copyMap(data.Request)
+	return data
+}
+
+// flatContextData is GetContextData without copying the maps, which may be
+// shared with ctx and other contexts. Callers must not modify them.
+func flatContextData(ctx context.Context) *ContextData {
+	data := contextData(ctx)
+	flattened := *data
+	if data.layers != nil {
+		flattened.Context = data.layers.flatten()
+		flattened.layers = nil
+	}
+	return &flattened
+}
+
+// contextData returns the Checkend data stored in ctx without merging its
+// context layers.
+func contextData(ctx context.Context) *ContextData {
 	if data, ok := ctx.Value(contextDataKey).(*ContextData); ok {
 		return data
 	}
@@ -35,41 +97,90 @@ func GetContextData(ctx context.Context) *ContextData {
 	}
 }
 
+// clone returns a shallow copy of d to be modified and stored in a derived
+// context.
+func (d *ContextData) clone() *ContextData {
+	c := *d
+	return &c
+}
+
 // SetContext adds context data to the given context.
 func SetContext(ctx context.Context, data map[string]interface{}) context.Context {
-	ctxData := GetContextData(ctx)
-	newData := &ContextData{
-		Context: make(map[string]interface{}),
-		User:    ctxData.User,
-		Request: ctxData.Request,
-	}
-	for k, v := range ctxData.Context {
-		newData.Context[k] = v
-	}
-	for k, v := range data {
-		newData.Context[k] = v
+	ctxData := contextData(ctx)
+
+	parent := ctxData.layers
+	if parent == nil && len(ctxData.Context) > 0 {
+		parent = &contextLayer{values: copyMap(ctxData.Context)}
 	}
+
+	newData := ctxData.clone()
+	newData.Context = nil
+	newData.layers = &contextLayer{parent: parent, values: copyMap(data)}
 	return WithContextData(ctx, newData)
 }
 
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		copied[k] = v
+	}
+	return copied
+}
+
 // SetUser sets user information in the given context.
 func SetUser(ctx context.Context, user map[string]interface{}) context.Context {
-	ctxData := GetContextData(ctx)
-	newData := &ContextData{
-		Context: ctxData.Context,
-		User:    user,
-		Request: ctxData.Request,
-	}
+	newData := contextData(ctx).clone()
+	newData.User = user
 	return WithContextData(ctx, newData)
 }
 
 // SetRequest sets request information in the given context.
 func SetRequest(ctx context.Context, request map[string]interface{}) context.Context {
-	ctxData := GetContextData(ctx)
-	newData := &ContextData{
-		Context: ctxData.Context,
-		User:    ctxData.User,
-		Request: request,
-	}
+	newData := contextData(ctx).clone()
+	newData.Request = request
 	return WithContextData(ctx, newData)
 }
+
+// SetSession sets the ID of the user session the context belongs to, so
+// notices can be counted per session. It is only sent when SendSessionData
+// is enabled.
+func SetSession(ctx context.Context, sessionID string) context.Context {
+	newData := contextData(ctx).clone()
+	newData.Session = sessionID
+	return WithContextData(ctx, newData)
+}
+
+var (
+	globalContextMu sync.RWMutex
+	globalContext   map[string]interface{}
+)
+
+// SetGlobalContext adds app-wide context, such as the service version or
+// datacenter, to every notice. Keys are merged into any global context set
+// earlier. Context set with SetContext or WithContext takes precedence on
+// conflicting keys. Global context is shared by every Notifier.
+func SetGlobalContext(data map[string]interface{}) {
+	globalContextMu.Lock()
+	defer globalContextMu.Unlock()
+
+	// Replace rather than mutate, so readers can use the map unlocked
+	merged := copyMap(globalContext)
+	for k, v := range data {
+		merged[k] = v
+	}
+	globalContext = merged
+}
+
+// ClearGlobalContext removes all context set with SetGlobalContext.
+func ClearGlobalContext() {
+	globalContextMu.Lock()
+	defer globalContextMu.Unlock()
+	globalContext = nil
+}
+
+// globalContextValues returns the global context. It must not be modified.
+func globalContextValues() map[string]interface{} {
+	globalContextMu.RLock()
+	defer globalContextMu.RUnlock()
+	return globalContext
+}
@@ -0,0 +1,166 @@
+package checkend
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// copyingSetContext is the previous SetContext, which copied the whole
+// context map on every call. It is the reference for the layered version.
+func copyingSetContext(ctx context.Context, data map[string]interface{}) context.Context {
+	ctxData := GetContextData(ctx)
+	newData := &ContextData{
+		Context: make(map[string]interface{}),
+		User:    ctxData.User,
+		Request: ctxData.Request,
+		Session: ctxData.Session,
+	}
+	for k, v := range ctxData.Context {
+		newData.Context[k] = v
+	}
+	for k, v := range data {
+		newData.Context[k] = v
+	}
+	return WithContextData(ctx, newData)
+}
+
+func TestSetContextMatchesCopyingBehavior(t *testing.T) {
+	steps := []map[string]interface{}{
+		{"request_id": "abc"},
+		{"tenant": "acme", "region": "eu"},
+		nil,
+		{"region": "us"},
+		{"tenant": nil},
+	}
+
+	layered := context.Background()
+	copying := context.Background()
+	for i, data := range steps {
+		layered = SetContext(layered, data)
+		copying = copyingSetContext(copying, data)
+
+		// Other setters must carry the layers along
+		layered = SetUser(layered, map[string]interface{}{"id": i})
+		copying = SetUser(copying, map[string]interface{}{"id": i})
+
+		got, want := GetContextData(layered).Context, GetContextData(copying).Context
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("After step %d: expected %v, got %v", i, want, got)
+		}
+	}
+}
+
+func TestSetContextBranchesAreIndependent(t *testing.T) {
+	data := map[string]interface{}{"request_id": "abc"}
+	parent := SetContext(context.Background(), data)
+	left := SetContext(parent, map[string]interface{}{"branch": "left"})
+	right := SetContext(parent, map[string]interface{}{"branch": "right"})
+
+	// Mutating the caller's map afterwards must not leak into the context
+	data["request_id"] = "changed"
+
+	if got := GetContextData(left).Context; got["branch"] != "left" || got["request_id"] != "abc" {
+		t.Errorf("Unexpected left context %v", got)
+	}
+	if got := GetContextData(right).Context; got["branch"] != "right" {
+		t.Errorf("Unexpected right context %v", got)
+	}
+	if got := GetContextData(parent).Context; len(got) != 1 {
+		t.Errorf("Expected the parent context to be unchanged, got %v", got)
+	}
+}
+
+func TestSetContextOnExplicitContextData(t *testing.T) {
+	ctx := WithContextData(context.Background(), &ContextData{
+		Context: map[string]interface{}{"service": "billing"},
+	})
+	ctx = SetContext(ctx, map[string]interface{}{"job": "invoice"})
+
+	got := GetContextData(ctx).Context
+	if got["service"] != "billing" || got["job"] != "invoice" {
+		t.Errorf("Expected explicit context data to be kept, got %v", got)
+	}
+}
+
+func TestGetContextDataReturnsCopies(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{APIKey: "test-key", Enabled: boolPtr(true)})
+
+	ctx := SetContext(context.Background(), map[string]interface{}{"order_id": 42})
+	ctx = SetContext(ctx, map[string]interface{}{"step": "charge"})
+	ctx = SetUser(ctx, map[string]interface{}{"id": "user-1"})
+
+	data := GetContextData(ctx)
+	data.Context["order_id"] = "tampered"
+	data.User["id"] = "tampered"
+
+	again := GetContextData(ctx)
+	if again.Context["order_id"] != 42 || again.User["id"] != "user-1" {
+		t.Errorf("Expected context data to be unchanged, got %v and %v", again.Context, again.User)
+	}
+
+	NotifyWithContext(ctx, fmt.Errorf("boom"))
+	if got := TestingLastNotice().Context["order_id"]; got != 42 {
+		t.Errorf("Expected the notice to keep the original context, got %v", got)
+	}
+}
+
+func benchmarkContextChain(b *testing.B, set func(context.Context, map[string]interface{}) context.Context) {
+	keys := make([]string, 100)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("middleware_%d", i)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ctx := context.Background()
+		for _, key := range keys {
+			ctx = set(ctx, map[string]interface{}{key: true})
+		}
+		_ = GetContextData(ctx).Context
+	}
+}
+
+func TestSetGlobalContext(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{APIKey: "test-key", Enabled: boolPtr(true)})
+
+	SetGlobalContext(map[string]interface{}{"service_version": "1.4.2", "datacenter": "fra1", "region": "eu"})
+	SetGlobalContext(map[string]interface{}{"shard": 3})
+
+	ctx := SetContext(context.Background(), map[string]interface{}{"datacenter": "ams3"})
+	NotifyWithContext(ctx, fmt.Errorf("boom"), WithContext(map[string]interface{}{"region": "us"}))
+
+	got := TestingLastNotice().Context
+	expected := map[string]interface{}{
+		"service_version": "1.4.2",
+		"shard":           3,
+		"datacenter":      "ams3",
+		"region":          "us",
+	}
+	for k, v := range expected {
+		if got[k] != v {
+			t.Errorf("Expected context %s=%v, got %v", k, v, got[k])
+		}
+	}
+
+	ClearGlobalContext()
+	Notify(fmt.Errorf("boom"))
+	if _, ok := TestingLastNotice().Context["service_version"]; ok {
+		t.Error("Expected global context to be cleared")
+	}
+}
+
+func BenchmarkSetContextChain(b *testing.B) {
+	benchmarkContextChain(b, SetContext)
+}
+
+func BenchmarkSetContextChainCopying(b *testing.B) {
+	benchmarkContextChain(b, copyingSetContext)
+}
@@ -0,0 +1,182 @@
+package checkend
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxRecentNotices is the number of notices kept for the debug handler.
+const maxRecentNotices = 20
+
+// recentNotice summarizes a notice for the debug handler.
+type recentNotice struct {
+	ErrorClass  string    `json:"error_class"`
+	Message     string    `json:"message"`
+	Fingerprint string    `json:"fingerprint,omitempty"`
+	Severity    string    `json:"severity,omitempty"`
+	OccurredAt  time.Time `json:"occurred_at"`
+
+	// notice is the full notice, kept for ExportNotices.
+	notice *Notice
+}
+
+// sendError records the most recent delivery failure.
+type sendError struct {
+	Message    string    `json:"message"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+var (
+	debugMu       sync.Mutex
+	recentNotices []recentNotice
+	lastSendError *sendError
+)
+
+// recordRecentNotice adds a notice to the ring of recent notices.
+func recordRecentNotice(notice *Notice) {
+	debugMu.Lock()
+	defer debugMu.Unlock()
+
+	recentNotices = append(recentNotices, recentNotice{
+		ErrorClass:  notice.ErrorClass,
+		Message:     notice.Message,
+		Fingerprint: notice.Fingerprint,
+		Severity:    notice.Severity,
+		OccurredAt:  notice.OccurredAt,
+		notice:      notice,
+	})
+	if len(recentNotices) > maxRecentNotices {
+		recentNotices = recentNotices[len(recentNotices)-maxRecentNotices:]
+	}
+}
+
+// recordSendError remembers the most recent delivery failure.
+func recordSendError(err error) {
+	debugMu.Lock()
+	defer debugMu.Unlock()
+	lastSendError = &sendError{Message: err.Error(), OccurredAt: time.Now().UTC()}
+}
+
+func clearDebugState() {
+	debugMu.Lock()
+	defer debugMu.Unlock()
+	recentNotices = nil
+	lastSendError = nil
+}
+
+// debugConfig is the redacted configuration shown by the debug handler.
+type debugConfig struct {
+	APIKey       string `json:"api_key"`
+	Endpoint     string `json:"endpoint"`
+	Environment  string `json:"environment"`
+	Enabled      bool   `json:"enabled"`
+	AsyncSend    bool   `json:"async_send"`
+	MaxQueueSize int    `json:"max_queue_size"`
+	BatchSize    int    `json:"batch_size"`
+	AppName      string `json:"app_name,omitempty"`
+	Revision     string `json:"revision,omitempty"`
+}
+
+type debugStats struct {
+	QueueDepth int `json:"queue_depth"`
+	Pending    int `json:"pending"`
+	Dropped    int `json:"dropped"`
+}
+
+type debugStatus struct {
+	Configured    bool           `json:"configured"`
+	Config        *debugConfig   `json:"config,omitempty"`
+	Stats         debugStats     `json:"stats"`
+	RecentNotices []recentNotice `json:"recent_notices"`
+	LastError     *sendError     `json:"last_error"`
+}
+
+// DebugHandler returns an HTTP handler exposing the SDK's state as JSON:
+// a redacted configuration summary, worker stats, recent notices, and the
+// last delivery error. A POST with a JSON body such as {"enabled": false}
+// mutes or re-enables reporting.
+//
+// The handler performs no authentication; mount it behind your own, e.g.
+// at /debug/checkend.
+func DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			var body struct {
+				Enabled *bool `json:"enabled"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Enabled == nil {
+				http.Error(w, `expected a JSON body like {"enabled": true}`, http.StatusBadRequest)
+				return
+			}
+			if !setEnabled(*body.Enabled) {
+				http.Error(w, "checkend is not configured", http.StatusConflict)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(currentDebugStatus())
+	})
+}
+
+// setEnabled toggles reporting, returning false if the SDK isn't configured.
+func setEnabled(enabled bool) bool {
+	n := defaultNotifier()
+	if n == nil {
+		return false
+	}
+	n.setEnabled(enabled)
+	return true
+}
+
+func currentDebugStatus() debugStatus {
+	var status debugStatus
+
+	if n := defaultNotifier(); n != nil {
+		n.mu.RLock()
+		config, worker := n.config, n.worker
+		status.Configured = true
+		status.Config = &debugConfig{
+			APIKey:       redactAPIKey(config.APIKey),
+			Endpoint:     config.Endpoint,
+			Environment:  config.Environment,
+			Enabled:      config.Enabled,
+			AsyncSend:    config.AsyncSend,
+			MaxQueueSize: config.MaxQueueSize,
+			BatchSize:    config.BatchSize,
+			AppName:      config.AppName,
+			Revision:     config.Revision,
+		}
+		if worker != nil {
+			status.Stats = debugStats{
+				QueueDepth: len(worker.queue),
+				Pending:    worker.Pending(),
+				Dropped:    worker.Dropped(),
+			}
+		}
+		n.mu.RUnlock()
+	}
+
+	debugMu.Lock()
+	status.RecentNotices = append([]recentNotice{}, recentNotices...)
+	status.LastError = lastSendError
+	debugMu.Unlock()
+
+	return status
+}
+
+// redactAPIKey keeps only the last four characters of the API key.
+func redactAPIKey(key string) string {
+	if len(key) <= 4 {
+		return "[FILTERED]"
+	}
+	return "[FILTERED]" + key[len(key)-4:]
+}
@@ -0,0 +1,134 @@
+package checkend
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func getDebugStatus(t *testing.T, handler http.Handler) map[string]interface{} {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/checkend", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	var status map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatalf("Failed to decode status: %v", err)
+	}
+	return status
+}
+
+func TestDebugHandlerStatus(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{
+		APIKey:      "secret-api-key-1234",
+		Enabled:     boolPtr(true),
+		Environment: "production",
+	})
+	Notify(errors.New("first error"))
+	Notify(errors.New("second error"))
+
+	status := getDebugStatus(t, DebugHandler())
+
+	cfg := status["config"].(map[string]interface{})
+	if cfg["api_key"] != "[FILTERED]1234" {
+		t.Errorf("Expected redacted API key, got %v", cfg["api_key"])
+	}
+	if cfg["environment"] != "production" || cfg["enabled"] != true {
+		t.Errorf("Unexpected config summary %v", cfg)
+	}
+
+	stats := status["stats"].(map[string]interface{})
+	for _, key := range []string{"queue_depth", "pending"} {
+		if _, ok := stats[key].(float64); !ok {
+			t.Errorf("Expected numeric stat %s, got %v", key, stats[key])
+		}
+	}
+
+	recent := status["recent_notices"].([]interface{})
+	if len(recent) != 2 {
+		t.Fatalf("Expected 2 recent notices, got %d", len(recent))
+	}
+	if recent[1].(map[string]interface{})["message"] != "second error" {
+		t.Errorf("Expected most recent notice last, got %v", recent[1])
+	}
+	if _, ok := status["last_error"]; !ok {
+		t.Error("Expected last_error field")
+	}
+}
+
+func TestDebugHandlerToggleEnabled(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{APIKey: "test-key", Enabled: boolPtr(true)})
+	handler := DebugHandler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/debug/checkend", strings.NewReader(`{"enabled": false}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	Notify(errors.New("muted"))
+	if TestingHasNotices() {
+		t.Error("Expected no notices while muted")
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/debug/checkend", strings.NewReader(`{"enabled": true}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	Notify(errors.New("unmuted"))
+	if TestingNoticeCount() != 1 {
+		t.Errorf("Expected 1 notice after re-enabling, got %d", TestingNoticeCount())
+	}
+}
+
+func TestDebugHandlerRejectsInvalidRequests(t *testing.T) {
+	defer Reset()
+
+	Configure(Config{APIKey: "test-key", Enabled: boolPtr(true)})
+	handler := DebugHandler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/debug/checkend", strings.NewReader(`{}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/debug/checkend", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405, got %d", rec.Code)
+	}
+}
+
+func TestDebugHandlerRecordsSendErrors(t *testing.T) {
+	defer Reset()
+
+	transport := NewTestTransport()
+	transport.RespondWith(500, `{"error": "boom"}`)
+	Configure(Config{APIKey: "test-key", Enabled: boolPtr(true), Transport: transport})
+
+	NotifySync(errors.New("test error"))
+
+	status := getDebugStatus(t, DebugHandler())
+	lastError, ok := status["last_error"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected last_error to be set, got %v", status["last_error"])
+	}
+	if !strings.Contains(lastError["message"].(string), "500") {
+		t.Errorf("Expected status in last error, got %v", lastError["message"])
+	}
+}
@@ -0,0 +1,83 @@
+package checkend
+
+import "sync"
+
+// duplicateStreak tracks the fingerprint of the last notice sent, holding
+// back consecutive duplicates of it. A nil streak lets every notice through.
+type duplicateStreak struct {
+	mu          sync.Mutex
+	fingerprint string
+
+	// latest is the most recent duplicate held back, and occurrences the
+	// number of occurrences held back since the last notice sent.
+	latest      *Notice
+	occurrences int
+}
+
+func newDuplicateStreak(enabled bool) *duplicateStreak {
+	if !enabled {
+		return nil
+	}
+	return &duplicateStreak{}
+}
+
+// allow reports whether notice should be sent. When notice ends a streak
+// of duplicates, it also returns their summary, as flush does.
+func (s *duplicateStreak) allow(notice *Notice) (bool, *Notice) {
+	if s == nil {
+		return true, nil
+	}
+
+	fingerprint := notice.Fingerprint
+	if fingerprint == "" {
+		fingerprint = DefaultFingerprint(notice)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if fingerprint == s.fingerprint {
+		s.latest = notice
+		s.occurrences += noticeOccurrences(notice)
+		return false, nil
+	}
+	summary := s.summary()
+	s.fingerprint = fingerprint
+	return true, summary
+}
+
+// flush returns the summary of the duplicates held back since the last
+// notice sent, or nil if there are none. The streak itself continues, so
+// later duplicates are still held back.
+func (s *duplicateStreak) flush() *Notice {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.summary()
+}
+
+// summary turns the latest duplicate held back into a notice for all of
+// them, with Occurrences set to their total, and starts a new count. It
+// must be called with s.mu held.
+func (s *duplicateStreak) summary() *Notice {
+	notice := s.latest
+	if notice == nil {
+		return nil
+	}
+	notice.Occurrences = s.occurrences
+	notice.delivery = deliveryDefault
+
+	s.latest, s.occurrences = nil, 0
+	return notice
+}
+
+// noticeOccurrences returns the number of occurrences a notice stands for.
+func noticeOccurrences(notice *Notice) int {
+	if notice.Occurrences > 1 {
+		return notice.Occurrences
+	}
+	return 1
+}
@@ -0,0 +1,115 @@
+package checkend
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSuppressConsecutiveDuplicates(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{
+		APIKey:                        "test-key",
+		Enabled:                       boolPtr(true),
+		SuppressConsecutiveDuplicates: true,
+	})
+
+	for _, message := range []string{"a failed", "a failed", "a failed", "b failed", "a failed"} {
+		Notify(errors.New(message))
+	}
+
+	notices := TestingNotices()
+	if len(notices) != 4 {
+		t.Fatalf("Expected 4 notices, got %d", len(notices))
+	}
+	for i, want := range []string{"a failed", "a failed", "b failed", "a failed"} {
+		if notices[i].Message != want {
+			t.Errorf("Notice %d: expected %q, got %q", i, want, notices[i].Message)
+		}
+	}
+
+	if notices[0].Occurrences != 0 {
+		t.Errorf("Expected no occurrence count on the first notice, got %d", notices[0].Occurrences)
+	}
+	if notices[1].Occurrences != 2 {
+		t.Errorf("Expected the held back duplicates to be reported as 2 occurrences, got %d", notices[1].Occurrences)
+	}
+	if DefaultFingerprint(notices[1]) != DefaultFingerprint(notices[0]) {
+		t.Error("Expected the held back duplicates to be reported as the duplicated notice")
+	}
+	if notices[2].Occurrences != 0 || notices[3].Occurrences != 0 {
+		t.Error("Expected no occurrence count on notices that were sent as reported")
+	}
+}
+
+func TestSuppressConsecutiveDuplicatesFlushesStreak(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{
+		APIKey:                        "test-key",
+		Enabled:                       boolPtr(true),
+		SuppressConsecutiveDuplicates: true,
+	})
+
+	Notify(errors.New("a failed"))
+	Notify(errors.New("a failed"))
+	Notify(errors.New("a failed"), WithOccurrences(3))
+	Flush()
+
+	notices := TestingNotices()
+	if len(notices) != 2 {
+		t.Fatalf("Expected Flush to send the held back duplicates, got %d notices", len(notices))
+	}
+	if notices[1].Occurrences != 4 {
+		t.Errorf("Expected 4 occurrences, got %d", notices[1].Occurrences)
+	}
+
+	// The streak continues after a flush
+	Notify(errors.New("a failed"))
+	if TestingNoticeCount() != 2 {
+		t.Fatalf("Expected the duplicate to be held back after Flush, got %d notices", TestingNoticeCount())
+	}
+	Stop()
+
+	if TestingNoticeCount() != 3 {
+		t.Fatalf("Expected Stop to send the held back duplicate, got %d notices", TestingNoticeCount())
+	}
+	if occurrences := TestingLastNotice().Occurrences; occurrences != 1 {
+		t.Errorf("Expected 1 occurrence, got %d", occurrences)
+	}
+}
+
+func TestSuppressConsecutiveDuplicatesUsesFingerprint(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{
+		APIKey:                        "test-key",
+		Enabled:                       boolPtr(true),
+		SuppressConsecutiveDuplicates: true,
+	})
+
+	Notify(errors.New("first"), WithFingerprint("same"))
+	Notify(errors.New("second"), WithFingerprint("same"))
+
+	if TestingNoticeCount() != 1 {
+		t.Errorf("Expected notices with the same fingerprint to be collapsed, got %d", TestingNoticeCount())
+	}
+}
+
+func TestConsecutiveDuplicatesSentByDefault(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{APIKey: "test-key", Enabled: boolPtr(true)})
+
+	for i := 0; i < 3; i++ {
+		Notify(errors.New("a failed"))
+	}
+
+	if TestingNoticeCount() != 3 {
+		t.Errorf("Expected every notice to be sent, got %d", TestingNoticeCount())
+	}
+}
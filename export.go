@@ -0,0 +1,49 @@
+package checkend
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// ExportNotices writes notices as a JSON array to w, for saving a repro from
+// a failing test or a debug endpoint. In testing mode it writes the captured
+// notices; otherwise it writes the most recent notices reported, up to 20.
+// Notices are written in full, in the order they were reported.
+func ExportNotices(w io.Writer) error {
+	notices := exportedNotices()
+	if notices == nil {
+		notices = []*Notice{}
+	}
+	return json.NewEncoder(w).Encode(notices)
+}
+
+// ExportNoticesNDJSON is like ExportNotices, but writes one JSON notice per
+// line.
+func ExportNoticesNDJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for _, notice := range exportedNotices() {
+		if err := enc.Encode(notice); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportedNotices returns the captured notices in testing mode, or else the
+// recent notices.
+func exportedNotices() []*Notice {
+	testingMu.Lock()
+	enabled := testingEnabled
+	testingMu.Unlock()
+	if enabled {
+		return TestingNotices()
+	}
+
+	debugMu.Lock()
+	defer debugMu.Unlock()
+	notices := make([]*Notice, 0, len(recentNotices))
+	for _, recent := range recentNotices {
+		notices = append(notices, recent.notice)
+	}
+	return notices
+}
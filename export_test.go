@@ -0,0 +1,88 @@
+package checkend
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestExportNoticesRoundTrips(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{APIKey: "test-key", Enabled: boolPtr(true)})
+
+	Notify(errors.New("first"), WithTags("checkout"), WithContext(map[string]interface{}{"order_id": "o-1"}))
+	NotifyMessage("second", "warning",
+		WithUser(map[string]interface{}{"id": "u-1"}),
+		WithContext(map[string]interface{}{"job": "sync"}))
+
+	var buf bytes.Buffer
+	if err := ExportNotices(&buf); err != nil {
+		t.Fatalf("ExportNotices failed: %v", err)
+	}
+
+	var exported []*Notice
+	if err := json.Unmarshal(buf.Bytes(), &exported); err != nil {
+		t.Fatalf("Expected a JSON array, got %s: %v", buf.String(), err)
+	}
+
+	captured := TestingNotices()
+	if len(exported) != len(captured) {
+		t.Fatalf("Expected %d notices, got %d", len(captured), len(exported))
+	}
+	matcher := NewNoticeMatcher().Include("backtrace", "frames", "hostname")
+	for i := range captured {
+		if diff := matcher.Diff(captured[i], exported[i]); len(diff) > 0 {
+			t.Errorf("Notice %d differs after export in %v", i, diff)
+		}
+		if !captured[i].OccurredAt.Equal(exported[i].OccurredAt) {
+			t.Errorf("Notice %d: expected occurred_at %v, got %v", i, captured[i].OccurredAt, exported[i].OccurredAt)
+		}
+	}
+}
+
+func TestExportNoticesNDJSONWritesRecentNotices(t *testing.T) {
+	defer Reset()
+
+	transport := NewTestTransport()
+	Configure(Config{APIKey: "test-key", Enabled: boolPtr(true), Transport: transport})
+
+	Notify(errors.New("first"))
+	Notify(errors.New("second"))
+	Flush()
+
+	var buf bytes.Buffer
+	if err := ExportNoticesNDJSON(&buf); err != nil {
+		t.Fatalf("ExportNoticesNDJSON failed: %v", err)
+	}
+
+	var messages []string
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var notice Notice
+		if err := json.Unmarshal(scanner.Bytes(), &notice); err != nil {
+			t.Fatalf("Expected a JSON notice per line, got %q: %v", scanner.Text(), err)
+		}
+		messages = append(messages, notice.Message)
+	}
+	if len(messages) != 2 || messages[0] != "first" || messages[1] != "second" {
+		t.Errorf("Expected the recent notices in order, got %v", messages)
+	}
+}
+
+func TestExportNoticesEmpty(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+
+	var buf bytes.Buffer
+	if err := ExportNotices(&buf); err != nil {
+		t.Fatalf("ExportNotices failed: %v", err)
+	}
+	if got := bytes.TrimSpace(buf.Bytes()); string(got) != "[]" {
+		t.Errorf("Expected an empty array, got %s", got)
+	}
+}
@@ -1,19 +1,105 @@
 package filters
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
 	"regexp"
 	"strings"
 )
 
+// messagePrefix marks a string pattern that matches the error message
+// instead of the error type name.
+const messagePrefix = "msg:"
+
+// IgnoreRule ignores errors by type name and/or message. Type is matched
+// like a string pattern (exactly, by suffix, or as a regular expression)
+// and Message is a regular expression matched against the error message.
+// Empty fields match any error; both must match when both are set.
+type IgnoreRule struct {
+	Type    string
+	Message string
+}
+
+// genericErrorTypes are the types of errors created by errors.New and
+// fmt.Errorf. Instances of these types only match with errors.Is, since
+// matching by type would ignore almost every error.
+var genericErrorTypes = map[reflect.Type]bool{
+	reflect.TypeOf(errors.New("")):                                      true,
+	reflect.TypeOf(fmt.Errorf("%w", errors.New(""))):                    true,
+	reflect.TypeOf(errors.Join(errors.New(""))):                         true,
+	reflect.TypeOf(fmt.Errorf("%w %w", errors.New(""), errors.New(""))): true,
+}
+
 // IgnoreFilter determines if an error should be ignored.
 type IgnoreFilter struct {
+	// MatchMessage makes *regexp.Regexp patterns also match against the
+	// error message, not just the error type name.
+	MatchMessage bool
+
 	patterns []interface{}
+	compiled []compiledPattern
 }
 
-// NewIgnoreFilter creates a new IgnoreFilter.
+// compiledPattern holds the regular expressions compiled from a pattern.
+type compiledPattern struct {
+	typeRe    *regexp.Regexp
+	messageRe *regexp.Regexp
+}
+
+// NewIgnoreFilter creates a new IgnoreFilter. Patterns may be:
+//   - type name strings, matched exactly, by suffix, or as a regular expression
+//   - strings prefixed with "msg:", matched as a regular expression against
+//     the error message
+//   - *regexp.Regexp values, matched against the type name
+//   - IgnoreRule values
+//   - reflect.Type values
+//   - error instances, matched with errors.Is; instances of custom error
+//     types also match any error of the same type
 func NewIgnoreFilter(patterns []interface{}) *IgnoreFilter {
-	return &IgnoreFilter{patterns: patterns}
+	f := &IgnoreFilter{
+		patterns: patterns,
+		compiled: make([]compiledPattern, len(patterns)),
+	}
+
+	// Compile string patterns once rather than on every error
+	for i, pattern := range patterns {
+		switch p := pattern.(type) {
+		case string:
+			if strings.HasPrefix(p, messagePrefix) {
+				f.compiled[i].messageRe = compileOrNil(strings.TrimPrefix(p, messagePrefix))
+			} else {
+				f.compiled[i].typeRe = compileOrNil(p)
+			}
+		case IgnoreRule:
+			f.compiled[i] = compileRule(p)
+		case *IgnoreRule:
+			if p != nil {
+				f.compiled[i] = compileRule(*p)
+			}
+		}
+	}
+
+	return f
+}
+
+func compileRule(rule IgnoreRule) compiledPattern {
+	var c compiledPattern
+	if rule.Type != "" {
+		c.typeRe = compileOrNil(rule.Type)
+	}
+	if rule.Message != "" {
+		c.messageRe = compileOrNil(rule.Message)
+	}
+	return c
+}
+
+func compileOrNil(pattern string) *regexp.Regexp {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil
+	}
+	return re
 }
 
 // ShouldIgnore returns true if the error should be ignored.
@@ -28,21 +114,42 @@ func (f *IgnoreFilter) ShouldIgnore(err error) bool {
 	// Remove pointer prefix
 	errName = strings.TrimPrefix(errName, "*")
 
-	for _, pattern := range f.patterns {
+	for i, pattern := range f.patterns {
 		switch p := pattern.(type) {
 		case string:
-			// String matching
-			if f.matchesString(errName, p) {
+			if strings.HasPrefix(p, messagePrefix) {
+				// Message matching
+				if f.matchesMessage(err, strings.TrimPrefix(p, messagePrefix), f.compiled[i].messageRe) {
+					return true
+				}
+			} else if f.matchesString(errName, p, f.compiled[i].typeRe) {
+				// String matching
+				return true
+			}
+		case IgnoreRule:
+			if f.matchesRule(err, errName, p, f.compiled[i]) {
+				return true
+			}
+		case *IgnoreRule:
+			if p != nil && f.matchesRule(err, errName, *p, f.compiled[i]) {
+				return true
+			}
+		case *regexp.Regexp:
+			// Precompiled regex matching
+			if p.MatchString(errName) || (f.MatchMessage && p.MatchString(err.Error())) {
 				return true
 			}
 		case reflect.Type:
-			// Type matching
-			if errType == p || errType.AssignableTo(p) {
+			// Type matching anywhere in the wrap chain
+			if errType == p || errType.AssignableTo(p) || asType(err, p) {
 				return true
 			}
 		case error:
-			// Error instance matching (compare types)
-			if errType == reflect.TypeOf(p) {
+			// Sentinel matching, or type matching for custom error types
+			if errors.Is(err, p) {
+				return true
+			}
+			if pType := reflect.TypeOf(p); !genericErrorTypes[pType] && asType(err, pType) {
 				return true
 			}
 		}
@@ -51,7 +158,39 @@ func (f *IgnoreFilter) ShouldIgnore(err error) bool {
 	return false
 }
 
-func (f *IgnoreFilter) matchesString(errName, pattern string) bool {
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// asType reports whether err or any error it wraps is assignable to t.
+func asType(err error, t reflect.Type) bool {
+	// errors.As panics on targets that can never hold an error
+	if t.Kind() != reflect.Interface && !t.Implements(errorType) {
+		return false
+	}
+	return errors.As(err, reflect.New(t).Interface())
+}
+
+func (f *IgnoreFilter) matchesRule(err error, errName string, rule IgnoreRule, c compiledPattern) bool {
+	if rule.Type == "" && rule.Message == "" {
+		return false
+	}
+	if rule.Type != "" && !f.matchesString(errName, rule.Type, c.typeRe) {
+		return false
+	}
+	if rule.Message != "" && !f.matchesMessage(err, rule.Message, c.messageRe) {
+		return false
+	}
+	return true
+}
+
+func (f *IgnoreFilter) matchesMessage(err error, pattern string, re *regexp.Regexp) bool {
+	message := err.Error()
+	if message == pattern {
+		return true
+	}
+	return re != nil && re.MatchString(message)
+}
+
+func (f *IgnoreFilter) matchesString(errName, pattern string, re *regexp.Regexp) bool {
 	// Exact match
 	if errName == pattern {
 		return true
@@ -63,10 +202,8 @@ func (f *IgnoreFilter) matchesString(errName, pattern string) bool {
 	}
 
 	// Regex match
-	if re, err := regexp.Compile(pattern); err == nil {
-		if re.MatchString(errName) {
-			return true
-		}
+	if re != nil && re.MatchString(errName) {
+		return true
 	}
 
 	return false
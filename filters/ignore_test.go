@@ -2,6 +2,9 @@ package filters
 
 import (
 	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
 	"testing"
 )
 
@@ -96,3 +99,151 @@ func TestIgnoreFilterNilError(t *testing.T) {
 		t.Error("Expected nil error to be ignored")
 	}
 }
+
+func TestIgnoreFilterByCompiledRegex(t *testing.T) {
+	filter := NewIgnoreFilter([]interface{}{regexp.MustCompile(`^filters\.custom`)})
+
+	if !filter.ShouldIgnore(&customError{message: "test"}) {
+		t.Error("Expected customError to be ignored")
+	}
+	if filter.ShouldIgnore(&anotherError{message: "test"}) {
+		t.Error("Expected anotherError not to be ignored")
+	}
+}
+
+func TestIgnoreFilterCompiledRegexMatchMessage(t *testing.T) {
+	pattern := regexp.MustCompile(`connection reset`)
+
+	filter := NewIgnoreFilter([]interface{}{pattern})
+	if filter.ShouldIgnore(errors.New("read: connection reset by peer")) {
+		t.Error("Expected message not to be matched by default")
+	}
+
+	filter.MatchMessage = true
+	if !filter.ShouldIgnore(errors.New("read: connection reset by peer")) {
+		t.Error("Expected message to be matched with MatchMessage")
+	}
+	if filter.ShouldIgnore(errors.New("permission denied")) {
+		t.Error("Expected unrelated message not to be ignored")
+	}
+}
+
+func TestIgnoreFilterInvalidStringPattern(t *testing.T) {
+	filter := NewIgnoreFilter([]interface{}{"[invalid"})
+
+	if filter.ShouldIgnore(&customError{message: "test"}) {
+		t.Error("Expected invalid pattern not to match")
+	}
+}
+
+func TestIgnoreFilterByMessagePrefix(t *testing.T) {
+	filter := NewIgnoreFilter([]interface{}{"msg:^context canceled$"})
+
+	if !filter.ShouldIgnore(errors.New("context canceled")) {
+		t.Error("Expected matching message to be ignored")
+	}
+	if filter.ShouldIgnore(errors.New("permission denied")) {
+		t.Error("Expected other message not to be ignored")
+	}
+}
+
+func TestIgnoreFilterByRule(t *testing.T) {
+	filter := NewIgnoreFilter([]interface{}{
+		IgnoreRule{Type: "customError", Message: "temporary"},
+		&IgnoreRule{Message: "^EOF$"},
+	})
+
+	if !filter.ShouldIgnore(&customError{message: "temporary failure"}) {
+		t.Error("Expected error matching type and message to be ignored")
+	}
+	if filter.ShouldIgnore(&customError{message: "permanent failure"}) {
+		t.Error("Expected error matching only the type not to be ignored")
+	}
+	if filter.ShouldIgnore(&anotherError{message: "temporary failure"}) {
+		t.Error("Expected error matching only the message not to be ignored")
+	}
+	if !filter.ShouldIgnore(errors.New("EOF")) {
+		t.Error("Expected message-only rule to match any type")
+	}
+}
+
+func TestIgnoreFilterEmptyRule(t *testing.T) {
+	filter := NewIgnoreFilter([]interface{}{IgnoreRule{}})
+
+	if filter.ShouldIgnore(errors.New("test")) {
+		t.Error("Expected empty rule not to match")
+	}
+}
+
+func TestIgnoreFilterBySentinel(t *testing.T) {
+	sentinel := errors.New("not found")
+	filter := NewIgnoreFilter([]interface{}{sentinel})
+
+	if !filter.ShouldIgnore(sentinel) {
+		t.Error("Expected sentinel to be ignored")
+	}
+	if filter.ShouldIgnore(errors.New("not found")) {
+		t.Error("Expected a different error with the same type not to be ignored")
+	}
+}
+
+func TestIgnoreFilterWrappedSentinel(t *testing.T) {
+	sentinel := errors.New("no rows in result set")
+	filter := NewIgnoreFilter([]interface{}{sentinel})
+
+	if !filter.ShouldIgnore(fmt.Errorf("query failed: %w", sentinel)) {
+		t.Error("Expected wrapped sentinel to be ignored")
+	}
+	if !filter.ShouldIgnore(fmt.Errorf("outer: %w", fmt.Errorf("inner: %w", sentinel))) {
+		t.Error("Expected doubly wrapped sentinel to be ignored")
+	}
+	if filter.ShouldIgnore(fmt.Errorf("query failed: %v", sentinel)) {
+		t.Error("Expected formatted but unwrapped sentinel not to be ignored")
+	}
+}
+
+func TestIgnoreFilterWrappedCustomType(t *testing.T) {
+	wrapped := fmt.Errorf("handler: %w", &customError{message: "test"})
+
+	byType := NewIgnoreFilter([]interface{}{reflect.TypeOf(&customError{})})
+	if !byType.ShouldIgnore(wrapped) {
+		t.Error("Expected wrapped custom type to be ignored by reflect.Type")
+	}
+
+	byInstance := NewIgnoreFilter([]interface{}{&customError{}})
+	if !byInstance.ShouldIgnore(wrapped) {
+		t.Error("Expected wrapped custom type to be ignored by instance")
+	}
+
+	if byType.ShouldIgnore(fmt.Errorf("handler: %w", &anotherError{message: "test"})) {
+		t.Error("Expected wrapped unrelated type not to be ignored")
+	}
+}
+
+func TestIgnoreFilterWrappedInterfaceType(t *testing.T) {
+	type temporary interface {
+		error
+		Temporary() bool
+	}
+	filter := NewIgnoreFilter([]interface{}{reflect.TypeOf((*temporary)(nil)).Elem()})
+
+	if !filter.ShouldIgnore(fmt.Errorf("dial: %w", &temporaryError{})) {
+		t.Error("Expected wrapped error implementing the interface to be ignored")
+	}
+	if filter.ShouldIgnore(fmt.Errorf("dial: %w", &customError{})) {
+		t.Error("Expected wrapped error not implementing the interface not to be ignored")
+	}
+}
+
+func TestIgnoreFilterNonErrorType(t *testing.T) {
+	filter := NewIgnoreFilter([]interface{}{reflect.TypeOf("")})
+
+	if filter.ShouldIgnore(fmt.Errorf("wrapped: %w", &customError{})) {
+		t.Error("Expected non-error type not to match")
+	}
+}
+
+type temporaryError struct{}
+
+func (e *temporaryError) Error() string   { return "temporary" }
+func (e *temporaryError) Temporary() bool { return true }
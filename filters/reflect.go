@@ -0,0 +1,138 @@
+package filters
+
+import (
+	"encoding"
+	"reflect"
+	"strings"
+)
+
+// filterReflected filters values of types other than the generic map and
+// slice types. Structs and maps with string keys are converted to maps, and
+// slices and arrays to slices, so key filtering applies to their contents.
+// It reports false for values it can't represent.
+func (f *SanitizeFilter) filterReflected(value interface{}, depth int) (interface{}, bool) {
+	// Types with a text form, such as time.Time, are reported as text
+	if tm, ok := value.(encoding.TextMarshaler); ok {
+		if text, err := tm.MarshalText(); err == nil {
+			return f.truncateString(f.redactValue(string(text))), true
+		}
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, true
+		}
+		if !f.enter(value) {
+			return CircularValue, true
+		}
+		defer f.leave(value)
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return f.filterMap(structToMap(v), depth), true
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[iter.Key().String()] = iter.Value().Interface()
+		}
+		return f.filterMap(m, depth), true
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return nil, false
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = v.Index(i).Interface()
+		}
+		return f.filterSlice(items, depth), true
+	}
+
+	if stringer, ok := value.(interface{ String() string }); ok {
+		return f.truncateString(f.redactValue(stringer.String())), true
+	}
+
+	// Named scalar types, e.g. type Role string
+	switch v.Kind() {
+	case reflect.String:
+		return f.truncateString(f.redactValue(v.String())), true
+	case reflect.Bool:
+		return v.Bool(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint(), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+
+	return nil, false
+}
+
+// structToMap converts a struct to a map keyed by JSON field names.
+// Unexported fields and fields tagged `json:"-"` are skipped, and embedded
+// structs are flattened as encoding/json does.
+func structToMap(v reflect.Value) map[string]interface{} {
+	result := make(map[string]interface{})
+	addStructFields(result, v)
+	return result
+}
+
+func addStructFields(result map[string]interface{}, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omitEmpty, skip := jsonFieldName(field)
+		if skip {
+			continue
+		}
+
+		fv := v.Field(i)
+
+		// Flatten embedded structs without an explicit name
+		if field.Anonymous && name == "" {
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				addStructFields(result, fv)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if omitEmpty && fv.IsZero() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		result[name] = fv.Interface()
+	}
+}
+
+// jsonFieldName parses a field's json tag.
+func jsonFieldName(field reflect.StructField) (name string, omitEmpty, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	parts := strings.Split(tag, ",")
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return parts[0], omitEmpty, false
+}
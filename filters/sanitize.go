@@ -1,18 +1,50 @@
 package filters
 
 import (
+	"encoding"
+	"reflect"
+	"regexp"
 	"strings"
 )
 
 const (
 	// FilteredValue is the replacement for sensitive values.
 	FilteredValue = "[FILTERED]"
-	maxDepth      = 10
-	maxStringLen  = 10000
+
+	// CircularValue replaces a map or slice that contains itself.
+	CircularValue = "[CIRCULAR]"
+
+	// ExactKeyPrefix marks a filter key that matches only the whole key name.
+	ExactKeyPrefix = "="
+
+	maxDepth     = 10
+	maxStringLen = 10000
 )
 
 // SanitizeFilter removes sensitive data from payloads.
 type SanitizeFilter struct {
+	// KeyPatterns filter values whose key matches any of the patterns.
+	KeyPatterns []*regexp.Regexp
+
+	// ValuePatterns redact matching parts of string values, leaving the
+	// rest of the string intact.
+	ValuePatterns []*regexp.Regexp
+
+	// AllowKeys switches the filter to allowlist mode when non-empty: only
+	// values under these keys (case-insensitive) are kept, and all other
+	// values are filtered. Nested maps are still descended into, and filter
+	// keys still apply to allowed keys.
+	AllowKeys []string
+
+	// Placeholder replaces filtered values. Defaults to FilteredValue.
+	Placeholder string
+
+	// ExactKeys makes filter keys match whole key names (case-insensitive)
+	// instead of substrings, so "key" no longer filters "public_key_id".
+	// Individual keys can opt into exact matching with an "=" prefix,
+	// e.g. "=key".
+	ExactKeys bool
+
 	filterKeys []string
 	seen       map[uintptr]bool
 }
@@ -34,9 +66,48 @@ func (f *SanitizeFilter) Filter(data map[string]interface{}) map[string]interfac
 		return nil
 	}
 	f.seen = make(map[uintptr]bool)
+	f.enter(data)
+	defer f.leave(data)
 	return f.filterMap(data, 0)
 }
 
+// enter marks a map or slice as being filtered, reporting false if it is
+// already on the current path, i.e. it contains itself.
+func (f *SanitizeFilter) enter(container interface{}) bool {
+	ptr, ok := containerPointer(container)
+	if !ok {
+		return true
+	}
+	if f.seen[ptr] {
+		return false
+	}
+	f.seen[ptr] = true
+	return true
+}
+
+// leave unmarks a map or slice once it has been filtered, so containers
+// shared by several branches aren't mistaken for cycles.
+func (f *SanitizeFilter) leave(container interface{}) {
+	if ptr, ok := containerPointer(container); ok {
+		delete(f.seen, ptr)
+	}
+}
+
+// containerPointer returns the identity of a map, pointer, or non-empty slice.
+func containerPointer(container interface{}) (uintptr, bool) {
+	v := reflect.ValueOf(container)
+	switch v.Kind() {
+	case reflect.Map:
+		return v.Pointer(), !v.IsNil()
+	case reflect.Slice:
+		return v.Pointer(), v.Len() > 0
+	case reflect.Ptr:
+		return v.Pointer(), !v.IsNil()
+	default:
+		return 0, false
+	}
+}
+
 func (f *SanitizeFilter) filterMap(data map[string]interface{}, depth int) map[string]interface{} {
 	if depth > maxDepth {
 		return map[string]interface{}{"_truncated": "[MAX DEPTH EXCEEDED]"}
@@ -44,8 +115,8 @@ func (f *SanitizeFilter) filterMap(data map[string]interface{}, depth int) map[s
 
 	result := make(map[string]interface{})
 	for key, value := range data {
-		if f.shouldFilter(key) {
-			result[key] = FilteredValue
+		if f.shouldFilter(key) || (!f.isAllowed(key) && !isObject(value)) {
+			result[key] = f.placeholder()
 		} else {
 			result[key] = f.filterValue(value, depth+1)
 		}
@@ -60,18 +131,29 @@ func (f *SanitizeFilter) filterValue(value interface{}, depth int) interface{} {
 
 	switch v := value.(type) {
 	case map[string]interface{}:
+		if !f.enter(v) {
+			return CircularValue
+		}
+		defer f.leave(v)
 		return f.filterMap(v, depth)
 	case []interface{}:
+		if !f.enter(v) {
+			return CircularValue
+		}
+		defer f.leave(v)
 		return f.filterSlice(v, depth)
 	case string:
-		return f.truncateString(v)
+		return f.truncateString(f.redactValue(v))
 	case nil, bool, int, int8, int16, int32, int64,
 		uint, uint8, uint16, uint32, uint64,
 		float32, float64:
 		return v
 	default:
+		if filtered, ok := f.filterReflected(v, depth); ok {
+			return filtered
+		}
 		// Convert to string for unknown types
-		return f.truncateString(valueToString(v))
+		return f.truncateString(f.redactValue(valueToString(v)))
 	}
 }
 
@@ -83,16 +165,74 @@ func (f *SanitizeFilter) filterSlice(data []interface{}, depth int) []interface{
 	return result
 }
 
+// MatchesKey reports whether values under key are filtered by the filter
+// keys or key patterns.
+func (f *SanitizeFilter) MatchesKey(key string) bool {
+	return f.shouldFilter(key)
+}
+
 func (f *SanitizeFilter) shouldFilter(key string) bool {
 	keyLower := strings.ToLower(key)
 	for _, filterKey := range f.filterKeys {
-		if strings.Contains(keyLower, filterKey) {
+		if exact := strings.TrimPrefix(filterKey, ExactKeyPrefix); exact != filterKey || f.ExactKeys {
+			if keyLower == exact {
+				return true
+			}
+		} else if strings.Contains(keyLower, filterKey) {
+			return true
+		}
+	}
+	for _, re := range f.KeyPatterns {
+		if re.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAllowed reports whether a key may keep its value in allowlist mode.
+func (f *SanitizeFilter) isAllowed(key string) bool {
+	if len(f.AllowKeys) == 0 {
+		return true
+	}
+	for _, allowed := range f.AllowKeys {
+		if strings.EqualFold(key, allowed) {
 			return true
 		}
 	}
 	return false
 }
 
+// redactValue replaces parts of a string that match a value pattern.
+func (f *SanitizeFilter) redactValue(s string) string {
+	for _, re := range f.ValuePatterns {
+		s = re.ReplaceAllLiteralString(s, f.placeholder())
+	}
+	return s
+}
+
+func (f *SanitizeFilter) placeholder() string {
+	if f.Placeholder != "" {
+		return f.Placeholder
+	}
+	return FilteredValue
+}
+
+// isObject reports whether a value is converted to a map when filtered.
+func isObject(value interface{}) bool {
+	if _, ok := value.(map[string]interface{}); ok {
+		return true
+	}
+	if _, ok := value.(encoding.TextMarshaler); ok {
+		return false
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	return v.Kind() == reflect.Struct || (v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String)
+}
+
 func (f *SanitizeFilter) truncateString(s string) string {
 	if len(s) > maxStringLen {
 		return s[:maxStringLen] + "..."
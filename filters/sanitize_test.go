@@ -1,7 +1,9 @@
 package filters
 
 import (
+	"regexp"
 	"testing"
+	"time"
 )
 
 func TestSanitizeFilterSimple(t *testing.T) {
@@ -194,3 +196,275 @@ func TestSanitizeFilterHandlesDeepNesting(t *testing.T) {
 		t.Errorf("Expected level 0, got %v", result["level"])
 	}
 }
+
+func TestSanitizeFilterKeyPatterns(t *testing.T) {
+	filter := NewSanitizeFilter([]string{})
+	filter.KeyPatterns = []*regexp.Regexp{regexp.MustCompile(`^x-.*-key$`)}
+
+	result := filter.Filter(map[string]interface{}{
+		"x-internal-key": "abc",
+		"x-request-id":   "123",
+	})
+
+	if result["x-internal-key"] != FilteredValue {
+		t.Errorf("Expected x-internal-key to be filtered, got '%v'", result["x-internal-key"])
+	}
+	if result["x-request-id"] != "123" {
+		t.Errorf("Expected x-request-id to be kept, got '%v'", result["x-request-id"])
+	}
+}
+
+func TestSanitizeFilterValuePatterns(t *testing.T) {
+	filter := NewSanitizeFilter([]string{})
+	filter.ValuePatterns = []*regexp.Regexp{regexp.MustCompile(`\d{4}-\d{4}-\d{4}-\d{4}`)}
+
+	result := filter.Filter(map[string]interface{}{
+		"message": "charge failed for card 4111-1111-1111-1111",
+	})
+
+	if result["message"] != "charge failed for card [FILTERED]" {
+		t.Errorf("Expected card number to be redacted inline, got '%v'", result["message"])
+	}
+}
+
+func TestSanitizeFilterAllowKeys(t *testing.T) {
+	filter := NewSanitizeFilter([]string{"password"})
+	filter.AllowKeys = []string{"id", "Status", "password"}
+
+	result := filter.Filter(map[string]interface{}{
+		"id":       42,
+		"status":   "active",
+		"email":    "user@example.com",
+		"password": "secret",
+		"tags":     []interface{}{"a", "b"},
+		"order": map[string]interface{}{
+			"id":    7,
+			"total": 99.5,
+		},
+	})
+
+	if result["id"] != 42 || result["status"] != "active" {
+		t.Errorf("Expected allowed keys to be kept, got %v", result)
+	}
+	if result["email"] != FilteredValue || result["tags"] != FilteredValue {
+		t.Errorf("Expected other keys to be filtered, got %v", result)
+	}
+	if result["password"] != FilteredValue {
+		t.Error("Expected filter keys to apply to allowed keys")
+	}
+
+	order := result["order"].(map[string]interface{})
+	if order["id"] != 7 || order["total"] != FilteredValue {
+		t.Errorf("Expected nested map to be filtered by allowlist, got %v", order)
+	}
+}
+
+func TestSanitizeFilterPlaceholder(t *testing.T) {
+	filter := NewSanitizeFilter([]string{"password"})
+	filter.Placeholder = "***"
+
+	result := filter.Filter(map[string]interface{}{"password": "secret"})
+
+	if result["password"] != "***" {
+		t.Errorf("Expected custom placeholder, got '%v'", result["password"])
+	}
+}
+
+func TestSanitizeFilterExactKeys(t *testing.T) {
+	filter := NewSanitizeFilter([]string{"key", "token"})
+	filter.ExactKeys = true
+
+	result := filter.Filter(map[string]interface{}{
+		"Key":             "secret",
+		"public_key_id":   "pk_1",
+		"idempotency_key": "abc",
+		"monkey":          "banana",
+		"token":           "t",
+	})
+
+	if result["Key"] != FilteredValue || result["token"] != FilteredValue {
+		t.Errorf("Expected exact keys to be filtered, got %v", result)
+	}
+	for _, key := range []string{"public_key_id", "idempotency_key", "monkey"} {
+		if result[key] == FilteredValue {
+			t.Errorf("Expected %s not to be filtered", key)
+		}
+	}
+}
+
+func TestSanitizeFilterExactKeyPrefix(t *testing.T) {
+	filter := NewSanitizeFilter([]string{"=key", "token"})
+
+	result := filter.Filter(map[string]interface{}{
+		"key":        "secret",
+		"monkey":     "banana",
+		"auth_token": "t",
+	})
+
+	if result["key"] != FilteredValue {
+		t.Error("Expected exact key to be filtered")
+	}
+	if result["monkey"] != "banana" {
+		t.Error("Expected exact key not to match substrings")
+	}
+	if result["auth_token"] != FilteredValue {
+		t.Error("Expected other keys to keep substring matching")
+	}
+}
+
+func TestSanitizeFilterMatchesKey(t *testing.T) {
+	filter := NewSanitizeFilter([]string{"=key", "token"})
+
+	if !filter.MatchesKey("KEY") || !filter.MatchesKey("Auth_Token") {
+		t.Error("Expected filter keys to match case-insensitively")
+	}
+	if filter.MatchesKey("monkey") {
+		t.Error("Expected exact key not to match substrings")
+	}
+}
+
+func TestSanitizeFilterDetectsCycles(t *testing.T) {
+	filter := NewSanitizeFilter([]string{})
+
+	data := map[string]interface{}{"name": "root"}
+	child := map[string]interface{}{"parent": data}
+	data["child"] = child
+	data["self"] = data
+
+	list := []interface{}{"item", nil}
+	list[1] = list
+	data["list"] = list
+
+	result := filter.Filter(data)
+
+	if result["self"] != CircularValue {
+		t.Errorf("Expected self reference to be marked circular, got %v", result["self"])
+	}
+	if parent := result["child"].(map[string]interface{})["parent"]; parent != CircularValue {
+		t.Errorf("Expected back reference to be marked circular, got %v", parent)
+	}
+	if inner := result["list"].([]interface{})[1]; inner != CircularValue {
+		t.Errorf("Expected self-containing slice to be marked circular, got %v", inner)
+	}
+}
+
+func TestSanitizeFilterSharedNodesAreNotCycles(t *testing.T) {
+	filter := NewSanitizeFilter([]string{})
+
+	shared := map[string]interface{}{"id": 1}
+	data := map[string]interface{}{
+		"a": shared,
+		"b": shared,
+		"c": []interface{}{shared, shared},
+	}
+
+	result := filter.Filter(data)
+
+	for _, key := range []string{"a", "b"} {
+		if m, ok := result[key].(map[string]interface{}); !ok || m["id"] != 1 {
+			t.Errorf("Expected shared node under %s to be kept, got %v", key, result[key])
+		}
+	}
+	for i, item := range result["c"].([]interface{}) {
+		if m, ok := item.(map[string]interface{}); !ok || m["id"] != 1 {
+			t.Errorf("Expected shared node at index %d to be kept, got %v", i, item)
+		}
+	}
+}
+
+type testAddress struct {
+	City string `json:"city"`
+}
+
+type testAudit struct {
+	CreatedBy string `json:"created_by"`
+}
+
+type testUser struct {
+	testAudit
+	ID       int               `json:"id"`
+	Name     string            `json:"name"`
+	Password string            `json:"password"`
+	Nickname string            `json:"nickname,omitempty"`
+	Internal string            `json:"-"`
+	Address  *testAddress      `json:"address"`
+	Roles    []string          `json:"roles"`
+	Labels   map[string]string `json:"labels"`
+	Joined   time.Time         `json:"joined"`
+	NoTag    bool
+	secret   string
+}
+
+func TestSanitizeFilterStructs(t *testing.T) {
+	filter := NewSanitizeFilter([]string{"password", "token"})
+
+	joined := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	result := filter.Filter(map[string]interface{}{
+		"user": &testUser{
+			testAudit: testAudit{CreatedBy: "admin"},
+			ID:        7,
+			Name:      "Jane",
+			Password:  "hunter2",
+			Internal:  "hidden",
+			Address:   &testAddress{City: "Paris"},
+			Roles:     []string{"admin"},
+			Labels:    map[string]string{"api_token": "abc", "tier": "gold"},
+			Joined:    joined,
+			NoTag:     true,
+			secret:    "s",
+		},
+	})
+
+	user, ok := result["user"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected struct to become a map, got %T", result["user"])
+	}
+
+	expected := map[string]interface{}{
+		"id":         7,
+		"name":       "Jane",
+		"password":   FilteredValue,
+		"created_by": "admin",
+		"joined":     "2024-01-02T03:04:05Z",
+		"NoTag":      true,
+	}
+	for key, want := range expected {
+		if user[key] != want {
+			t.Errorf("Expected %s to be %v, got %v", key, want, user[key])
+		}
+	}
+	for _, key := range []string{"nickname", "Internal", "secret", "-"} {
+		if _, ok := user[key]; ok {
+			t.Errorf("Expected %s to be skipped", key)
+		}
+	}
+	if city := user["address"].(map[string]interface{})["city"]; city != "Paris" {
+		t.Errorf("Expected nested struct to be converted, got %v", city)
+	}
+	if roles := user["roles"].([]interface{}); len(roles) != 1 || roles[0] != "admin" {
+		t.Errorf("Expected typed slice to be converted, got %v", roles)
+	}
+	labels := user["labels"].(map[string]interface{})
+	if labels["api_token"] != FilteredValue || labels["tier"] != "gold" {
+		t.Errorf("Expected typed map to be filtered, got %v", labels)
+	}
+}
+
+type testNode struct {
+	Name string    `json:"name"`
+	Next *testNode `json:"next"`
+}
+
+func TestSanitizeFilterStructCycles(t *testing.T) {
+	filter := NewSanitizeFilter([]string{})
+
+	node := &testNode{Name: "a"}
+	node.Next = node
+
+	result := filter.Filter(map[string]interface{}{"node": node})
+
+	converted := result["node"].(map[string]interface{})
+	if converted["next"] != CircularValue {
+		t.Errorf("Expected self-referencing struct to be marked circular, got %v", converted["next"])
+	}
+}
@@ -0,0 +1,108 @@
+package checkend
+
+import (
+	"crypto/sha1" //nolint:gosec // Used for grouping, not security
+	"encoding/hex"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// MessageNormalizer replaces the matches of Pattern in a message with
+// Replacement.
+type MessageNormalizer struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// MessageNormalizers replace the variable parts of a message, most specific
+// first so a UUID isn't partially consumed by the hex or digit patterns.
+// They are used by DefaultNormalizeMessage and DefaultFingerprint; append to
+// them during initialization to normalize application-specific tokens.
+var MessageNormalizers = []MessageNormalizer{
+	{regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`), "<uuid>"},
+	{regexp.MustCompile(`(?i)\b0x[0-9a-f]+\b`), "<hex>"},
+	{regexp.MustCompile(`(?i)\b[0-9a-f]*[0-9][0-9a-f]*[a-f][0-9a-f]*\b|\b[0-9a-f]*[a-f][0-9a-f]*[0-9][0-9a-f]*\b`), "<hex>"},
+	{regexp.MustCompile(`\d+`), "<n>"},
+}
+
+// DefaultNormalizeMessage replaces UUIDs, hex strings, and numbers in a
+// message with placeholders, so "user 123 not found" and "user 456 not found"
+// normalize to the same text. It can be used as Config.NormalizeMessage.
+func DefaultNormalizeMessage(message string) string {
+	for _, n := range MessageNormalizers {
+		message = n.Pattern.ReplaceAllString(message, n.Replacement)
+	}
+	return message
+}
+
+// DefaultFingerprint groups a notice by its error class, its normalized
+// message, and the first application frame of its backtrace. The frame's
+// line number is left out so unrelated edits to the file don't split a
+// group. It can be used as Config.Fingerprinter.
+func DefaultFingerprint(n *Notice) string {
+	key := n.ErrorClass + "\n" + DefaultNormalizeMessage(n.Message) + "\n" + applicationFrame(n.Backtrace)
+	sum := sha1.Sum([]byte(key)) //nolint:gosec // Used for grouping, not security
+	return hex.EncodeToString(sum[:])
+}
+
+// applicationFrame returns the first backtrace frame outside the standard
+// library and third-party modules, without its line number. It falls back
+// to the first frame.
+func applicationFrame(backtrace []string) string {
+	if len(backtrace) == 0 {
+		return ""
+	}
+	for _, frame := range backtrace {
+		if !isLibraryFrame(frame) {
+			return stripLineNumber(frame)
+		}
+	}
+	return stripLineNumber(backtrace[0])
+}
+
+// isLibraryFrame reports whether a formatted frame belongs to the standard
+// library, the module cache, or a vendor directory.
+func isLibraryFrame(frame string) bool {
+	file, _ := splitFrame(frame)
+	return isLibraryFile(file)
+}
+
+// isLibraryFile reports whether file belongs to the standard library, the
+// module cache, or a vendor directory.
+func isLibraryFile(file string) bool {
+	if root := runtime.GOROOT(); root != "" && strings.HasPrefix(file, root+"/src/") {
+		return true
+	}
+	return strings.Contains(file, "/pkg/mod/") || strings.Contains(file, "/vendor/") || strings.Contains(file, "@v")
+}
+
+// stripLineNumber turns "file.go:42 in pkg.Func" into "file.go in pkg.Func".
+func stripLineNumber(frame string) string {
+	file, function := splitFrame(frame)
+	if function == "" {
+		return file
+	}
+	return file + " in " + function
+}
+
+// splitFrame splits a formatted "file:line in function" frame into its file
+// and function.
+func splitFrame(frame string) (file, function string) {
+	location := frame
+	if i := strings.LastIndex(frame, " in "); i >= 0 {
+		location, function = frame[:i], frame[i+len(" in "):]
+	}
+	file = location
+	if i := strings.LastIndex(location, ":"); i >= 0 {
+		file = location[:i]
+	}
+	return file, function
+}
+
+// normalizedFingerprint computes a fingerprint from the error class and the
+// normalized message.
+func normalizedFingerprint(class, message string, normalize func(string) string) string {
+	sum := sha1.Sum([]byte(class + "\n" + normalize(message))) //nolint:gosec // Used for grouping, not security
+	return hex.EncodeToString(sum[:])
+}
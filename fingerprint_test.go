@@ -0,0 +1,183 @@
+package checkend
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"runtime"
+	"testing"
+)
+
+func TestDefaultNormalizeMessage(t *testing.T) {
+	tests := []struct {
+		message  string
+		expected string
+	}{
+		{"user 123 not found", "user <n> not found"},
+		{"order 5f0c2b9e-1a2b-4c3d-8e9f-0a1b2c3d4e5f missing", "order <uuid> missing"},
+		{"bad pointer 0xc000123abc", "bad pointer <hex>"},
+		{"commit deadbeef42 failed", "commit <hex> failed"},
+		{"connection refused", "connection refused"},
+	}
+
+	for _, tt := range tests {
+		if got := DefaultNormalizeMessage(tt.message); got != tt.expected {
+			t.Errorf("DefaultNormalizeMessage(%q) = %q, expected %q", tt.message, got, tt.expected)
+		}
+	}
+}
+
+func TestNormalizeMessageFingerprint(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{
+		APIKey:           "test-key",
+		Enabled:          boolPtr(true),
+		AsyncSend:        false,
+		NormalizeMessage: DefaultNormalizeMessage,
+	})
+
+	Notify(fmt.Errorf("user %d not found", 123))
+	first := TestingLastNotice()
+	Notify(fmt.Errorf("user %d not found", 456))
+	second := TestingLastNotice()
+
+	if first.Fingerprint == "" || first.Fingerprint != second.Fingerprint {
+		t.Errorf("Expected matching fingerprints, got '%s' and '%s'", first.Fingerprint, second.Fingerprint)
+	}
+	if first.Message != "user 123 not found" {
+		t.Errorf("Expected raw message to be kept, got '%s'", first.Message)
+	}
+
+	Notify(errors.New("permission denied"))
+	if TestingLastNotice().Fingerprint == first.Fingerprint {
+		t.Error("Expected a different message to have a different fingerprint")
+	}
+
+	Notify(errors.New("user 789 not found"), WithFingerprint("custom"))
+	if TestingLastNotice().Fingerprint != "custom" {
+		t.Errorf("Expected explicit fingerprint to win, got '%s'", TestingLastNotice().Fingerprint)
+	}
+}
+
+func TestNoFingerprintWithoutNormalizer(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{APIKey: "test-key", Enabled: boolPtr(true)})
+
+	Notify(errors.New("user 123 not found"))
+	if TestingLastNotice().Fingerprint != "" {
+		t.Errorf("Expected server-side grouping by default, got '%s'", TestingLastNotice().Fingerprint)
+	}
+}
+
+func TestFingerprinter(t *testing.T) {
+	defer Reset()
+
+	var seen *Notice
+	SetupTesting()
+	Configure(Config{
+		APIKey:  "test-key",
+		Enabled: boolPtr(true),
+		Fingerprinter: func(n *Notice) string {
+			seen = n
+			if n.Message == "user 123 not found" {
+				return "class:" + n.ErrorClass
+			}
+			return ""
+		},
+	})
+
+	Notify(errors.New("user 123 not found"))
+	if TestingLastNotice().Fingerprint != "class:"+TestingLastNotice().ErrorClass {
+		t.Errorf("Expected fingerprinter result, got '%s'", TestingLastNotice().Fingerprint)
+	}
+	if seen == nil || len(seen.Backtrace) == 0 {
+		t.Error("Expected fingerprinter to receive the assembled notice")
+	}
+
+	Notify(fmt.Errorf("wrapped: %w", errors.New("inner")))
+	if TestingLastNotice().Fingerprint != "" {
+		t.Errorf("Expected empty result to leave grouping to the server, got '%s'", TestingLastNotice().Fingerprint)
+	}
+
+	Notify(errors.New("user 456 not found"), WithFingerprint("custom"))
+	if TestingLastNotice().Fingerprint != "custom" {
+		t.Errorf("Expected explicit fingerprint to win, got '%s'", TestingLastNotice().Fingerprint)
+	}
+}
+
+func TestFingerprinterSeesNormalizedFingerprint(t *testing.T) {
+	defer Reset()
+
+	var normalized string
+	SetupTesting()
+	Configure(Config{
+		APIKey:           "test-key",
+		Enabled:          boolPtr(true),
+		NormalizeMessage: DefaultNormalizeMessage,
+		Fingerprinter: func(n *Notice) string {
+			normalized = n.Fingerprint
+			return n.Fingerprint
+		},
+	})
+
+	Notify(errors.New("user 123 not found"))
+	if normalized == "" || TestingLastNotice().Fingerprint != normalized {
+		t.Errorf("Expected the normalized fingerprint to be passed through, got '%s'", TestingLastNotice().Fingerprint)
+	}
+}
+
+func TestDefaultFingerprint(t *testing.T) {
+	backtrace := []string{
+		runtime.GOROOT() + "/src/runtime/panic.go:770 in runtime.gopanic",
+		"/home/app/go/pkg/mod/github.com/lib/pq@v1.10.9/conn.go:120 in github.com/lib/pq.(*conn).query",
+		"/srv/app/orders/store.go:42 in example.com/app/orders.(*Store).Find",
+		"/srv/app/main.go:10 in main.main",
+	}
+	base := &Notice{ErrorClass: "*orders.NotFound", Message: "order 123 not found", Backtrace: backtrace}
+
+	moved := append([]string{}, backtrace...)
+	moved[2] = "/srv/app/orders/store.go:57 in example.com/app/orders.(*Store).Find"
+	sameGroup := &Notice{ErrorClass: "*orders.NotFound", Message: "order 456 not found", Backtrace: moved}
+
+	if DefaultFingerprint(base) != DefaultFingerprint(sameGroup) {
+		t.Error("Expected notices differing in IDs and line numbers to share a fingerprint")
+	}
+
+	otherFrame := append([]string{}, backtrace...)
+	otherFrame[2] = "/srv/app/orders/cache.go:42 in example.com/app/orders.(*Cache).Find"
+	if DefaultFingerprint(base) == DefaultFingerprint(&Notice{ErrorClass: base.ErrorClass, Message: base.Message, Backtrace: otherFrame}) {
+		t.Error("Expected a different application frame to change the fingerprint")
+	}
+
+	// Library frames above the application frame are ignored
+	otherLibrary := append([]string{}, backtrace...)
+	otherLibrary[1] = "/home/app/go/pkg/mod/github.com/jackc/pgx/v5@v5.5.0/conn.go:300 in github.com/jackc/pgx/v5.(*Conn).Query"
+	if DefaultFingerprint(base) != DefaultFingerprint(&Notice{ErrorClass: base.ErrorClass, Message: base.Message, Backtrace: otherLibrary}) {
+		t.Error("Expected library frames not to affect the fingerprint")
+	}
+
+	if DefaultFingerprint(base) == DefaultFingerprint(&Notice{ErrorClass: "*orders.Conflict", Message: base.Message, Backtrace: backtrace}) {
+		t.Error("Expected a different error class to change the fingerprint")
+	}
+	if len(DefaultFingerprint(base)) != 40 {
+		t.Errorf("Expected a SHA-1 hex digest, got %q", DefaultFingerprint(base))
+	}
+}
+
+func TestMessageNormalizersAreExtensible(t *testing.T) {
+	saved := MessageNormalizers
+	defer func() { MessageNormalizers = saved }()
+
+	MessageNormalizers = append(append([]MessageNormalizer{}, saved...), MessageNormalizer{
+		Pattern:     regexp.MustCompile(`tenant-[a-z]+`),
+		Replacement: "<tenant>",
+	})
+
+	if got := DefaultNormalizeMessage("quota exceeded for tenant-acme"); got != "quota exceeded for <tenant>" {
+		t.Errorf("Expected custom normalizer to apply, got %q", got)
+	}
+}
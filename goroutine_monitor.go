@@ -0,0 +1,197 @@
+package checkend
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultGoroutineMonitorInterval is how often the goroutine monitor
+// samples the goroutine count when no interval is given.
+const DefaultGoroutineMonitorInterval = time.Minute
+
+// GoroutineGrowthSamples is the number of consecutive samples with a
+// rising goroutine count that the goroutine monitor reports as a leak.
+const GoroutineGrowthSamples = 5
+
+const (
+	maxGoroutineDumpBytes = 1 << 20
+	maxGoroutineStacks    = 10
+)
+
+// GoroutineLeakError is reported by the goroutine monitor when the number of
+// goroutines looks like a leak.
+type GoroutineLeakError struct {
+	Count     int
+	Threshold int
+	Reason    string
+}
+
+func (e *GoroutineLeakError) Error() string {
+	return fmt.Sprintf("possible goroutine leak: %d goroutines (%s)", e.Count, e.Reason)
+}
+
+var (
+	goroutineMonitorMu   sync.Mutex
+	goroutineMonitorStop chan struct{}
+	goroutineMonitorDone chan struct{}
+)
+
+// StartGoroutineMonitor samples runtime.NumGoroutine every interval and
+// reports a notice tagged "goroutine_leak" when the count rises above
+// threshold, or when it has risen for GoroutineGrowthSamples samples in a
+// row. The notice's context summarizes where the goroutines are blocked
+// and which functions started them. A threshold of zero or less only
+// checks for growth; an interval of zero or less uses
+// DefaultGoroutineMonitorInterval.
+//
+// The threshold is reported once each time it is crossed. Starting the
+// monitor again replaces the running one.
+func StartGoroutineMonitor(threshold int, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultGoroutineMonitorInterval
+	}
+
+	StopGoroutineMonitor()
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	goroutineMonitorMu.Lock()
+	goroutineMonitorStop, goroutineMonitorDone = stop, done
+	goroutineMonitorMu.Unlock()
+
+	go runGoroutineMonitor(threshold, interval, stop, done)
+}
+
+// StopGoroutineMonitor stops the monitor started by StartGoroutineMonitor
+// and waits for it to exit.
+func StopGoroutineMonitor() {
+	goroutineMonitorMu.Lock()
+	stop, done := goroutineMonitorStop, goroutineMonitorDone
+	goroutineMonitorStop, goroutineMonitorDone = nil, nil
+	goroutineMonitorMu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+func runGoroutineMonitor(threshold int, interval time.Duration, stop, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var (
+		previous = runtime.NumGoroutine()
+		rising   int
+		above    bool
+	)
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		count := runtime.NumGoroutine()
+		if count > previous {
+			rising++
+		} else {
+			rising = 0
+		}
+		previous = count
+
+		switch {
+		case threshold > 0 && count > threshold:
+			if !above {
+				above = true
+				reportGoroutineLeak(count, threshold, fmt.Sprintf("above threshold of %d", threshold))
+			}
+		case rising >= GoroutineGrowthSamples:
+			rising = 0
+			reportGoroutineLeak(count, threshold, fmt.Sprintf("rising for %d samples", GoroutineGrowthSamples))
+		}
+		if threshold > 0 && count <= threshold {
+			above = false
+		}
+	}
+}
+
+func reportGoroutineLeak(count, threshold int, reason string) {
+	Notify(
+		&GoroutineLeakError{Count: count, Threshold: threshold, Reason: reason},
+		WithTags("goroutine_leak"),
+		WithFingerprint("goroutine_leak"),
+		WithoutBacktrace(),
+		WithContext(map[string]interface{}{
+			"goroutines": count,
+			"threshold":  threshold,
+			"stacks":     goroutineStacks(),
+		}),
+	)
+}
+
+// goroutineStacks summarizes a dump of all goroutine stacks as the most
+// common pairs of the function a goroutine is in and the function that
+// started it, with their counts.
+func goroutineStacks() []map[string]interface{} {
+	buf := make([]byte, maxGoroutineDumpBytes)
+	buf = buf[:runtime.Stack(buf, true)]
+
+	type stack struct{ function, createdBy string }
+	counts := make(map[stack]int)
+	for _, dump := range strings.Split(string(buf), "\n\n") {
+		lines := strings.Split(dump, "\n")
+		if len(lines) < 2 {
+			continue
+		}
+		s := stack{function: stackFunction(lines[1])}
+		for _, line := range lines {
+			if strings.HasPrefix(line, "created by ") {
+				s.createdBy = stackFunction(strings.TrimPrefix(line, "created by "))
+			}
+		}
+		counts[s]++
+	}
+
+	stacks := make([]stack, 0, len(counts))
+	for s := range counts {
+		stacks = append(stacks, s)
+	}
+	sort.Slice(stacks, func(i, j int) bool {
+		if counts[stacks[i]] != counts[stacks[j]] {
+			return counts[stacks[i]] > counts[stacks[j]]
+		}
+		return stacks[i].function < stacks[j].function
+	})
+	if len(stacks) > maxGoroutineStacks {
+		stacks = stacks[:maxGoroutineStacks]
+	}
+
+	summary := make([]map[string]interface{}, 0, len(stacks))
+	for _, s := range stacks {
+		summary = append(summary, map[string]interface{}{
+			"function":   s.function,
+			"created_by": s.createdBy,
+			"count":      counts[s],
+		})
+	}
+	return summary
+}
+
+// stackFunction returns the function name from a line of a goroutine stack
+// dump, dropping its arguments and the creating goroutine.
+func stackFunction(line string) string {
+	if i := strings.Index(line, " in goroutine "); i >= 0 {
+		line = line[:i]
+	}
+	if i := strings.LastIndex(line, "("); i > 0 {
+		line = line[:i]
+	}
+	return line
+}
@@ -0,0 +1,98 @@
+package checkend
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func waitForNotices(t *testing.T, timeout time.Duration) bool {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if TestingHasNotices() {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return false
+}
+
+func TestGoroutineMonitorReportsThreshold(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{APIKey: "test-key", Enabled: boolPtr(true)})
+
+	threshold := runtime.NumGoroutine() + 5
+	StartGoroutineMonitor(threshold, 10*time.Millisecond)
+
+	release := make(chan struct{})
+	defer close(release)
+	for i := 0; i < 10; i++ {
+		go func() { <-release }()
+	}
+
+	if !waitForNotices(t, 2*time.Second) {
+		t.Fatal("Expected a notice once the goroutine count passed the threshold")
+	}
+	StopGoroutineMonitor()
+
+	notice := TestingLastNotice()
+	if len(notice.Tags) != 1 || notice.Tags[0] != "goroutine_leak" {
+		t.Errorf("Expected goroutine_leak tag, got %v", notice.Tags)
+	}
+	if count, _ := notice.Context["goroutines"].(int); count <= threshold {
+		t.Errorf("Expected a goroutine count above %d, got %v", threshold, notice.Context["goroutines"])
+	}
+	stacks, _ := notice.Context["stacks"].([]interface{})
+	if len(stacks) == 0 {
+		t.Fatalf("Expected a stack summary, got %v", notice.Context["stacks"])
+	}
+	top := stacks[0].(map[string]interface{})
+	if top["created_by"] != "github.com/Checkend/checkend-go.TestGoroutineMonitorReportsThreshold" || top["count"] != 10 {
+		t.Errorf("Expected the leaked goroutines to lead the summary, got %v", top)
+	}
+	if TestingNoticeCount() != 1 {
+		t.Errorf("Expected the threshold to be reported once, got %d notices", TestingNoticeCount())
+	}
+}
+
+func TestGoroutineMonitorReportsGrowth(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{APIKey: "test-key", Enabled: boolPtr(true)})
+
+	StartGoroutineMonitor(0, 20*time.Millisecond)
+
+	release := make(chan struct{})
+	defer close(release)
+	stop := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			go func() { <-release }()
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+
+	reported := waitForNotices(t, 3*time.Second)
+	close(stop)
+	StopGoroutineMonitor()
+
+	if !reported {
+		t.Fatal("Expected a notice while the goroutine count kept rising")
+	}
+	if count, ok := TestingLastNotice().Context["goroutines"].(int); !ok || count == 0 {
+		t.Errorf("Expected the goroutine count in context, got %v", TestingLastNotice().Context)
+	}
+}
+
+func TestStopGoroutineMonitorWithoutStart(t *testing.T) {
+	StopGoroutineMonitor()
+}
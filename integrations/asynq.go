@@ -28,11 +28,9 @@ type AsynqTaskInfo struct {
 // AsynqMiddleware creates middleware that wraps Asynq task handlers with
 // error reporting and panic recovery.
 //
-// Usage with Asynq:
-//
-//	mux := asynq.NewServeMux()
-//	mux.Use(integrations.AsynqMiddleware())
-//	mux.HandleFunc("email:send", handleEmailTask)
+// Deprecated: This is a placeholder that returns handlers unchanged to
+// avoid importing asynq. Use Middleware from
+// github.com/Checkend/checkend-go/integrations/asynq.
 func AsynqMiddleware() func(next interface{}) interface{} {
 	return func(next interface{}) interface{} {
 		// Return a function that matches Asynq's middleware signature
@@ -64,7 +62,7 @@ func AsynqErrorHandler(ctx context.Context, task AsynqTask, err error, opts ...c
 	ctx = checkend.SetContext(ctx, taskCtx)
 
 	allOpts := append([]checkend.NotifyOption{
-		checkend.WithTags("asynq", "background_job"),
+		checkend.WithTags(defaultTags("asynq")...),
 	}, opts...)
 
 	checkend.NotifyWithContext(ctx, err, allOpts...)
@@ -90,7 +88,7 @@ func AsynqErrorHandlerWithInfo(ctx context.Context, info *AsynqTaskInfo, err err
 	ctx = checkend.SetContext(ctx, taskCtx)
 
 	allOpts := append([]checkend.NotifyOption{
-		checkend.WithTags("asynq", "background_job"),
+		checkend.WithTags(defaultTags("asynq")...),
 	}, opts...)
 
 	checkend.NotifyWithContext(ctx, err, allOpts...)
@@ -115,7 +113,7 @@ func AsynqPanicHandler(ctx context.Context, task AsynqTask) {
 			err = fmt.Errorf("panic in asynq task: %v", v)
 		}
 
-		AsynqErrorHandler(ctx, task, err)
+		AsynqErrorHandler(ctx, task, err, checkend.WithPanicStack())
 		panic(r) // Re-panic to let Asynq handle retry logic
 	}
 }
@@ -132,7 +130,7 @@ func AsynqRecoverHandler(ctx context.Context, task AsynqTask) error {
 			err = fmt.Errorf("panic in asynq task: %v", v)
 		}
 
-		AsynqErrorHandler(ctx, task, err)
+		AsynqErrorHandler(ctx, task, err, checkend.WithPanicStack())
 		return err
 	}
 	return nil
@@ -0,0 +1,25 @@
+module github.com/Checkend/checkend-go/integrations/asynq
+
+go 1.21
+
+require (
+	github.com/Checkend/checkend-go v0.2.0
+	github.com/hibiken/asynq v0.24.1
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/golang/protobuf v1.5.2 // indirect
+	github.com/google/uuid v1.2.0 // indirect
+	github.com/redis/go-redis/v9 v9.0.3 // indirect
+	github.com/robfig/cron/v3 v3.0.1 // indirect
+	github.com/spf13/cast v1.3.1 // indirect
+	golang.org/x/sys v0.0.0-20211216021012-1d35b9e2eb4e // indirect
+	golang.org/x/time v0.0.0-20190308202827-9d24e82272b4 // indirect
+	google.golang.org/protobuf v1.26.0 // indirect
+)
+
+// Build against the core module in this repository during development.
+// Consumers ignore replace directives and get the required release.
+replace github.com/Checkend/checkend-go => ../..
@@ -0,0 +1,74 @@
+// Package asynq reports errors and panics from Asynq task handlers to
+// Checkend. It is a separate module so the core SDK doesn't depend on
+// Asynq.
+//
+// Usage:
+//
+//	import checkendasynq "github.com/Checkend/checkend-go/integrations/asynq"
+//
+//	mux := asynq.NewServeMux()
+//	mux.Use(checkendasynq.Middleware())
+//	mux.HandleFunc("email:send", handleEmailTask)
+package asynq
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Checkend/checkend-go"
+	"github.com/Checkend/checkend-go/integrations"
+	asynqlib "github.com/hibiken/asynq"
+)
+
+// Middleware wraps Asynq task handlers with error reporting and panic
+// recovery. Returned errors are reported with the task's ID, queue, and
+// retry counts; panics are reported and re-raised so Asynq's retry logic
+// still runs.
+func Middleware() asynqlib.MiddlewareFunc {
+	return func(next asynqlib.Handler) asynqlib.Handler {
+		return asynqlib.HandlerFunc(func(ctx context.Context, task *asynqlib.Task) error {
+			defer func() {
+				if r := recover(); r != nil {
+					var err error
+					switch v := r.(type) {
+					case error:
+						err = v
+					default:
+						err = fmt.Errorf("panic in asynq task: %v", v)
+					}
+
+					integrations.AsynqErrorHandlerWithInfo(ctx, taskInfo(ctx, task), err, checkend.WithPanicStack())
+					panic(r) // Re-panic to let Asynq handle retry logic
+				}
+			}()
+
+			err := next.ProcessTask(ctx, task)
+			if err != nil {
+				integrations.AsynqErrorHandlerWithInfo(ctx, taskInfo(ctx, task), err)
+			}
+			return err
+		})
+	}
+}
+
+func taskInfo(ctx context.Context, task *asynqlib.Task) *integrations.AsynqTaskInfo {
+	info := &integrations.AsynqTaskInfo{
+		Type:    task.Type(),
+		Payload: task.Payload(),
+	}
+
+	if id, ok := asynqlib.GetTaskID(ctx); ok {
+		info.ID = id
+	}
+	if queue, ok := asynqlib.GetQueueName(ctx); ok {
+		info.Queue = queue
+	}
+	if retried, ok := asynqlib.GetRetryCount(ctx); ok {
+		info.Retried = retried
+	}
+	if maxRetry, ok := asynqlib.GetMaxRetry(ctx); ok {
+		info.MaxRetry = maxRetry
+	}
+
+	return info
+}
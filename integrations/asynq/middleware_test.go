@@ -0,0 +1,95 @@
+package asynq
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Checkend/checkend-go"
+	asynqlib "github.com/hibiken/asynq"
+)
+
+func setupTesting(t *testing.T) {
+	t.Helper()
+	enabled := true
+	checkend.SetupTesting()
+	checkend.Configure(checkend.Config{
+		APIKey:  "test-key",
+		Enabled: &enabled,
+	})
+	t.Cleanup(checkend.Reset)
+}
+
+func newMux(handler asynqlib.HandlerFunc) *asynqlib.ServeMux {
+	mux := asynqlib.NewServeMux()
+	mux.Use(Middleware())
+	mux.HandleFunc("email:send", handler)
+	return mux
+}
+
+func TestMiddlewareReportsErrors(t *testing.T) {
+	setupTesting(t)
+
+	mux := newMux(func(ctx context.Context, task *asynqlib.Task) error {
+		return errors.New("smtp unavailable")
+	})
+	task := asynqlib.NewTask("email:send", []byte(`{"to":"user@example.com"}`))
+
+	if err := mux.ProcessTask(context.Background(), task); err == nil {
+		t.Error("Expected the task error to be returned")
+	}
+
+	if checkend.TestingNoticeCount() != 1 {
+		t.Fatalf("Expected 1 notice, got %d", checkend.TestingNoticeCount())
+	}
+	notice := checkend.TestingLastNotice()
+	if notice.Message != "smtp unavailable" {
+		t.Errorf("Unexpected message '%s'", notice.Message)
+	}
+	info, ok := notice.Context["asynq"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected asynq context, got %v", notice.Context)
+	}
+	if info["task_type"] != "email:send" {
+		t.Errorf("Expected task_type 'email:send', got %v", info["task_type"])
+	}
+}
+
+func TestMiddlewareIgnoresSuccess(t *testing.T) {
+	setupTesting(t)
+
+	mux := newMux(func(ctx context.Context, task *asynqlib.Task) error {
+		return nil
+	})
+
+	if err := mux.ProcessTask(context.Background(), asynqlib.NewTask("email:send", nil)); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if checkend.TestingHasNotices() {
+		t.Error("Expected no notices for a successful task")
+	}
+}
+
+func TestMiddlewareReportsAndRepanics(t *testing.T) {
+	setupTesting(t)
+
+	mux := newMux(func(ctx context.Context, task *asynqlib.Task) error {
+		panic("boom")
+	})
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("Expected the panic to be re-raised, got %v", r)
+			}
+		}()
+		mux.ProcessTask(context.Background(), asynqlib.NewTask("email:send", nil))
+	}()
+
+	if checkend.TestingNoticeCount() != 1 {
+		t.Fatalf("Expected 1 notice, got %d", checkend.TestingNoticeCount())
+	}
+	if msg := checkend.TestingLastNotice().Message; msg != "panic in asynq task: boom" {
+		t.Errorf("Unexpected message '%s'", msg)
+	}
+}
@@ -10,13 +10,8 @@ import (
 // EchoMiddleware returns an Echo middleware for Checkend error reporting.
 // This middleware is compatible with the labstack/echo framework.
 //
-// Usage:
-//
-//	import "github.com/labstack/echo/v4"
-//	import "github.com/Checkend/checkend-go/integrations"
-//
-//	e := echo.New()
-//	e.Use(integrations.EchoMiddleware())
+// Deprecated: This is a placeholder that avoids importing echo and reports
+// nothing. Use Middleware from github.com/Checkend/checkend-go/integrations/echo.
 func EchoMiddleware() interface{} {
 	// Return a function that matches echo.MiddlewareFunc signature
 	// We use interface{} to avoid importing echo as a dependency
@@ -44,8 +39,15 @@ func EchoErrorHandler(r *http.Request, err error, opts ...checkend.NotifyOption)
 	checkend.NotifyWithContext(ctx, err, opts...)
 }
 
-// EchoPanicHandler handles panics and reports them to Checkend.
-func EchoPanicHandler(r *http.Request, recovered interface{}) {
+// EchoPanicHandler handles panics and reports them to Checkend. Panics with
+// http.ErrAbortHandler are not reported unless WithReportAbortHandler is
+// passed; the caller should re-raise them so the server aborts the response.
+// Other options are ignored.
+func EchoPanicHandler(r *http.Request, recovered interface{}, opts ...HTTPMiddlewareOption) {
+	if isAbortPanic(recovered) && !reportsAbort(opts) {
+		return
+	}
+
 	var err error
 	switch v := recovered.(type) {
 	case error:
@@ -55,10 +57,14 @@ func EchoPanicHandler(r *http.Request, recovered interface{}) {
 	}
 
 	ctx := checkend.SetRequest(r.Context(), extractRequest(r))
-	checkend.NotifyWithContext(ctx, err)
+	checkend.NotifyWithContext(ctx, err, checkend.WithPanicStack())
 }
 
 // EchoRecoveryMiddleware returns a recovery middleware that reports panics.
+//
+// Deprecated: This is a placeholder that avoids importing echo and reports
+// nothing. Use RecoveryMiddleware from
+// github.com/Checkend/checkend-go/integrations/echo.
 func EchoRecoveryMiddleware() interface{} {
 	return func(next interface{}) interface{} {
 		return func(c interface{}) error {
@@ -0,0 +1,65 @@
+// Package echo reports errors and panics from Echo v4 applications to
+// Checkend. It is a separate module so the core SDK doesn't depend on
+// Echo.
+//
+// Usage:
+//
+//	import checkendecho "github.com/Checkend/checkend-go/integrations/echo"
+//
+//	e := echo.New()
+//	e.Use(checkendecho.RecoveryMiddleware())
+//	e.Use(checkendecho.Middleware())
+package echo
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Checkend/checkend-go/integrations"
+	echolib "github.com/labstack/echo/v4"
+)
+
+// Middleware returns an Echo middleware for Checkend error reporting.
+// Errors returned by handlers are reported, except *echo.HTTPError values
+// with a 4xx status code.
+func Middleware() echolib.MiddlewareFunc {
+	return func(next echolib.HandlerFunc) echolib.HandlerFunc {
+		return func(c echolib.Context) error {
+			err := next(c)
+			if err != nil && shouldReport(err) {
+				integrations.EchoErrorHandler(c.Request(), err)
+			}
+			return err
+		}
+	}
+}
+
+// RecoveryMiddleware returns a recovery middleware that reports panics and
+// responds with a 500 *echo.HTTPError. Panics with http.ErrAbortHandler are
+// re-raised so the server aborts the response, and only reported when
+// integrations.WithReportAbortHandler is passed.
+func RecoveryMiddleware(opts ...integrations.HTTPMiddlewareOption) echolib.MiddlewareFunc {
+	return func(next echolib.HandlerFunc) echolib.HandlerFunc {
+		return func(c echolib.Context) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					integrations.EchoPanicHandler(c.Request(), r, opts...)
+					if err, ok := r.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+						// Let the server abort the response
+						panic(r)
+					}
+					err = echolib.NewHTTPError(http.StatusInternalServerError)
+				}
+			}()
+			return next(c)
+		}
+	}
+}
+
+func shouldReport(err error) bool {
+	var httpErr *echolib.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Code < 400 || httpErr.Code >= 500
+	}
+	return true
+}
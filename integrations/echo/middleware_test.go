@@ -0,0 +1,91 @@
+package echo
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Checkend/checkend-go"
+	echolib "github.com/labstack/echo/v4"
+)
+
+func setupTesting(t *testing.T) {
+	t.Helper()
+	enabled := true
+	checkend.SetupTesting()
+	checkend.Configure(checkend.Config{
+		APIKey:  "test-key",
+		Enabled: &enabled,
+	})
+	t.Cleanup(checkend.Reset)
+}
+
+func newServer() *echolib.Echo {
+	e := echolib.New()
+	e.Use(RecoveryMiddleware())
+	e.Use(Middleware())
+	e.GET("/error", func(c echolib.Context) error {
+		return errors.New("database unavailable")
+	})
+	e.GET("/missing", func(c echolib.Context) error {
+		return echolib.NewHTTPError(http.StatusNotFound)
+	})
+	e.GET("/panic", func(c echolib.Context) error {
+		panic("boom")
+	})
+	return e
+}
+
+func TestMiddlewareReportsErrors(t *testing.T) {
+	setupTesting(t)
+
+	rec := httptest.NewRecorder()
+	newServer().ServeHTTP(rec, httptest.NewRequest("GET", "/error", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", rec.Code)
+	}
+	if checkend.TestingNoticeCount() != 1 {
+		t.Fatalf("Expected 1 notice, got %d", checkend.TestingNoticeCount())
+	}
+	notice := checkend.TestingLastNotice()
+	if notice.Message != "database unavailable" {
+		t.Errorf("Unexpected message '%s'", notice.Message)
+	}
+	if notice.Request["method"] != "GET" {
+		t.Errorf("Expected request method 'GET', got %v", notice.Request["method"])
+	}
+}
+
+func TestMiddlewareIgnoresClientErrors(t *testing.T) {
+	setupTesting(t)
+
+	rec := httptest.NewRecorder()
+	newServer().ServeHTTP(rec, httptest.NewRequest("GET", "/missing", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+	if checkend.TestingHasNotices() {
+		t.Error("Expected no notices for a 4xx error")
+	}
+}
+
+func TestRecoveryMiddlewareReportsPanics(t *testing.T) {
+	setupTesting(t)
+
+	rec := httptest.NewRecorder()
+	newServer().ServeHTTP(rec, httptest.NewRequest("GET", "/panic", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", rec.Code)
+	}
+	// The 500 *echo.HTTPError from the recovery is not reported again
+	if checkend.TestingNoticeCount() != 1 {
+		t.Fatalf("Expected 1 notice, got %d", checkend.TestingNoticeCount())
+	}
+	if msg := checkend.TestingLastNotice().Message; msg != "panic: boom" {
+		t.Errorf("Unexpected message '%s'", msg)
+	}
+}
@@ -71,8 +71,15 @@ func GinErrorHandler(r *http.Request, err error, opts ...checkend.NotifyOption)
 	checkend.NotifyWithContext(ctx, err, opts...)
 }
 
-// GinPanicHandler handles panics and reports them to Checkend.
-func GinPanicHandler(r *http.Request, recovered interface{}) {
+// GinPanicHandler handles panics and reports them to Checkend. Panics with
+// http.ErrAbortHandler are not reported unless WithReportAbortHandler is
+// passed; the caller should re-raise them so the server aborts the response.
+// Other options are ignored.
+func GinPanicHandler(r *http.Request, recovered interface{}, opts ...HTTPMiddlewareOption) {
+	if isAbortPanic(recovered) && !reportsAbort(opts) {
+		return
+	}
+
 	var err error
 	switch v := recovered.(type) {
 	case error:
@@ -82,5 +89,5 @@ func GinPanicHandler(r *http.Request, recovered interface{}) {
 	}
 
 	ctx := checkend.SetRequest(r.Context(), extractRequest(r))
-	checkend.NotifyWithContext(ctx, err)
+	checkend.NotifyWithContext(ctx, err, checkend.WithPanicStack())
 }
@@ -0,0 +1,15 @@
+// Package grpc reports errors and panics from gRPC servers to Checkend.
+//
+// It is a separate module so the core SDK doesn't depend on gRPC:
+//
+//	go get github.com/Checkend/checkend-go/integrations/grpc
+//
+// Usage:
+//
+//	import checkendgrpc "github.com/Checkend/checkend-go/integrations/grpc"
+//
+//	server := grpc.NewServer(
+//		grpc.ChainUnaryInterceptor(checkendgrpc.UnaryServerInterceptor()),
+//		grpc.ChainStreamInterceptor(checkendgrpc.StreamServerInterceptor()),
+//	)
+package grpc
@@ -0,0 +1,163 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Checkend/checkend-go"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultIgnoredCodes are the status codes not reported by default. They
+// describe client mistakes or cancellations rather than server faults.
+var DefaultIgnoredCodes = []codes.Code{
+	codes.Canceled,
+	codes.InvalidArgument,
+	codes.NotFound,
+	codes.AlreadyExists,
+	codes.PermissionDenied,
+	codes.Unauthenticated,
+}
+
+// DefaultMetadataKeys are the incoming metadata keys attached to notices by
+// default.
+var DefaultMetadataKeys = []string{"user-agent", "x-request-id"}
+
+type options struct {
+	ignoredCodes map[codes.Code]bool
+	metadataKeys []string
+}
+
+// Option customizes the interceptors.
+type Option func(*options)
+
+// WithIgnoredCodes replaces DefaultIgnoredCodes with ignored. Errors with
+// these status codes are not reported; pass no codes to report every error.
+func WithIgnoredCodes(ignored ...codes.Code) Option {
+	return func(o *options) {
+		o.ignoredCodes = codeSet(ignored)
+	}
+}
+
+// WithMetadataKeys replaces DefaultMetadataKeys with keys. The values of
+// these incoming metadata keys are attached to the notice context.
+func WithMetadataKeys(keys ...string) Option {
+	return func(o *options) {
+		o.metadataKeys = keys
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		ignoredCodes: codeSet(DefaultIgnoredCodes),
+		metadataKeys: DefaultMetadataKeys,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func codeSet(list []codes.Code) map[codes.Code]bool {
+	set := make(map[codes.Code]bool, len(list))
+	for _, c := range list {
+		set[c] = true
+	}
+	return set
+}
+
+// UnaryServerInterceptor returns an interceptor that reports errors
+// returned by unary handlers, except those with an ignored status code.
+// Panics are reported and turned into a codes.Internal error so that one
+// failing request doesn't crash the server.
+func UnaryServerInterceptor(opts ...Option) grpclib.UnaryServerInterceptor {
+	o := newOptions(opts)
+	return func(ctx context.Context, req interface{}, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (resp interface{}, err error) {
+		ctx = checkend.SetContext(ctx, o.requestContext(ctx, info.FullMethod))
+
+		defer func() {
+			if r := recover(); r != nil {
+				err = o.reportPanic(ctx, r)
+			}
+		}()
+
+		resp, err = handler(ctx, req)
+		o.report(ctx, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns an interceptor that reports errors
+// returned by stream handlers, except those with an ignored status code.
+// Panics are reported and turned into a codes.Internal error.
+func StreamServerInterceptor(opts ...Option) grpclib.StreamServerInterceptor {
+	o := newOptions(opts)
+	return func(srv interface{}, ss grpclib.ServerStream, info *grpclib.StreamServerInfo, handler grpclib.StreamHandler) (err error) {
+		ctx := checkend.SetContext(ss.Context(), o.requestContext(ss.Context(), info.FullMethod))
+
+		defer func() {
+			if r := recover(); r != nil {
+				err = o.reportPanic(ctx, r)
+			}
+		}()
+
+		err = handler(srv, ss)
+		o.report(ctx, err)
+		return err
+	}
+}
+
+// report sends err unless it is nil or has an ignored status code.
+func (o *options) report(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+	code := status.Code(err)
+	if code == codes.OK || o.ignoredCodes[code] {
+		return
+	}
+	checkend.NotifyWithContext(ctx, err, checkend.WithTags("grpc", "grpc_code:"+code.String()))
+}
+
+// reportPanic reports a recovered panic and returns the error sent to the
+// client in its place. It must be called from the deferred function that
+// recovered the panic.
+func (o *options) reportPanic(ctx context.Context, recovered interface{}) error {
+	err, ok := recovered.(error)
+	if !ok {
+		err = fmt.Errorf("panic: %v", recovered)
+	}
+	checkend.NotifyWithContext(ctx, err,
+		checkend.WithTags("grpc", "grpc_code:"+codes.Internal.String()),
+		checkend.WithPanicStack(),
+	)
+	return status.Error(codes.Internal, "internal error")
+}
+
+// requestContext describes the call for the notice context.
+func (o *options) requestContext(ctx context.Context, method string) map[string]interface{} {
+	call := map[string]interface{}{"method": method}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		call["peer"] = p.Addr.String()
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		selected := make(map[string]interface{})
+		for _, key := range o.metadataKeys {
+			if values := md.Get(key); len(values) > 0 {
+				selected[strings.ToLower(key)] = strings.Join(values, ", ")
+			}
+		}
+		if len(selected) > 0 {
+			call["metadata"] = selected
+		}
+	}
+
+	return map[string]interface{}{"grpc": call}
+}
@@ -0,0 +1,167 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/Checkend/checkend-go"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// healthServer fails or panics depending on the requested service name.
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+}
+
+func (healthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	switch req.Service {
+	case "unavailable":
+		return nil, status.Error(codes.Unavailable, "database unavailable")
+	case "missing":
+		return nil, status.Error(codes.NotFound, "no such service")
+	case "panic":
+		panic("boom")
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func (healthServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	return errors.New("watch failed")
+}
+
+func setupTesting(t *testing.T) {
+	t.Helper()
+	enabled := true
+	checkend.SetupTesting()
+	checkend.Configure(checkend.Config{
+		APIKey:  "test-key",
+		Enabled: &enabled,
+	})
+	t.Cleanup(checkend.Reset)
+}
+
+// newClient starts a server with the interceptors on an in-memory
+// listener and returns a client connected to it.
+func newClient(t *testing.T) healthpb.HealthClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpclib.NewServer(
+		grpclib.ChainUnaryInterceptor(UnaryServerInterceptor()),
+		grpclib.ChainStreamInterceptor(StreamServerInterceptor()),
+	)
+	healthpb.RegisterHealthServer(server, healthServer{})
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpclib.NewClient("passthrough:///bufconn",
+		grpclib.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpclib.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+func hasTag(notice *checkend.Notice, tag string) bool {
+	for _, t := range notice.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func TestUnaryServerInterceptorReportsErrors(t *testing.T) {
+	setupTesting(t)
+	client := newClient(t)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "req-42")
+	_, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "unavailable"})
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("Expected Unavailable, got %v", err)
+	}
+
+	if checkend.TestingNoticeCount() != 1 {
+		t.Fatalf("Expected 1 notice, got %d", checkend.TestingNoticeCount())
+	}
+	notice := checkend.TestingLastNotice()
+	if !hasTag(notice, "grpc") || !hasTag(notice, "grpc_code:Unavailable") {
+		t.Errorf("Expected grpc tags, got %v", notice.Tags)
+	}
+	call, _ := notice.Context["grpc"].(map[string]interface{})
+	if call["method"] != "/grpc.health.v1.Health/Check" {
+		t.Errorf("Expected the full method name, got %v", call["method"])
+	}
+	md, _ := call["metadata"].(map[string]interface{})
+	if md["x-request-id"] != "req-42" {
+		t.Errorf("Expected x-request-id metadata, got %v", call["metadata"])
+	}
+}
+
+func TestUnaryServerInterceptorIgnoresClientErrors(t *testing.T) {
+	setupTesting(t)
+	client := newClient(t)
+
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("Expected NotFound, got %v", err)
+	}
+	if checkend.TestingHasNotices() {
+		t.Error("Expected NotFound not to be reported")
+	}
+}
+
+func TestUnaryServerInterceptorRecoversPanics(t *testing.T) {
+	setupTesting(t)
+	client := newClient(t)
+
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "panic"})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("Expected Internal, got %v", err)
+	}
+
+	if checkend.TestingNoticeCount() != 1 {
+		t.Fatalf("Expected 1 notice, got %d", checkend.TestingNoticeCount())
+	}
+	notice := checkend.TestingLastNotice()
+	if notice.Message != "panic: boom" {
+		t.Errorf("Unexpected message '%s'", notice.Message)
+	}
+	if !hasTag(notice, "grpc_code:Internal") {
+		t.Errorf("Expected grpc_code:Internal tag, got %v", notice.Tags)
+	}
+}
+
+func TestStreamServerInterceptorReportsErrors(t *testing.T) {
+	setupTesting(t)
+	client := newClient(t)
+
+	stream, err := client.Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err == nil || err == io.EOF {
+		t.Fatalf("Expected the stream to fail, got %v", err)
+	}
+
+	if checkend.TestingNoticeCount() != 1 {
+		t.Fatalf("Expected 1 notice, got %d", checkend.TestingNoticeCount())
+	}
+	if msg := checkend.TestingLastNotice().Message; msg != "watch failed" {
+		t.Errorf("Unexpected message '%s'", msg)
+	}
+}
@@ -1,80 +1,251 @@
 package integrations
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/Checkend/checkend-go"
 )
 
+// DefaultStatusThreshold is the lowest response status HTTPMiddleware
+// reports by default.
+const DefaultStatusThreshold = http.StatusInternalServerError
+
+// HTTPMiddlewareOption customizes HTTPMiddleware and HTTPRecoveryMiddleware.
+type HTTPMiddlewareOption func(*httpMiddleware)
+
+// WithStatusThreshold sets the lowest response status that is reported when
+// a handler returns normally. Zero or less disables status reporting.
+func WithStatusThreshold(status int) HTTPMiddlewareOption {
+	return func(m *httpMiddleware) {
+		m.statusThreshold = status
+	}
+}
+
+// WithReportAbortHandler makes the middleware report panics with
+// http.ErrAbortHandler, which handlers use to abort a response without
+// logging. By default they are not reported. Either way the panic is
+// re-raised so the server aborts the response.
+func WithReportAbortHandler() HTTPMiddlewareOption {
+	return func(m *httpMiddleware) {
+		m.reportAbort = true
+	}
+}
+
+type httpMiddleware struct {
+	next            http.Handler
+	recoverPanics   bool
+	repanic         bool
+	reportAbort     bool
+	statusThreshold int
+}
+
+// reportStateKey is the context key for the reportState of a request.
+type reportStateKey struct{}
+
+// reportState is shared by nested Checkend middleware handling the same
+// request, so a failure is reported once even when, say, HTTPMiddleware
+// is wrapped in ReportServerErrors.
+type reportState struct {
+	reported bool
+}
+
 // HTTPMiddleware wraps an http.Handler with Checkend error reporting.
-func HTTPMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Set request context
-		ctx := checkend.SetRequest(r.Context(), extractRequest(r))
+// Panics are reported and re-raised. A handler that returns normally after
+// responding with a status at or above the threshold, 500 by default, is
+// reported as an HTTPStatusError. The request's path and, when known, the
+// matched ServeMux route pattern are added to the request data, and the
+// pattern is added as a "route:<pattern>" tag. Panics with
+// http.ErrAbortHandler are re-raised without being reported; see
+// WithReportAbortHandler.
+//
+// The response writer passed to next still supports http.Flusher,
+// http.Hijacker and http.Pusher; they return http.ErrNotSupported (or, for
+// Flush, do nothing) when the underlying writer doesn't.
+func HTTPMiddleware(next http.Handler, opts ...HTTPMiddlewareOption) http.Handler {
+	return newHTTPMiddleware(next, true, opts)
+}
 
-		// Create a response wrapper to catch panics
+// HTTPRecoveryMiddleware is like HTTPMiddleware, but instead of re-raising
+// a panic it writes a 500 response, so clients don't see a dropped
+// connection. http.ErrAbortHandler is still re-raised so the server aborts
+// the response.
+func HTTPRecoveryMiddleware(next http.Handler, opts ...HTTPMiddlewareOption) http.Handler {
+	return newHTTPMiddleware(next, false, opts)
+}
+
+func newHTTPMiddleware(next http.Handler, repanic bool, opts []HTTPMiddlewareOption) *httpMiddleware {
+	m := &httpMiddleware{
+		next:            next,
+		recoverPanics:   true,
+		repanic:         repanic,
+		statusThreshold: DefaultStatusThreshold,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// ServeHTTP reports panics, then either re-raises them or responds with a
+// 500, and reports responses at or above the status threshold.
+func (m *httpMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Buffer the body before the handler consumes it; it is only
+	// parsed and attached if the handler fails
+	body := checkend.BufferRequestBody(r)
+
+	// Set request context
+	request := extractRequest(r)
+	request["path"] = r.URL.Path
+	ctx := checkend.SetRequest(r.Context(), request)
+	state, ok := ctx.Value(reportStateKey{}).(*reportState)
+	if !ok {
+		state = &reportState{}
+		ctx = context.WithValue(ctx, reportStateKey{}, state)
+	}
+	req := r.WithContext(ctx)
+
+	recorder := newStatusRecorder(w)
+
+	// Create a response wrapper to catch panics
+	if m.recoverPanics {
 		defer func() {
 			if err := recover(); err != nil {
-				// Convert panic to error
-				var e error
-				switch v := err.(type) {
-				case error:
-					e = v
-				default:
-					e = fmt.Errorf("panic: %v", v)
+				// A nested Checkend middleware may already have
+				// reported the panic before re-raising it
+				if isAbortPanic(err) {
+					if m.reportAbort && !state.reported {
+						state.reported = true
+						m.report(ctx, req, request, body, panicError(err), checkend.WithPanicStack())
+					}
+					// Let the server abort the response
+					panic(err)
 				}
 
-				checkend.NotifyWithContext(ctx, e)
+				if !state.reported {
+					state.reported = true
+					m.report(ctx, req, request, body, panicError(err), checkend.WithPanicStack())
+				}
 
-				// Re-panic to let the default panic handler respond
-				panic(err)
+				if m.repanic {
+					// Re-panic to let the default panic handler respond
+					panic(err)
+				}
+				// Too late to change the status once the handler has
+				// started the response
+				if !recorder.wroteHeader {
+					http.Error(recorder, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
 			}
 		}()
+	}
 
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
-}
+	m.next.ServeHTTP(recorder, req)
 
-// HTTPMiddlewareFunc wraps an http.HandlerFunc with Checkend error reporting.
-func HTTPMiddlewareFunc(next http.HandlerFunc) http.HandlerFunc {
-	return HTTPMiddleware(next).ServeHTTP
+	if m.statusThreshold <= 0 || state.reported || recorder.hijacked || recorder.status < m.statusThreshold {
+		return
+	}
+	state.reported = true
+	m.report(ctx, req, request, body, &HTTPStatusError{
+		StatusCode: recorder.status,
+		Method:     r.Method,
+		Path:       r.URL.Path,
+	}, checkend.WithContext(map[string]interface{}{
+		"status_code": recorder.status,
+	}))
 }
 
-func extractRequest(r *http.Request) map[string]interface{} {
-	scheme := "http"
-	if r.TLS != nil {
-		scheme = "https"
+// report reports err for req with its request data, the buffered body and
+// the matched route. For panics it must be called from the deferred
+// function that recovered the panic.
+func (m *httpMiddleware) report(ctx context.Context, req *http.Request, request map[string]interface{}, body *checkend.BufferedRequestBody, err error, opts ...checkend.NotifyOption) {
+	// The mux records the matched pattern on the request while routing
+	route := requestPattern(req)
+	if route == "" {
+		route = muxRoute(m.next, req)
 	}
 
-	url := fmt.Sprintf("%s://%s%s", scheme, r.Host, r.RequestURI)
-
-	headers := make(map[string]interface{})
-	for key, values := range r.Header {
-		if len(values) == 1 {
-			headers[key] = values[0]
-		} else {
-			headers[key] = values
+	if body != nil || route != "" {
+		withExtra := make(map[string]interface{}, len(request)+2)
+		for key, value := range request {
+			withExtra[key] = value
+		}
+		if body != nil {
+			withExtra["body"] = body.Value()
 		}
+		if route != "" {
+			withExtra["route"] = route
+		}
+		opts = append(opts, checkend.WithRequest(withExtra))
+	}
+	if route != "" {
+		opts = append(opts, checkend.WithTags("route:"+route))
 	}
 
-	request := map[string]interface{}{
-		"url":     url,
-		"method":  r.Method,
-		"headers": headers,
+	checkend.NotifyWithContext(ctx, err, opts...)
+}
+
+// muxRoute looks up the pattern next routes r to when next is a ServeMux.
+// It is only needed when the mux doesn't set r.Pattern: before Go 1.23, or
+// when the program uses the Go 1.21 mux through GODEBUG=httpmuxgo121=1,
+// which is also the default for modules declaring go 1.21 or earlier.
+func muxRoute(next http.Handler, r *http.Request) string {
+	mux, ok := next.(*http.ServeMux)
+	if !ok {
+		return ""
 	}
+	_, pattern := mux.Handler(r)
+	return pattern
+}
 
-	if r.URL.RawQuery != "" {
-		params := make(map[string]interface{})
-		for key, values := range r.URL.Query() {
-			if len(values) == 1 {
-				params[key] = values[0]
-			} else {
-				params[key] = values
-			}
-		}
-		request["params"] = params
+// HTTPMiddlewareFunc wraps an http.HandlerFunc with Checkend error reporting.
+func HTTPMiddlewareFunc(next http.HandlerFunc, opts ...HTTPMiddlewareOption) http.HandlerFunc {
+	return HTTPMiddleware(next, opts...).ServeHTTP
+}
+
+// SafeHandlerFunc wraps a single handler with panic recovery and error
+// reporting. Unlike HTTPMiddleware it does not re-panic: the panic is
+// reported with the request data and a 500 response is written instead.
+// Error statuses the handler responds with are only reported when
+// WithStatusThreshold is passed.
+//
+// Use it to opt individual routes in when global middleware is not wanted:
+//
+//	mux.HandleFunc("/checkout", integrations.SafeHandlerFunc(checkout))
+//
+// It shares per-request state with HTTPMiddleware, so wrapping it in
+// HTTPMiddleware still reports a panic once.
+func SafeHandlerFunc(h http.HandlerFunc, opts ...HTTPMiddlewareOption) http.HandlerFunc {
+	opts = append([]HTTPMiddlewareOption{WithStatusThreshold(0)}, opts...)
+	return newHTTPMiddleware(h, false, opts).ServeHTTP
+}
+
+// reportsAbort reports whether opts include WithReportAbortHandler.
+func reportsAbort(opts []HTTPMiddlewareOption) bool {
+	var m httpMiddleware
+	for _, opt := range opts {
+		opt(&m)
 	}
+	return m.reportAbort
+}
 
-	return request
+// isAbortPanic reports whether a recovered panic value is
+// http.ErrAbortHandler.
+func isAbortPanic(v interface{}) bool {
+	err, ok := v.(error)
+	return ok && errors.Is(err, http.ErrAbortHandler)
+}
+
+// panicError converts a recovered panic value to an error.
+func panicError(v interface{}) error {
+	if err, ok := v.(error); ok {
+		return err
+	}
+	return fmt.Errorf("panic: %v", v)
+}
+
+func extractRequest(r *http.Request) map[string]interface{} {
+	return checkend.RequestData(r)
 }
@@ -0,0 +1,592 @@
+package integrations
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Checkend/checkend-go"
+)
+
+func TestHTTPMiddlewareCapturesRequestBody(t *testing.T) {
+	enabled := true
+	checkend.SetupTesting()
+	checkend.Configure(checkend.Config{
+		APIKey:             "test-key",
+		Enabled:            &enabled,
+		CaptureRequestBody: true,
+	})
+	t.Cleanup(checkend.Reset)
+
+	var received string
+	handler := HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		panic("invalid order")
+	}))
+
+	body := `{"sku": "A-1", "card_number": "4111111111111111"}`
+	req := httptest.NewRequest("POST", "/orders", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	func() {
+		defer func() { _ = recover() }()
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+
+	if received != body {
+		t.Errorf("Expected handler to read the full body, got %q", received)
+	}
+
+	notice := checkend.TestingLastNotice()
+	if notice == nil {
+		t.Fatal("Expected a notice")
+	}
+	captured, ok := notice.Request["body"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected captured JSON body, got %v", notice.Request["body"])
+	}
+	if captured["sku"] != "A-1" || captured["card_number"] != "[FILTERED]" {
+		t.Errorf("Unexpected captured body %v", captured)
+	}
+}
+
+func TestHTTPMiddlewareFiltersSensitiveHeaders(t *testing.T) {
+	enabled := true
+	checkend.SetupTesting()
+	checkend.Configure(checkend.Config{
+		APIKey:     "test-key",
+		Enabled:    &enabled,
+		FilterKeys: []string{"signature"},
+	})
+	t.Cleanup(checkend.Reset)
+
+	handler := HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	req := httptest.NewRequest("GET", "/account", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Proxy-Authorization", "Basic c2VjcmV0")
+	req.Header.Set("Cookie", "session=abc")
+	req.Header.Set("Set-Cookie", "session=abc")
+	req.Header.Set("X-Api-Key", "key-123")
+	req.Header.Set("X-Shop-Signature", "sig-456")
+	req.Header.Set("Accept", "text/html")
+
+	func() {
+		defer func() { _ = recover() }()
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+
+	notice := checkend.TestingLastNotice()
+	if notice == nil {
+		t.Fatal("Expected a notice")
+	}
+	headers := notice.Request["headers"].(map[string]interface{})
+	for _, name := range []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key", "X-Shop-Signature"} {
+		if headers[name] != "[FILTERED]" {
+			t.Errorf("Expected %s to be filtered, got %v", name, headers[name])
+		}
+	}
+	if headers["Accept"] != "text/html" {
+		t.Errorf("Expected Accept header to be kept, got %v", headers["Accept"])
+	}
+}
+
+func TestHTTPMiddlewareCapturesBodyOnlyOnPanic(t *testing.T) {
+	enabled := true
+	checkend.SetupTesting()
+	checkend.Configure(checkend.Config{
+		APIKey:                    "test-key",
+		Enabled:                   &enabled,
+		CaptureRequestBodyOnError: true,
+	})
+	t.Cleanup(checkend.Reset)
+
+	var received string
+	var requestData map[string]interface{}
+	handler := HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		requestData = checkend.GetContextData(r.Context()).Request
+		if r.URL.Path == "/fail" {
+			panic("invalid order")
+		}
+	}))
+
+	body := `{"sku": "A-1", "password": "hunter2"}`
+	req := httptest.NewRequest("POST", "/ok", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if received != body {
+		t.Errorf("Expected handler to read the full body, got %q", received)
+	}
+	if _, ok := requestData["body"]; ok {
+		t.Error("Expected the body not to be materialized for a successful request")
+	}
+	if checkend.TestingHasNotices() {
+		t.Fatal("Expected no notice for a successful request")
+	}
+
+	req = httptest.NewRequest("POST", "/fail", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	func() {
+		defer func() { _ = recover() }()
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+
+	if received != body {
+		t.Errorf("Expected handler to read the full body, got %q", received)
+	}
+	notice := checkend.TestingLastNotice()
+	if notice == nil {
+		t.Fatal("Expected a notice")
+	}
+	captured, ok := notice.Request["body"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected captured JSON body, got %v", notice.Request["body"])
+	}
+	if captured["sku"] != "A-1" || captured["password"] != "[FILTERED]" {
+		t.Errorf("Unexpected captured body %v", captured)
+	}
+}
+
+func TestSafeHandlerFuncRecoversPanic(t *testing.T) {
+	enabled := true
+	checkend.SetupTesting()
+	checkend.Configure(checkend.Config{
+		APIKey:  "test-key",
+		Enabled: &enabled,
+	})
+	t.Cleanup(checkend.Reset)
+
+	handler := SafeHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("checkout failed")
+	})
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest("GET", "/checkout", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", rec.Code)
+	}
+	notice := checkend.TestingLastNotice()
+	if notice == nil {
+		t.Fatal("Expected a notice")
+	}
+	if notice.Message != "panic: checkout failed" {
+		t.Errorf("Expected panic message, got %q", notice.Message)
+	}
+	if notice.Request["url"] == nil {
+		t.Error("Expected request data to be attached")
+	}
+}
+
+func TestSafeHandlerFuncPassesThrough(t *testing.T) {
+	enabled := true
+	checkend.SetupTesting()
+	checkend.Configure(checkend.Config{
+		APIKey:  "test-key",
+		Enabled: &enabled,
+	})
+	t.Cleanup(checkend.Reset)
+
+	handler := SafeHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest("POST", "/checkout", nil))
+
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("Expected status 202, got %d", rec.Code)
+	}
+	if checkend.TestingHasNotices() {
+		t.Error("Expected no notice for a successful request")
+	}
+}
+
+func TestHTTPMiddlewareCapturesRoutePattern(t *testing.T) {
+	enabled := true
+	checkend.SetupTesting()
+	checkend.Configure(checkend.Config{
+		APIKey:  "test-key",
+		Enabled: &enabled,
+	})
+	t.Cleanup(checkend.Reset)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/items/", func(w http.ResponseWriter, r *http.Request) {
+		panic("item lookup failed")
+	})
+
+	func() {
+		defer func() { _ = recover() }()
+		HTTPMiddleware(mux).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/items/42", nil))
+	}()
+
+	notice := checkend.TestingLastNotice()
+	if notice == nil {
+		t.Fatal("Expected a notice")
+	}
+	if notice.Request["route"] != "/items/" {
+		t.Errorf("Expected route /items/, got %v", notice.Request["route"])
+	}
+	if notice.Request["path"] != "/items/42" {
+		t.Errorf("Expected path /items/42, got %v", notice.Request["path"])
+	}
+	found := false
+	for _, tag := range notice.Tags {
+		if tag == "route:/items/" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected route tag, got %v", notice.Tags)
+	}
+}
+
+func TestHTTPRecoveryMiddlewareWrites500(t *testing.T) {
+	enabled := true
+	checkend.SetupTesting()
+	checkend.Configure(checkend.Config{
+		APIKey:  "test-key",
+		Enabled: &enabled,
+	})
+	t.Cleanup(checkend.Reset)
+
+	handler := HTTPRecoveryMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("checkout failed")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/checkout", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", rec.Code)
+	}
+	notice := checkend.TestingLastNotice()
+	if notice == nil {
+		t.Fatal("Expected a notice")
+	}
+	if notice.Message != "panic: checkout failed" {
+		t.Errorf("Expected panic message, got %q", notice.Message)
+	}
+	if notice.Request["path"] != "/checkout" {
+		t.Errorf("Expected path /checkout, got %v", notice.Request["path"])
+	}
+	if _, ok := notice.Request["route"]; ok {
+		t.Errorf("Expected no route outside a ServeMux, got %v", notice.Request["route"])
+	}
+}
+
+func TestHTTPRecoveryMiddlewareKeepsStartedResponse(t *testing.T) {
+	setupTesting(t)
+
+	handler := HTTPRecoveryMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("partial"))
+		panic("stream failed")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/stream", nil))
+
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("Expected status 202, got %d", rec.Code)
+	}
+	if rec.Body.String() != "partial" {
+		t.Errorf("Expected the partial body only, got %q", rec.Body.String())
+	}
+	if checkend.TestingNoticeCount() != 1 {
+		t.Errorf("Expected 1 notice, got %d", checkend.TestingNoticeCount())
+	}
+}
+
+func TestHTTPMiddlewareReportsServerErrorStatus(t *testing.T) {
+	setupTesting(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orders/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	rec := httptest.NewRecorder()
+	HTTPMiddleware(mux).ServeHTTP(rec, httptest.NewRequest("GET", "/orders/7", nil))
+
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("Expected status 502, got %d", rec.Code)
+	}
+	notice := checkend.TestingLastNotice()
+	if notice == nil {
+		t.Fatal("Expected a notice")
+	}
+	if notice.Message != "GET /orders/7 responded with 502 Bad Gateway" {
+		t.Errorf("Unexpected message %q", notice.Message)
+	}
+	if notice.Context["status_code"] != http.StatusBadGateway {
+		t.Errorf("Expected status_code 502, got %v", notice.Context["status_code"])
+	}
+	if notice.Request["route"] != "/orders/" {
+		t.Errorf("Expected route /orders/, got %v", notice.Request["route"])
+	}
+}
+
+func TestHTTPMiddlewareStatusThreshold(t *testing.T) {
+	setupTesting(t)
+
+	notFound := http.HandlerFunc(http.NotFound)
+	HTTPMiddleware(notFound).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+	if checkend.TestingHasNotices() {
+		t.Fatal("Expected no notice for a 404 with the default threshold")
+	}
+
+	HTTPMiddleware(notFound, WithStatusThreshold(http.StatusBadRequest)).
+		ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+	if checkend.TestingNoticeCount() != 1 {
+		t.Fatalf("Expected a notice for a 404 with threshold 400, got %d", checkend.TestingNoticeCount())
+	}
+
+	failing := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	HTTPMiddleware(failing, WithStatusThreshold(0)).
+		ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if checkend.TestingNoticeCount() != 1 {
+		t.Error("Expected status reporting to be disabled with threshold 0")
+	}
+}
+
+func TestHTTPMiddlewareProxiesResponseWriterInterfaces(t *testing.T) {
+	setupTesting(t)
+
+	var flushed, hijackable bool
+	var pushErr error
+	handler := HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+			flushed = true
+		}
+		_, hijackable = w.(http.Hijacker)
+		if p, ok := w.(http.Pusher); ok {
+			pushErr = p.Push("/app.js", nil)
+		}
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/stream", nil))
+
+	if !flushed || !rec.Flushed {
+		t.Error("Expected Flush to reach the underlying writer")
+	}
+	if !hijackable {
+		t.Error("Expected the writer to implement http.Hijacker")
+	}
+	if pushErr != http.ErrNotSupported {
+		t.Errorf("Expected http.ErrNotSupported from Push, got %v", pushErr)
+	}
+}
+
+func TestHTTPMiddlewareHijack(t *testing.T) {
+	setupTesting(t)
+
+	server := httptest.NewServer(HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("Hijack failed: %v", err)
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 2\r\nConnection: close\r\n\r\nok")
+		rw.Flush()
+	})))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if string(body) != "ok" {
+		t.Errorf("Expected hijacked response body, got %q", body)
+	}
+	if checkend.TestingHasNotices() {
+		t.Error("Expected no notice for a hijacked connection")
+	}
+}
+
+func TestAbortHandlerPanicsAreNotReported(t *testing.T) {
+	setupTesting(t)
+
+	aborting := func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}
+
+	handlers := map[string]http.Handler{
+		"HTTPMiddleware":         HTTPMiddleware(http.HandlerFunc(aborting)),
+		"HTTPRecoveryMiddleware": HTTPRecoveryMiddleware(http.HandlerFunc(aborting)),
+		"SafeHandlerFunc":        SafeHandlerFunc(aborting),
+	}
+	for name, handler := range handlers {
+		var recovered interface{}
+		func() {
+			defer func() { recovered = recover() }()
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/download", nil))
+		}()
+
+		if recovered != http.ErrAbortHandler {
+			t.Errorf("%s: expected http.ErrAbortHandler to be re-raised, got %v", name, recovered)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/download", nil)
+	GinPanicHandler(req, http.ErrAbortHandler)
+	EchoPanicHandler(req, http.ErrAbortHandler)
+
+	if checkend.TestingHasNotices() {
+		t.Errorf("Expected no notices for aborted handlers, got %d", checkend.TestingNoticeCount())
+	}
+}
+
+func TestReportAbortHandler(t *testing.T) {
+	setupTesting(t)
+
+	aborting := func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}
+
+	handlers := map[string]http.Handler{
+		"HTTPMiddleware":         HTTPMiddleware(http.HandlerFunc(aborting), WithReportAbortHandler()),
+		"HTTPRecoveryMiddleware": HTTPRecoveryMiddleware(http.HandlerFunc(aborting), WithReportAbortHandler()),
+		"SafeHandlerFunc":        SafeHandlerFunc(aborting, WithReportAbortHandler()),
+	}
+	for name, handler := range handlers {
+		var recovered interface{}
+		func() {
+			defer func() { recovered = recover() }()
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/download", nil))
+		}()
+
+		if recovered != http.ErrAbortHandler {
+			t.Errorf("%s: expected http.ErrAbortHandler to be re-raised, got %v", name, recovered)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/download", nil)
+	GinPanicHandler(req, http.ErrAbortHandler, WithReportAbortHandler())
+	EchoPanicHandler(req, http.ErrAbortHandler, WithReportAbortHandler())
+
+	if checkend.TestingNoticeCount() != 5 {
+		t.Errorf("Expected every aborted handler to be reported, got %d notices", checkend.TestingNoticeCount())
+	}
+}
+
+//go:noinline
+func panickingHandler(w http.ResponseWriter, r *http.Request) {
+	panic("handler exploded")
+}
+
+func TestPanicHandlersReportPanicStack(t *testing.T) {
+	enabled := true
+	checkend.SetupTesting()
+	checkend.Configure(checkend.Config{
+		APIKey:  "test-key",
+		Enabled: &enabled,
+	})
+	t.Cleanup(checkend.Reset)
+
+	tests := []struct {
+		name  string
+		serve func()
+	}{
+		{"HTTPMiddleware", func() {
+			defer func() { _ = recover() }()
+			HTTPMiddleware(http.HandlerFunc(panickingHandler)).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		}},
+		{"SafeHandlerFunc", func() {
+			SafeHandlerFunc(panickingHandler)(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		}},
+		{"GinPanicHandler", func() {
+			req := httptest.NewRequest("GET", "/", nil)
+			defer func() {
+				if r := recover(); r != nil {
+					GinPanicHandler(req, r)
+				}
+			}()
+			panickingHandler(nil, req)
+		}},
+		{"RiverRecoverHandler", func() {
+			defer RiverRecoverHandler(context.Background(), nil) //nolint:errcheck // The panic is reported, not returned
+			panickingHandler(nil, nil)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkend.TestingClearNotices()
+			tt.serve()
+
+			notice := checkend.TestingLastNotice()
+			if notice == nil {
+				t.Fatal("Expected a notice")
+			}
+			if len(notice.Backtrace) == 0 || !strings.HasSuffix(notice.Backtrace[0], ".panickingHandler") {
+				t.Errorf("Expected backtrace to start at the panicking handler, got %v", notice.Backtrace)
+			}
+		})
+	}
+}
+
+func TestNestedHTTPMiddlewareReportsPanicOnce(t *testing.T) {
+	setupTesting(t)
+
+	panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	func() {
+		defer func() { _ = recover() }()
+		HTTPMiddleware(HTTPMiddleware(panicking)).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}()
+	if checkend.TestingNoticeCount() != 1 {
+		t.Errorf("Expected 1 notice for a re-raised panic, got %d", checkend.TestingNoticeCount())
+	}
+
+	checkend.TestingClearNotices()
+	rec := httptest.NewRecorder()
+	HTTPRecoveryMiddleware(HTTPMiddleware(panicking)).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", rec.Code)
+	}
+	if checkend.TestingNoticeCount() != 1 {
+		t.Errorf("Expected 1 notice for a recovered panic, got %d", checkend.TestingNoticeCount())
+	}
+}
+
+func TestHTTPMiddlewareWithSafeHandlerFuncReportsPanicOnce(t *testing.T) {
+	setupTesting(t)
+
+	handler := HTTPMiddleware(SafeHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/x", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", rec.Code)
+	}
+	if checkend.TestingNoticeCount() != 1 {
+		t.Fatalf("Expected 1 notice, got %d", checkend.TestingNoticeCount())
+	}
+	if notice := checkend.TestingLastNotice(); notice.Message != "panic: boom" {
+		t.Errorf("Expected the panic to be reported, got %q", notice.Message)
+	}
+}
@@ -51,7 +51,7 @@ func MachineryErrorHandler(ctx context.Context, taskName string, err error, opts
 	ctx = checkend.SetContext(ctx, taskCtx)
 
 	allOpts := append([]checkend.NotifyOption{
-		checkend.WithTags("machinery", "background_job"),
+		checkend.WithTags(defaultTags("machinery")...),
 	}, opts...)
 
 	checkend.NotifyWithContext(ctx, err, allOpts...)
@@ -77,7 +77,7 @@ func MachineryErrorHandlerWithSignature(ctx context.Context, sig *MachinerySigna
 	ctx = checkend.SetContext(ctx, taskCtx)
 
 	allOpts := append([]checkend.NotifyOption{
-		checkend.WithTags("machinery", "background_job"),
+		checkend.WithTags(defaultTags("machinery")...),
 	}, opts...)
 
 	checkend.NotifyWithContext(ctx, err, allOpts...)
@@ -100,7 +100,7 @@ func MachineryOnTaskFailure() func(signature interface{}, err error) {
 		ctx = checkend.SetContext(ctx, taskCtx)
 
 		checkend.NotifyWithContext(ctx, err,
-			checkend.WithTags("machinery", "background_job", "task_failure"),
+			checkend.WithTags(defaultTags("machinery", "task_failure")...),
 		)
 	}
 }
@@ -117,7 +117,7 @@ func MachineryOnTaskSuccessWithError() func(signature interface{}, results []int
 				ctx = checkend.SetContext(ctx, taskCtx)
 
 				checkend.NotifyWithContext(ctx, err,
-					checkend.WithTags("machinery", "background_job", "task_error_result"),
+					checkend.WithTags(defaultTags("machinery", "task_error_result")...),
 				)
 			}
 		}
@@ -143,7 +143,7 @@ func MachineryPanicHandler(taskName string) {
 			err = fmt.Errorf("panic in machinery task: %v", v)
 		}
 
-		MachineryErrorHandler(context.Background(), taskName, err)
+		MachineryErrorHandler(context.Background(), taskName, err, checkend.WithPanicStack())
 		panic(r) // Re-panic to let Machinery handle retry logic
 	}
 }
@@ -159,7 +159,7 @@ func MachineryRecoverHandler(taskName string) error {
 			err = fmt.Errorf("panic in machinery task: %v", v)
 		}
 
-		MachineryErrorHandler(context.Background(), taskName, err)
+		MachineryErrorHandler(context.Background(), taskName, err, checkend.WithPanicStack())
 		return err
 	}
 	return nil
@@ -0,0 +1,69 @@
+package prometheus
+
+import (
+	"github.com/Checkend/checkend-go"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector is a prometheus.Collector that reports the counters from
+// checkend.Stats. Notice counters are labelled by environment and
+// severity; the retry counter and queue depth gauge by environment only.
+type Collector struct {
+	queued     *prom.Desc
+	sent       *prom.Desc
+	failed     *prom.Desc
+	dropped    *prom.Desc
+	retried    *prom.Desc
+	queueDepth *prom.Desc
+}
+
+// NewCollector creates a Collector. Register it with your registry:
+//
+//	prometheus.MustRegister(checkendprom.NewCollector())
+func NewCollector() *Collector {
+	labels := []string{"environment", "severity"}
+	return &Collector{
+		queued: prom.NewDesc("checkend_notices_queued_total",
+			"Notices added to the async queue.", labels, nil),
+		sent: prom.NewDesc("checkend_notices_sent_total",
+			"Notices delivered to Checkend.", labels, nil),
+		failed: prom.NewDesc("checkend_notices_failed_total",
+			"Notices that could not be delivered after every retry.", labels, nil),
+		dropped: prom.NewDesc("checkend_notices_dropped_total",
+			"Notices discarded without being sent.", labels, nil),
+		retried: prom.NewDesc("checkend_send_retries_total",
+			"Notice send attempts that were retries.", []string{"environment"}, nil),
+		queueDepth: prom.NewDesc("checkend_queue_depth",
+			"Notices currently waiting in the queue.", []string{"environment"}, nil),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prom.Desc) {
+	ch <- c.queued
+	ch <- c.sent
+	ch <- c.failed
+	ch <- c.dropped
+	ch <- c.retried
+	ch <- c.queueDepth
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prom.Metric) {
+	stats := checkend.Stats()
+	environment := ""
+	if cfg := checkend.GetConfiguration(); cfg != nil {
+		environment = cfg.Environment
+	}
+
+	for severity, s := range stats.BySeverity {
+		ch <- prom.MustNewConstMetric(c.queued, prom.CounterValue, float64(s.Queued), environment, severity)
+		ch <- prom.MustNewConstMetric(c.sent, prom.CounterValue, float64(s.Sent), environment, severity)
+		ch <- prom.MustNewConstMetric(c.failed, prom.CounterValue, float64(s.Failed), environment, severity)
+		ch <- prom.MustNewConstMetric(c.dropped, prom.CounterValue, float64(s.Dropped), environment, severity)
+	}
+	ch <- prom.MustNewConstMetric(c.retried, prom.CounterValue, float64(stats.Retried), environment)
+	ch <- prom.MustNewConstMetric(c.queueDepth, prom.GaugeValue, float64(stats.CurrentQueueDepth), environment)
+}
+
+var _ prom.Collector = (*Collector)(nil)
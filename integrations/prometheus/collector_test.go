@@ -0,0 +1,44 @@
+package prometheus
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Checkend/checkend-go"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	enabled := true
+	checkend.Configure(checkend.Config{
+		APIKey:      "test-key",
+		Enabled:     &enabled,
+		Environment: "production",
+		Transport:   checkend.NewTestTransport(),
+	})
+	t.Cleanup(checkend.Reset)
+
+	checkend.NotifySync(errors.New("default"))
+	checkend.NotifySync(errors.New("warning"), checkend.WithSeverity("warning"))
+
+	expected := `
+# HELP checkend_notices_sent_total Notices delivered to Checkend.
+# TYPE checkend_notices_sent_total counter
+checkend_notices_sent_total{environment="production",severity="error"} 1
+checkend_notices_sent_total{environment="production",severity="warning"} 1
+`
+	if err := testutil.CollectAndCompare(NewCollector(), strings.NewReader(expected), "checkend_notices_sent_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollectorLints(t *testing.T) {
+	problems, err := testutil.CollectAndLint(NewCollector())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range problems {
+		t.Errorf("%s: %s", p.Metric, p.Text)
+	}
+}
@@ -0,0 +1,14 @@
+// Package prometheus exposes Checkend delivery statistics as Prometheus
+// metrics.
+//
+// It is a separate module so the core SDK doesn't depend on the Prometheus
+// client:
+//
+//	go get github.com/Checkend/checkend-go/integrations/prometheus
+//
+// Usage:
+//
+//	import checkendprom "github.com/Checkend/checkend-go/integrations/prometheus"
+//
+//	prometheus.MustRegister(checkendprom.NewCollector())
+package prometheus
@@ -0,0 +1,23 @@
+module github.com/Checkend/checkend-go/integrations/prometheus
+
+go 1.21
+
+require (
+	github.com/Checkend/checkend-go v0.2.0
+	github.com/prometheus/client_golang v1.19.1
+)
+
+require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/prometheus/client_model v0.5.0 // indirect
+	github.com/prometheus/common v0.48.0 // indirect
+	github.com/prometheus/procfs v0.12.0 // indirect
+	golang.org/x/sys v0.17.0 // indirect
+	google.golang.org/protobuf v1.33.0 // indirect
+)
+
+// Build against the core module in this repository during development.
+// Consumers ignore replace directives and get the required release.
+replace github.com/Checkend/checkend-go => ../..
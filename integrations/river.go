@@ -51,7 +51,7 @@ func RiverErrorHandler(ctx context.Context, job interface{}, err error, opts ...
 	ctx = checkend.SetContext(ctx, jobCtx)
 
 	allOpts := append([]checkend.NotifyOption{
-		checkend.WithTags("river", "background_job"),
+		checkend.WithTags(defaultTags("river")...),
 	}, opts...)
 
 	checkend.NotifyWithContext(ctx, err, allOpts...)
@@ -79,7 +79,7 @@ func RiverErrorHandlerWithRow(ctx context.Context, row *RiverJobRow, err error,
 	ctx = checkend.SetContext(ctx, jobCtx)
 
 	allOpts := append([]checkend.NotifyOption{
-		checkend.WithTags("river", "background_job"),
+		checkend.WithTags(defaultTags("river")...),
 	}, opts...)
 
 	checkend.NotifyWithContext(ctx, err, allOpts...)
@@ -104,7 +104,7 @@ func RiverPanicHandler(ctx context.Context, job interface{}) {
 			err = fmt.Errorf("panic in river job: %v", v)
 		}
 
-		RiverErrorHandler(ctx, job, err)
+		RiverErrorHandler(ctx, job, err, checkend.WithPanicStack())
 		panic(r) // Re-panic to let River handle retry logic
 	}
 }
@@ -121,7 +121,7 @@ func RiverRecoverHandler(ctx context.Context, job interface{}) error {
 			err = fmt.Errorf("panic in river job: %v", v)
 		}
 
-		RiverErrorHandler(ctx, job, err)
+		RiverErrorHandler(ctx, job, err, checkend.WithPanicStack())
 		return err
 	}
 	return nil
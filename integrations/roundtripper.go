@@ -0,0 +1,124 @@
+package integrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Checkend/checkend-go"
+)
+
+// OutboundHTTPError is reported when an outbound request receives a
+// response status that triggers a report.
+type OutboundHTTPError struct {
+	StatusCode int
+	Method     string
+	URL        string
+}
+
+func (e *OutboundHTTPError) Error() string {
+	return fmt.Sprintf("%s %s responded with %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// RoundTripperOption customizes a RoundTripper created by NewRoundTripper.
+type RoundTripperOption func(*roundTripper)
+
+// WithReportStatus sets which response status codes are reported. The
+// default reports 5xx responses.
+func WithReportStatus(report func(statusCode int) bool) RoundTripperOption {
+	return func(rt *roundTripper) {
+		rt.reportStatus = report
+	}
+}
+
+type roundTripper struct {
+	next         http.RoundTripper
+	reportStatus func(statusCode int) bool
+}
+
+// NewRoundTripper wraps next, reporting outbound requests that fail with a
+// transport error or a 5xx response. Notices are tagged outbound_http and
+// carry the method, status code, and target URL, with credentials removed
+// and query values sanitized like other notice data. Requests cancelled by
+// their context are not reported. The response and error are returned to
+// the caller unchanged. A nil next uses http.DefaultTransport.
+//
+// Usage:
+//
+//	client := &http.Client{Transport: integrations.NewRoundTripper(nil)}
+func NewRoundTripper(next http.RoundTripper, opts ...RoundTripperOption) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	rt := &roundTripper{
+		next: next,
+		reportStatus: func(statusCode int) bool {
+			return statusCode >= http.StatusInternalServerError
+		},
+	}
+	for _, opt := range opts {
+		opt(rt)
+	}
+	return rt
+}
+
+// RoundTrip implements http.RoundTripper.
+func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rt.next.RoundTrip(req)
+
+	switch {
+	case err != nil:
+		if !errors.Is(err, context.Canceled) {
+			rt.report(req, err, 0)
+		}
+	case rt.reportStatus(resp.StatusCode):
+		target, _ := outboundURL(req.URL)
+		rt.report(req, &OutboundHTTPError{
+			StatusCode: resp.StatusCode,
+			Method:     req.Method,
+			URL:        target,
+		}, resp.StatusCode)
+	}
+
+	return resp, err
+}
+
+func (rt *roundTripper) report(req *http.Request, err error, statusCode int) {
+	target, query := outboundURL(req.URL)
+	outbound := map[string]interface{}{
+		"method": req.Method,
+		"url":    target,
+	}
+	if len(query) > 0 {
+		outbound["query"] = query
+	}
+	if statusCode != 0 {
+		outbound["status_code"] = statusCode
+	}
+
+	ctx := checkend.SetContext(req.Context(), map[string]interface{}{"outbound_http": outbound})
+	checkend.NotifyWithContext(ctx, err, checkend.WithTags("outbound_http"))
+}
+
+// outboundURL returns u without credentials, query, or fragment, and its
+// query parameters sanitized with the configured filter keys.
+func outboundURL(u *url.URL) (string, map[string]interface{}) {
+	clean := *u
+	clean.User = nil
+	clean.RawQuery = ""
+	clean.ForceQuery = false
+	clean.Fragment = ""
+
+	values := u.Query()
+	if len(values) == 0 {
+		return clean.String(), nil
+	}
+	query := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		query[key] = strings.Join(value, ",")
+	}
+	return clean.String(), checkend.Sanitize(query)
+}
@@ -0,0 +1,11 @@
+//go:build go1.23
+
+package integrations
+
+import "net/http"
+
+// requestPattern returns the ServeMux pattern that matched r, set by the
+// mux while routing.
+func requestPattern(r *http.Request) string {
+	return r.Pattern
+}
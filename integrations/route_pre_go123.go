@@ -0,0 +1,10 @@
+//go:build !go1.23
+
+package integrations
+
+import "net/http"
+
+// requestPattern returns "": http.Request.Pattern requires Go 1.23.
+func requestPattern(r *http.Request) string {
+	return ""
+}
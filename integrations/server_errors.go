@@ -0,0 +1,107 @@
+package integrations
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// HTTPStatusError is reported when a handler responds with a server error
+// status without surfacing an error value.
+type HTTPStatusError struct {
+	StatusCode int
+	Method     string
+	Path       string
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("%s %s responded with %d %s", e.Method, e.Path, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// ReportServerErrors returns middleware that reports a notice for every 5xx
+// response written by the wrapped handler, even when no panic occurred and
+// no error was returned. It is HTTPMiddleware without panic handling, for
+// handlers whose panics are recovered elsewhere. When both are installed,
+// each failed request is still reported only once.
+//
+// Usage:
+//
+//	handler := integrations.ReportServerErrors()(mux)
+//	http.ListenAndServe(":8080", handler)
+func ReportServerErrors() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		m := newHTTPMiddleware(next, true, nil)
+		m.recoverPanics = false
+		return m
+	}
+}
+
+// statusRecorder wraps an http.ResponseWriter to observe the response
+// status. It proxies Flush, Hijack and Push to the underlying writer so
+// streaming, websockets and server push keep working.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	hijacked    bool
+}
+
+func newStatusRecorder(w http.ResponseWriter) *statusRecorder {
+	return &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (w *statusRecorder) WriteHeader(code int) {
+	// Informational responses may precede the final status
+	if !w.wroteHeader && code >= http.StatusOK {
+		w.status = code
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher. It does nothing if the underlying writer
+// can't flush.
+func (w *statusRecorder) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if !w.wroteHeader {
+			w.wroteHeader = true
+		}
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker, returning http.ErrNotSupported if the
+// underlying writer can't be hijacked. Hijacked responses aren't reported.
+func (w *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		w.hijacked = true
+	}
+	return conn, rw, err
+}
+
+// Push implements http.Pusher, returning http.ErrNotSupported if the
+// underlying writer doesn't support server push.
+func (w *statusRecorder) Push(target string, opts *http.PushOptions) error {
+	if p, ok := w.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// Unwrap returns the underlying ResponseWriter for use with http.ResponseController.
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
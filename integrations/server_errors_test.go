@@ -0,0 +1,112 @@
+package integrations
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Checkend/checkend-go"
+)
+
+func setupTesting(t *testing.T) {
+	t.Helper()
+	enabled := true
+	checkend.SetupTesting()
+	checkend.Configure(checkend.Config{
+		APIKey:  "test-key",
+		Enabled: &enabled,
+	})
+	t.Cleanup(checkend.Reset)
+}
+
+func TestReportServerErrors(t *testing.T) {
+	setupTesting(t)
+
+	handler := ReportServerErrors()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/health", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", rec.Code)
+	}
+
+	if checkend.TestingNoticeCount() != 1 {
+		t.Fatalf("Expected 1 notice, got %d", checkend.TestingNoticeCount())
+	}
+
+	notice := checkend.TestingLastNotice()
+	if notice.Context["status_code"] != http.StatusServiceUnavailable {
+		t.Errorf("Expected status_code 503, got %v", notice.Context["status_code"])
+	}
+	if notice.Request["method"] != "GET" {
+		t.Errorf("Expected request method 'GET', got %v", notice.Request["method"])
+	}
+	if notice.Message != "GET /health responded with 503 Service Unavailable" {
+		t.Errorf("Unexpected message '%s'", notice.Message)
+	}
+}
+
+func TestReportServerErrorsIgnoresSuccess(t *testing.T) {
+	setupTesting(t)
+
+	handler := ReportServerErrors()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	if checkend.TestingHasNotices() {
+		t.Error("Expected no notices for a successful response")
+	}
+}
+
+func TestReportServerErrorsIgnoresClientErrors(t *testing.T) {
+	setupTesting(t)
+
+	handler := ReportServerErrors()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+
+	if checkend.TestingHasNotices() {
+		t.Error("Expected no notices for a 4xx response")
+	}
+}
+
+func TestReportServerErrorsWithHTTPMiddlewareReportsOnce(t *testing.T) {
+	setupTesting(t)
+
+	failing := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	handler := ReportServerErrors()(HTTPMiddleware(failing))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/upstream", nil))
+
+	if checkend.TestingNoticeCount() != 1 {
+		t.Errorf("Expected 1 notice, got %d", checkend.TestingNoticeCount())
+	}
+}
+
+func TestReportServerErrorsDoesNotReportRecoveredPanicTwice(t *testing.T) {
+	setupTesting(t)
+
+	panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	handler := ReportServerErrors()(HTTPRecoveryMiddleware(panicking))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", rec.Code)
+	}
+	if checkend.TestingNoticeCount() != 1 {
+		t.Errorf("Expected 1 notice, got %d", checkend.TestingNoticeCount())
+	}
+}
@@ -0,0 +1,121 @@
+package integrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Checkend/checkend-go"
+)
+
+// SQLIgnoredErrors are the errors ReportSQLError does not report, matched
+// with errors.Is. They are expected outcomes rather than failures.
+var SQLIgnoredErrors = []error{sql.ErrNoRows}
+
+// SQLSendPositionalArgs makes ReportSQLError send positional args as they
+// are. They have no name to match against the filter keys, so by default
+// their values are replaced with the filter placeholder, in case one of
+// them is a password or token.
+var SQLSendPositionalArgs = false
+
+// ReportSQLError reports a database error with the query that caused it.
+// Errors in SQLIgnoredErrors and context cancellations are skipped. The
+// query is attached with its literals replaced and its placeholders kept.
+// sql.NamedArg values are sanitized by name like other notice data;
+// positional args are redacted unless SQLSendPositionalArgs is set.
+// Notices are tagged database.
+//
+// Usage:
+//
+//	rows, err := db.QueryContext(ctx, query, args...)
+//	if err != nil {
+//		integrations.ReportSQLError(ctx, query, args, err)
+//		return err
+//	}
+func ReportSQLError(ctx context.Context, query string, args []interface{}, err error, opts ...checkend.NotifyOption) {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+	for _, ignored := range SQLIgnoredErrors {
+		if errors.Is(err, ignored) {
+			return
+		}
+	}
+
+	sqlCtx := map[string]interface{}{
+		"query": NormalizeSQL(query),
+	}
+	if len(args) > 0 {
+		sqlCtx["args"] = sanitizeSQLArgs(args)
+	}
+	ctx = checkend.SetContext(ctx, map[string]interface{}{"sql": sqlCtx})
+
+	allOpts := append([]checkend.NotifyOption{
+		checkend.WithTags("database"),
+	}, opts...)
+
+	checkend.NotifyWithContext(ctx, err, allOpts...)
+}
+
+// sqlTokens matches string and numeric literals and the placeholder styles
+// of common drivers ($1, ?, :name, @name), so placeholders can be kept
+// while literals are replaced. Strings may be single- or double-quoted, as
+// MySQL allows, with doubled quotes or backslashes escaping the quote.
+var sqlTokens = regexp.MustCompile(`'(?:[^'\\]|''|\\.)*'|"(?:[^"\\]|""|\\.)*"|\$\d+|[:@]\w+|\b\d+(?:\.\d+)?\b`)
+
+// NormalizeSQL replaces the string and numeric literals in query with
+// placeholders and collapses whitespace, keeping bind parameters such as
+// $1, ?, :name, and @name intact. Double-quoted text is treated as a
+// string literal, as in MySQL, so quoted identifiers are replaced too.
+func NormalizeSQL(query string) string {
+	normalized := sqlTokens.ReplaceAllStringFunc(query, func(token string) string {
+		switch token[0] {
+		case '\'':
+			return "'<string>'"
+		case '"':
+			return `"<string>"`
+		case '$', ':', '@':
+			return token
+		default:
+			return "<n>"
+		}
+	})
+	return strings.Join(strings.Fields(normalized), " ")
+}
+
+// sanitizeSQLArgs keys args by name for sql.NamedArg and by position
+// otherwise, then sanitizes them. Positional values are redacted unless
+// SQLSendPositionalArgs is set.
+func sanitizeSQLArgs(args []interface{}) map[string]interface{} {
+	keyed := make(map[string]interface{}, len(args))
+	var positions []string
+	for i, arg := range args {
+		if named, ok := arg.(sql.NamedArg); ok && named.Name != "" {
+			keyed[named.Name] = named.Value
+			continue
+		}
+		position := strconv.Itoa(i + 1)
+		keyed[position] = arg
+		positions = append(positions, position)
+	}
+
+	sanitized := checkend.Sanitize(keyed)
+	if !SQLSendPositionalArgs {
+		placeholder := filterPlaceholder()
+		for _, position := range positions {
+			sanitized[position] = placeholder
+		}
+	}
+	return sanitized
+}
+
+// filterPlaceholder returns the configured placeholder for filtered values.
+func filterPlaceholder() string {
+	if config := checkend.GetConfiguration(); config != nil && config.FilterPlaceholder != "" {
+		return config.FilterPlaceholder
+	}
+	return "[FILTERED]"
+}
@@ -0,0 +1,96 @@
+package integrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Checkend/checkend-go"
+)
+
+func TestNormalizeSQL(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"SELECT * FROM users WHERE id = $1", "SELECT * FROM users WHERE id = $1"},
+		{"SELECT * FROM users WHERE email = 'ada@example.com' AND age > 36", "SELECT * FROM users WHERE email = '<string>' AND age > <n>"},
+		{"UPDATE orders SET note = 'it''s late' WHERE id = ?", "UPDATE orders SET note = '<string>' WHERE id = ?"},
+		{"SELECT name FROM t1\n\tWHERE id = :id OR id = @p2 LIMIT 10", "SELECT name FROM t1 WHERE id = :id OR id = @p2 LIMIT <n>"},
+		{`SELECT * FROM users WHERE password = "hunter2" OR note = "say ""hi"""`, `SELECT * FROM users WHERE password = "<string>" OR note = "<string>"`},
+		{`UPDATE users SET bio = 'it\'s "mine"' WHERE token = "a\"b"`, `UPDATE users SET bio = '<string>' WHERE token = "<string>"`},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeSQL(tt.query); got != tt.expected {
+			t.Errorf("NormalizeSQL(%q) = %q, expected %q", tt.query, got, tt.expected)
+		}
+	}
+}
+
+func TestReportSQLError(t *testing.T) {
+	setupTesting(t)
+
+	err := errors.New("pq: duplicate key value violates unique constraint")
+	ReportSQLError(context.Background(),
+		"INSERT INTO users (email, password) VALUES ($1, $2) -- batch 7",
+		[]interface{}{"ada@example.com", sql.Named("password", "hunter2")},
+		err,
+	)
+
+	notice := checkend.TestingLastNotice()
+	if notice == nil {
+		t.Fatal("Expected a notice")
+	}
+	if len(notice.Tags) != 1 || notice.Tags[0] != "database" {
+		t.Errorf("Expected database tag, got %v", notice.Tags)
+	}
+
+	sqlCtx := notice.Context["sql"].(map[string]interface{})
+	if sqlCtx["query"] != "INSERT INTO users (email, password) VALUES ($1, $2) -- batch <n>" {
+		t.Errorf("Expected normalized query, got %v", sqlCtx["query"])
+	}
+	args := sqlCtx["args"].(map[string]interface{})
+	if args["1"] != "[FILTERED]" {
+		t.Errorf("Expected positional arg to be redacted, got %v", args["1"])
+	}
+	if args["password"] != "[FILTERED]" {
+		t.Errorf("Expected named password arg to be filtered, got %v", args["password"])
+	}
+}
+
+func TestReportSQLErrorSendPositionalArgs(t *testing.T) {
+	setupTesting(t)
+	SQLSendPositionalArgs = true
+	t.Cleanup(func() { SQLSendPositionalArgs = false })
+
+	ReportSQLError(context.Background(),
+		"SELECT * FROM users WHERE email = $1 AND password = @password",
+		[]interface{}{"ada@example.com", sql.Named("password", "hunter2")},
+		errors.New("query failed"),
+	)
+
+	args := checkend.TestingLastNotice().Context["sql"].(map[string]interface{})["args"].(map[string]interface{})
+	if args["1"] != "ada@example.com" {
+		t.Errorf("Expected positional arg to be sent, got %v", args["1"])
+	}
+	if args["password"] != "[FILTERED]" {
+		t.Errorf("Expected named password arg to still be filtered, got %v", args["password"])
+	}
+}
+
+func TestReportSQLErrorSkipsExpectedErrors(t *testing.T) {
+	setupTesting(t)
+
+	ctx := context.Background()
+	ReportSQLError(ctx, "SELECT 1", nil, nil)
+	ReportSQLError(ctx, "SELECT 1", nil, sql.ErrNoRows)
+	ReportSQLError(ctx, "SELECT 1", nil, fmt.Errorf("find user: %w", sql.ErrNoRows))
+	ReportSQLError(ctx, "SELECT 1", nil, fmt.Errorf("query: %w", context.Canceled))
+
+	if checkend.TestingHasNotices() {
+		t.Errorf("Expected no notices, got %d", checkend.TestingNoticeCount())
+	}
+}
@@ -0,0 +1,44 @@
+package integrations
+
+import "sync"
+
+// builtinTags are the tags each integration attaches to its notices unless
+// overridden with SetDefaultTags.
+var builtinTags = map[string][]string{
+	"asynq":     {"asynq", "background_job"},
+	"machinery": {"machinery", "background_job"},
+	"river":     {"river", "background_job"},
+}
+
+var (
+	customTags   = map[string][]string{}
+	customTagsMu sync.RWMutex
+)
+
+// SetDefaultTags replaces the tags an integration ("asynq", "machinery", or
+// "river") attaches to every notice it reports. Pass an empty slice to send
+// no default tags, or nil to restore the built-in tags. As before,
+// checkend.WithTags passed to a handler overrides them for that call.
+func SetDefaultTags(integration string, tags []string) {
+	customTagsMu.Lock()
+	defer customTagsMu.Unlock()
+
+	if tags == nil {
+		delete(customTags, integration)
+		return
+	}
+	customTags[integration] = append([]string{}, tags...)
+}
+
+// defaultTags returns the default tags for an integration followed by any
+// event-specific tags.
+func defaultTags(integration string, extra ...string) []string {
+	customTagsMu.RLock()
+	tags, ok := customTags[integration]
+	customTagsMu.RUnlock()
+
+	if !ok {
+		tags = builtinTags[integration]
+	}
+	return append(append([]string{}, tags...), extra...)
+}
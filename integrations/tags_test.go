@@ -0,0 +1,63 @@
+package integrations
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Checkend/checkend-go"
+)
+
+type fakeAsynqTask struct{}
+
+func (fakeAsynqTask) Type() string    { return "email:send" }
+func (fakeAsynqTask) Payload() []byte { return []byte(`{}`) }
+
+func TestSetDefaultTagsReplacesBuiltins(t *testing.T) {
+	setupTesting(t)
+	SetDefaultTags("asynq", []string{"jobs", "team-platform"})
+	t.Cleanup(func() { SetDefaultTags("asynq", nil) })
+
+	AsynqErrorHandler(context.Background(), fakeAsynqTask{}, errors.New("smtp down"))
+
+	notice := checkend.TestingLastNotice()
+	if notice == nil {
+		t.Fatal("Expected a notice")
+	}
+	if expected := []string{"jobs", "team-platform"}; !reflect.DeepEqual(notice.Tags, expected) {
+		t.Errorf("Expected tags %v, got %v", expected, notice.Tags)
+	}
+}
+
+func TestSetDefaultTagsKeepsEventTags(t *testing.T) {
+	setupTesting(t)
+	SetDefaultTags("machinery", []string{})
+	t.Cleanup(func() { SetDefaultTags("machinery", nil) })
+
+	MachineryOnTaskFailure()(nil, errors.New("task failed"))
+
+	notice := checkend.TestingLastNotice()
+	if notice == nil {
+		t.Fatal("Expected a notice")
+	}
+	if expected := []string{"task_failure"}; !reflect.DeepEqual(notice.Tags, expected) {
+		t.Errorf("Expected tags %v, got %v", expected, notice.Tags)
+	}
+}
+
+func TestSetDefaultTagsNilRestoresBuiltins(t *testing.T) {
+	setupTesting(t)
+	SetDefaultTags("river", []string{"custom"})
+	SetDefaultTags("river", nil)
+
+	RiverErrorHandler(context.Background(), nil, errors.New("job failed"))
+
+	notice := checkend.TestingLastNotice()
+	if notice == nil {
+		t.Fatal("Expected a notice")
+	}
+	if expected := []string{"river", "background_job"}; !reflect.DeepEqual(notice.Tags, expected) {
+		t.Errorf("Expected tags %v, got %v", expected, notice.Tags)
+	}
+}
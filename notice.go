@@ -1,6 +1,8 @@
 package checkend
 
 import (
+	"context"
+	"fmt"
 	"time"
 )
 
@@ -9,17 +11,103 @@ type Notice struct {
 	ErrorClass  string                 `json:"error_class"`
 	Message     string                 `json:"message"`
 	Backtrace   []string               `json:"backtrace"`
+	Frames      []BacktraceFrame       `json:"frames,omitempty"`
 	Fingerprint string                 `json:"fingerprint,omitempty"`
 	Tags        []string               `json:"tags,omitempty"`
+	Severity    string                 `json:"severity,omitempty"`
+	Occurrences int                    `json:"occurrences,omitempty"`
+	Causes      []Cause                `json:"causes,omitempty"`
+	Source      *SourceLocation        `json:"source,omitempty"`
 	Context     map[string]interface{} `json:"context,omitempty"`
 	Request     map[string]interface{} `json:"request,omitempty"`
 	User        map[string]interface{} `json:"user,omitempty"`
+	Breadcrumbs []Breadcrumb           `json:"breadcrumbs,omitempty"`
+	Session     string                 `json:"session,omitempty"`
 	Environment string                 `json:"environment"`
 	OccurredAt  time.Time              `json:"occurred_at"`
 	Notifier    NotifierInfo           `json:"notifier"`
+	Client      *ClientInfo            `json:"client,omitempty"`
 	AppName     string                 `json:"app_name,omitempty"`
 	Revision    string                 `json:"revision,omitempty"`
 	Hostname    string                 `json:"hostname,omitempty"`
+
+	// ctx is the context the notice was reported with, kept when
+	// PropagateContext is enabled.
+	ctx context.Context
+
+	// delivery overrides AsyncSend for this notice.
+	delivery deliveryMode
+}
+
+// deliveryMode selects how Notify delivers a notice.
+type deliveryMode int
+
+const (
+	deliveryDefault deliveryMode = iota
+	deliverySync
+	deliveryAsync
+)
+
+// frames returns the structured backtrace, parsing Backtrace for notices
+// built without frames, such as those restored from an older spool.
+func (n *Notice) frames() []BacktraceFrame {
+	if n.Frames != nil {
+		return n.Frames
+	}
+	frames := make([]BacktraceFrame, 0, len(n.Backtrace))
+	for _, line := range n.Backtrace {
+		frames = append(frames, parseBacktraceLine(line))
+	}
+	return frames
+}
+
+// sendContext returns the context to send the notice with.
+func (n *Notice) sendContext() context.Context {
+	if n.ctx != nil {
+		return n.ctx
+	}
+	return context.Background()
+}
+
+// abandoned reports whether the context the notice was reported with is done.
+func (n *Notice) abandoned() bool {
+	return n.ctx != nil && n.ctx.Err() != nil
+}
+
+// Cause describes an underlying error attached to a notice.
+type Cause struct {
+	Class     string   `json:"class"`
+	Message   string   `json:"message"`
+	Backtrace []string `json:"backtrace,omitempty"`
+}
+
+// BacktraceFrame is a structured backtrace frame. InApp reports whether the
+// frame is application code: with AppPackages set, whether its function is
+// in one of them; with InAppByPath set, whether its file is outside the
+// standard library, the module cache, and vendor directories. Otherwise it
+// is false.
+//
+// PreContext, ContextLine, and PostContext hold the source around Line when
+// SourceContextLines is set.
+type BacktraceFrame struct {
+	File        string   `json:"file"`
+	Line        int      `json:"line"`
+	Function    string   `json:"function"`
+	InApp       bool     `json:"in_app"`
+	PreContext  []string `json:"pre_context,omitempty"`
+	ContextLine string   `json:"context_line,omitempty"`
+	PostContext []string `json:"post_context,omitempty"`
+}
+
+// String formats the frame as a "file:line in function" backtrace line.
+func (f BacktraceFrame) String() string {
+	return fmt.Sprintf("%s:%d in %s", f.File, f.Line, f.Function)
+}
+
+// SourceLocation is the logical place an error was reported from.
+type SourceLocation struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
 }
 
 // NotifierInfo contains SDK metadata.
@@ -30,6 +118,12 @@ type NotifierInfo struct {
 	LanguageVersion string `json:"language_version"`
 }
 
+// ClientInfo identifies the product reporting through the SDK.
+type ClientInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
 // ServerInfo contains server/application metadata.
 type ServerInfo struct {
 	AppName  string `json:"app_name,omitempty"`
@@ -37,24 +131,39 @@ type ServerInfo struct {
 	Hostname string `json:"hostname,omitempty"`
 }
 
+// SessionInfo identifies the user session a notice occurred in.
+type SessionInfo struct {
+	ID string `json:"id"`
+}
+
 // Payload represents the API request payload.
 type Payload struct {
-	Error    ErrorPayload           `json:"error"`
-	Context  map[string]interface{} `json:"context"`
-	Request  map[string]interface{} `json:"request,omitempty"`
-	User     map[string]interface{} `json:"user,omitempty"`
-	Notifier NotifierInfo           `json:"notifier"`
-	Server   *ServerInfo            `json:"server,omitempty"`
+	Error       ErrorPayload           `json:"error"`
+	Context     map[string]interface{} `json:"context"`
+	Request     map[string]interface{} `json:"request,omitempty"`
+	User        map[string]interface{} `json:"user,omitempty"`
+	Breadcrumbs []Breadcrumb           `json:"breadcrumbs,omitempty"`
+	Session     *SessionInfo           `json:"session,omitempty"`
+	Notifier    NotifierInfo           `json:"notifier"`
+	Client      *ClientInfo            `json:"client,omitempty"`
+	Server      *ServerInfo            `json:"server,omitempty"`
 }
 
-// ErrorPayload represents the error portion of the payload.
+// ErrorPayload represents the error portion of the payload. LegacyBacktrace
+// holds the backtrace as "file:line in function" strings for servers that
+// don't read structured frames yet; it will be removed in a future release.
 type ErrorPayload struct {
-	Class       string   `json:"class"`
-	Message     string   `json:"message"`
-	Backtrace   []string `json:"backtrace"`
-	Fingerprint string   `json:"fingerprint,omitempty"`
-	Tags        []string `json:"tags,omitempty"`
-	OccurredAt  string   `json:"occurred_at"`
+	Class           string           `json:"class"`
+	Message         string           `json:"message"`
+	Backtrace       []BacktraceFrame `json:"backtrace"`
+	LegacyBacktrace []string         `json:"legacy_backtrace"`
+	Fingerprint     string           `json:"fingerprint,omitempty"`
+	Tags            []string         `json:"tags,omitempty"`
+	Severity        string           `json:"severity,omitempty"`
+	Occurrences     int              `json:"occurrences,omitempty"`
+	Causes          []Cause          `json:"causes,omitempty"`
+	Source          *SourceLocation  `json:"source,omitempty"`
+	OccurredAt      string           `json:"occurred_at"`
 }
 
 // ToPayload converts the Notice to an API payload.
@@ -67,15 +176,21 @@ func (n *Notice) ToPayload() *Payload {
 
 	payload := &Payload{
 		Error: ErrorPayload{
-			Class:       n.ErrorClass,
-			Message:     n.Message,
-			Backtrace:   n.Backtrace,
-			Fingerprint: n.Fingerprint,
-			Tags:        n.Tags,
-			OccurredAt:  n.OccurredAt.UTC().Format(time.RFC3339),
+			Class:           n.ErrorClass,
+			Message:         n.Message,
+			Backtrace:       n.frames(),
+			LegacyBacktrace: n.Backtrace,
+			Fingerprint:     n.Fingerprint,
+			Tags:            n.Tags,
+			Severity:        n.Severity,
+			Occurrences:     n.Occurrences,
+			Causes:          n.Causes,
+			Source:          n.Source,
+			OccurredAt:      n.OccurredAt.UTC().Format(time.RFC3339),
 		},
 		Context:  ctx,
 		Notifier: n.Notifier,
+		Client:   n.Client,
 	}
 
 	if len(n.Request) > 0 {
@@ -86,6 +201,14 @@ func (n *Notice) ToPayload() *Payload {
 		payload.User = n.User
 	}
 
+	if len(n.Breadcrumbs) > 0 {
+		payload.Breadcrumbs = n.Breadcrumbs
+	}
+
+	if n.Session != "" {
+		payload.Session = &SessionInfo{ID: n.Session}
+	}
+
 	// Include server info if any field is set
 	if n.AppName != "" || n.Revision != "" || n.Hostname != "" {
 		payload.Server = &ServerInfo{
@@ -3,8 +3,10 @@ package checkend
 import (
 	"fmt"
 	"os"
+	"path"
 	"reflect"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -12,19 +14,27 @@ import (
 const (
 	maxBacktraceLines = 100
 	maxMessageLength  = 10000
+	maxCauses         = 10
 )
 
 // NoticeBuilder builds Notice objects from errors.
 type NoticeBuilder struct {
 	config         *Configuration
 	sanitizeFilter *SanitizeFilter
+	envFilter      *SanitizeFilter
+
+	// frames and backtrace, when non-nil, are used by Build instead of
+	// capturing the current stack.
+	frames    []BacktraceFrame
+	backtrace []string
 }
 
 // NewNoticeBuilder creates a new NoticeBuilder.
 func NewNoticeBuilder(config *Configuration) *NoticeBuilder {
 	return &NoticeBuilder{
 		config:         config,
-		sanitizeFilter: NewSanitizeFilter(config.FilterKeys),
+		sanitizeFilter: newConfiguredSanitizeFilter(config),
+		envFilter:      NewSanitizeFilter(config.SensitiveEnvPatterns),
 	}
 }
 
@@ -39,7 +49,16 @@ func (b *NoticeBuilder) Build(
 ) *Notice {
 	errorClass := b.extractClassName(err)
 	message := b.extractMessage(err)
-	backtrace := b.extractBacktrace()
+	frames, backtrace := b.frames, b.backtrace
+	if frames == nil {
+		frames = b.extractBacktrace()
+	}
+	if backtrace == nil {
+		backtrace = backtraceLines(frames)
+	}
+	if b.config.SourceContextLines > 0 {
+		b.addSourceContext(frames)
+	}
 
 	// Sanitize context (always included)
 	sanitizedContext := b.sanitizeFilter.Filter(context)
@@ -58,13 +77,14 @@ func (b *NoticeBuilder) Build(
 	// Conditionally include request data
 	var sanitizedRequest map[string]interface{}
 	if b.config.SendRequestData && len(request) > 0 {
-		sanitizedRequest = b.sanitizeFilter.Filter(request)
+		sanitizedRequest = redactSensitiveHeaders(b.sanitizeFilter.Filter(request), b.sanitizeFilter)
 	}
 
 	return &Notice{
 		ErrorClass:  errorClass,
 		Message:     message,
 		Backtrace:   backtrace,
+		Frames:      frames,
 		Fingerprint: fingerprint,
 		Tags:        tags,
 		Context:     sanitizedContext,
@@ -73,18 +93,112 @@ func (b *NoticeBuilder) Build(
 		Environment: b.config.Environment,
 		OccurredAt:  time.Now().UTC(),
 		Notifier:    b.buildNotifier(),
+		Client:      b.config.ClientInfo,
 		AppName:     b.config.AppName,
 		Revision:    b.config.Revision,
 		Hostname:    b.getHostname(),
 	}
 }
 
+// stackTracer is implemented by errors that record the stack where they
+// were created.
+type stackTracer interface {
+	Callers() []uintptr
+}
+
+// buildCause describes err as a cause. Its backtrace is only known when
+// err records its own stack.
+func (b *NoticeBuilder) buildCause(err error) Cause {
+	cause := Cause{
+		Class:   b.extractClassName(err),
+		Message: b.extractMessage(err),
+	}
+	if st, ok := err.(stackTracer); ok {
+		cause.Backtrace = b.formatBacktrace(st.Callers())
+	}
+	return cause
+}
+
+// buildCauses describes the errors wrapped by err, followed by the explicit
+// causes. A cause with the same class and message as one already listed is
+// skipped, so an explicit cause that is also in the wrap chain appears once.
+func (b *NoticeBuilder) buildCauses(err error, explicit []error) []Cause {
+	var causes []Cause
+	seen := make(map[string]bool)
+
+	add := func(cause error) {
+		if cause == nil || len(causes) >= maxCauses {
+			return
+		}
+		c := b.buildCause(cause)
+		key := c.Class + "\x00" + c.Message
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		causes = append(causes, c)
+	}
+
+	for _, wrapped := range unwrapChain(err) {
+		add(wrapped)
+	}
+	for _, cause := range explicit {
+		add(cause)
+	}
+
+	return causes
+}
+
+// unwrapChain returns the errors wrapped by err, depth first, supporting
+// both Unwrap() error and Unwrap() []error.
+func unwrapChain(err error) []error {
+	var chain []error
+
+	var walk func(error)
+	walk = func(e error) {
+		if len(chain) >= maxCauses {
+			return
+		}
+		switch u := e.(type) {
+		case interface{ Unwrap() error }:
+			if next := u.Unwrap(); next != nil {
+				chain = append(chain, next)
+				walk(next)
+			}
+		case interface{ Unwrap() []error }:
+			for _, next := range u.Unwrap() {
+				if next != nil {
+					chain = append(chain, next)
+					walk(next)
+				}
+			}
+		}
+	}
+	walk(err)
+
+	return chain
+}
+
+// filterBreadcrumbs sanitizes the data attached to each breadcrumb.
+func (b *NoticeBuilder) filterBreadcrumbs(breadcrumbs []Breadcrumb) []Breadcrumb {
+	result := make([]Breadcrumb, len(breadcrumbs))
+	for i, breadcrumb := range breadcrumbs {
+		breadcrumb.Data = b.sanitizeFilter.Filter(breadcrumb.Data)
+		result[i] = breadcrumb
+	}
+	return result
+}
+
 func (b *NoticeBuilder) extractClassName(err error) string {
 	t := reflect.TypeOf(err)
 	if t == nil {
 		return "error"
 	}
 
+	if class, ok := runtimeErrorClass(err); ok {
+		return class
+	}
+
 	// Get the type name
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
@@ -111,22 +225,45 @@ func (b *NoticeBuilder) extractMessage(err error) string {
 	return message
 }
 
-func (b *NoticeBuilder) extractBacktrace() []string {
-	var backtrace []string
-
+func (b *NoticeBuilder) extractBacktrace() []BacktraceFrame {
 	// Skip frames from checkend package
 	skip := 4 // Adjust based on call depth
 
 	pcs := make([]uintptr, maxBacktraceLines)
 	n := runtime.Callers(skip, pcs)
-	pcs = pcs[:n]
+	return b.callerFrames(pcs[:n])
+}
+
+// formatBacktrace converts program counters into backtrace lines.
+func (b *NoticeBuilder) formatBacktrace(pcs []uintptr) []string {
+	return backtraceLines(b.callerFrames(pcs))
+}
+
+// callerFrames converts program counters into backtrace frames.
+func (b *NoticeBuilder) callerFrames(pcs []uintptr) []BacktraceFrame {
+	if b.config.BacktraceFilter == nil {
+		return b.filterFrames(pcs)
+	}
+
+	var backtrace []BacktraceFrame
+	b.config.callbacks.run(func() {
+		backtrace = b.filterFrames(pcs)
+	})
+	return backtrace
+}
+
+// filterFrames converts program counters into backtrace frames, dropping
+// the SDK's own frames and those BacktraceFilter rejects.
+func (b *NoticeBuilder) filterFrames(pcs []uintptr) []BacktraceFrame {
+	backtrace := []BacktraceFrame{}
 
 	frames := runtime.CallersFrames(pcs)
 	for {
 		frame, more := frames.Next()
 
-		// Skip internal checkend frames
-		if strings.Contains(frame.File, "checkend-go") {
+		// Skip internal checkend frames and frames the filter rejects
+		if strings.Contains(frame.File, "checkend-go") ||
+			(b.config.BacktraceFilter != nil && !b.config.BacktraceFilter(frame)) {
 			if !more {
 				break
 			}
@@ -134,11 +271,9 @@ func (b *NoticeBuilder) extractBacktrace() []string {
 		}
 
 		// Clean file path using RootPath
-		filePath := b.cleanFilePath(frame.File)
-		line := fmt.Sprintf("%s:%d in %s", filePath, frame.Line, frame.Function)
-		backtrace = append(backtrace, line)
+		backtrace = append(backtrace, b.newFrame(b.cleanFilePath(frame.File), frame.Line, frame.Function))
 
-		if !more {
+		if !more || len(backtrace) >= maxBacktraceLines {
 			break
 		}
 	}
@@ -146,6 +281,81 @@ func (b *NoticeBuilder) extractBacktrace() []string {
 	return backtrace
 }
 
+// newFrame creates a backtrace frame, marking whether it is in-app.
+func (b *NoticeBuilder) newFrame(file string, line int, function string) BacktraceFrame {
+	var inApp bool
+	switch {
+	case len(b.config.AppPackages) > 0:
+		inApp = isAppFunction(function, b.config.AppPackages)
+	case b.config.InAppByPath:
+		inApp = !isLibraryFile(file)
+	}
+	return BacktraceFrame{File: file, Line: line, Function: function, InApp: inApp}
+}
+
+// backtraceLines formats frames as "file:line in function" lines.
+func backtraceLines(frames []BacktraceFrame) []string {
+	lines := make([]string, 0, len(frames))
+	for _, frame := range frames {
+		lines = append(lines, frame.String())
+	}
+	return lines
+}
+
+// cleanStackTrace removes the RootPath prefix from pre-formatted backtrace
+// lines.
+func (b *NoticeBuilder) cleanStackTrace(stack []string) []string {
+	cleaned := make([]string, 0, len(stack))
+	for _, line := range stack {
+		if len(cleaned) >= maxBacktraceLines {
+			break
+		}
+		cleaned = append(cleaned, b.cleanFilePath(strings.TrimSpace(line)))
+	}
+	return cleaned
+}
+
+// parseStackTrace converts pre-formatted backtrace lines into frames.
+func (b *NoticeBuilder) parseStackTrace(stack []string) []BacktraceFrame {
+	frames := make([]BacktraceFrame, 0, len(stack))
+	for _, line := range stack {
+		frame := parseBacktraceLine(line)
+		frames = append(frames, b.newFrame(frame.File, frame.Line, frame.Function))
+	}
+	return frames
+}
+
+// parseBacktraceLine parses a "file:line in function" backtrace line. Parts
+// that are missing are left empty.
+func parseBacktraceLine(line string) BacktraceFrame {
+	file, function := splitFrame(line)
+	frame := BacktraceFrame{File: file, Function: function}
+
+	location := line
+	if i := strings.LastIndex(line, " in "); i >= 0 {
+		location = line[:i]
+	}
+	if i := strings.LastIndex(location, ":"); i >= 0 {
+		frame.Line, _ = strconv.Atoi(location[i+1:])
+	}
+	return frame
+}
+
+// isAppFunction reports whether the fully qualified function name belongs
+// to one of packages or their subpackages.
+func isAppFunction(function string, packages []string) bool {
+	for _, pkg := range packages {
+		pkg = strings.TrimSuffix(pkg, "/")
+		if pkg == "" {
+			continue
+		}
+		if strings.HasPrefix(function, pkg+".") || strings.HasPrefix(function, pkg+"/") {
+			return true
+		}
+	}
+	return false
+}
+
 // cleanFilePath removes RootPath prefix from file paths for cleaner backtraces.
 func (b *NoticeBuilder) cleanFilePath(path string) string {
 	if b.config.RootPath != "" && strings.HasPrefix(path, b.config.RootPath) {
@@ -171,38 +381,45 @@ func (b *NoticeBuilder) getEnvironmentVars() map[string]string {
 	env := make(map[string]string)
 	for _, e := range os.Environ() {
 		pair := strings.SplitN(e, "=", 2)
-		if len(pair) == 2 {
-			key := pair[0]
-			if !b.isSensitiveEnvVar(key) {
-				env[key] = pair[1]
-			}
+		if len(pair) == 2 && b.includeEnvVar(pair[0]) {
+			env[pair[0]] = pair[1]
 		}
 	}
 	return env
 }
 
-// isSensitiveEnvVar checks if an environment variable name contains sensitive patterns.
-func (b *NoticeBuilder) isSensitiveEnvVar(key string) bool {
-	sensitivePatterns := []string{
-		"SECRET",
-		"PASSWORD",
-		"KEY",
-		"TOKEN",
-		"CREDENTIAL",
-		"AUTH",
-		"PRIVATE",
-	}
-	keyUpper := strings.ToUpper(key)
-	for _, pattern := range sensitivePatterns {
-		if strings.Contains(keyUpper, pattern) {
+// includeEnvVar reports whether an environment variable is sent, applying
+// EnvironmentAllowList if set and the sensitive-name check otherwise.
+func (b *NoticeBuilder) includeEnvVar(key string) bool {
+	if len(b.config.EnvironmentAllowList) == 0 {
+		return !b.isSensitiveEnvVar(key)
+	}
+	for _, allowed := range b.config.EnvironmentAllowList {
+		if allowed == key {
+			return true
+		}
+		if matched, _ := path.Match(allowed, key); matched && !b.isSensitiveEnvVar(key) {
 			return true
 		}
 	}
 	return false
 }
 
-// getHostname returns the current hostname.
+// isSensitiveEnvVar reports whether an environment variable name matches
+// SensitiveEnvPatterns.
+func (b *NoticeBuilder) isSensitiveEnvVar(key string) bool {
+	return b.envFilter.MatchesKey(key)
+}
+
+// getHostname returns the configured or detected hostname, or "" if
+// SendHostname is disabled.
 func (b *NoticeBuilder) getHostname() string {
+	if !b.config.SendHostname {
+		return ""
+	}
+	if b.config.Hostname != "" {
+		return b.config.Hostname
+	}
 	hostname, err := os.Hostname()
 	if err != nil {
 		return ""
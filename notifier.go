@@ -0,0 +1,275 @@
+package checkend
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Notifier reports errors to a single Checkend project. Each Notifier has
+// its own configuration and worker, so several can run side by side, e.g.
+// to report to two projects from one process. The package-level functions
+// use a default Notifier set up by Configure.
+//
+// Notifiers are not fully isolated. Testing mode, global context, delivery
+// stats and the recent notices shown by DebugHandler are package-level
+// state shared by every Notifier in the process.
+type Notifier struct {
+	mu     sync.RWMutex
+	config *Configuration
+	worker *Worker
+
+	// detached tracks WithAsync sends made without a worker.
+	detached sync.WaitGroup
+}
+
+// New creates a Notifier from cfg, returning an error if the configuration
+// is invalid. Call Stop when done to send pending notices.
+func New(cfg Config) (*Notifier, error) {
+	c := NewConfiguration(cfg)
+	if err := ValidateConfiguration(c); err != nil {
+		return nil, err
+	}
+	return newNotifier(c), nil
+}
+
+func newNotifier(c *Configuration) *Notifier {
+	n := &Notifier{config: c}
+	if c.AsyncSend && c.Enabled {
+		n.worker = NewWorker(c)
+		n.worker.Start()
+	}
+	return n
+}
+
+// Configuration returns the notifier's configuration.
+func (n *Notifier) Configuration() *Configuration {
+	return n.config
+}
+
+// Notify sends an error to Checkend asynchronously.
+func (n *Notifier) Notify(err error, opts ...NotifyOption) {
+	n.NotifyWithContext(context.Background(), err, opts...)
+}
+
+// NotifyWithContext sends an error to Checkend asynchronously with context.
+func (n *Notifier) NotifyWithContext(ctx context.Context, err error, opts ...NotifyOption) {
+	if n.config.callbacks.active() {
+		logReentrantNotify(n.config)
+		return
+	}
+
+	n.mu.RLock()
+	config, worker := n.config, n.worker
+	notice, duplicates := n.prepare(ctx, err, opts)
+	n.mu.RUnlock()
+	if duplicates != nil {
+		n.deliver(context.Background(), config, worker, duplicates)
+	}
+	if notice != nil {
+		n.deliver(ctx, config, worker, notice)
+	}
+}
+
+// deliver queues or sends a prepared notice according to its delivery mode
+// and AsyncSend.
+func (n *Notifier) deliver(ctx context.Context, config *Configuration, worker *Worker, notice *Notice) {
+	// Handle testing mode
+	if captureTestingNotice(notice) {
+		return
+	}
+
+	if config.PropagateContext {
+		notice.ctx = ctx
+	}
+
+	async := config.AsyncSend
+	switch notice.delivery {
+	case deliverySync:
+		async = false
+	case deliveryAsync:
+		async = true
+	}
+
+	// Send asynchronously or synchronously. The lock is released first so
+	// a slow send or a blocking push doesn't hold up Stop.
+	switch {
+	case async && worker != nil:
+		if !worker.Push(notice) && worker.stopped() {
+			// Stop ran after the worker was looked up
+			send(context.Background(), config, notice)
+		}
+	case notice.delivery == deliveryAsync:
+		n.detached.Add(1)
+		go func() {
+			defer n.detached.Done()
+			send(context.Background(), config, notice)
+		}()
+	default:
+		send(context.Background(), config, notice)
+	}
+}
+
+// NotifySync sends an error to Checkend synchronously and returns the response.
+func (n *Notifier) NotifySync(err error, opts ...NotifyOption) *APIResponse {
+	return n.NotifySyncWithContext(context.Background(), err, opts...)
+}
+
+// NotifySyncWithContext sends an error to Checkend synchronously with context.
+func (n *Notifier) NotifySyncWithContext(ctx context.Context, err error, opts ...NotifyOption) *APIResponse {
+	if n.config.callbacks.active() {
+		logReentrantNotify(n.config)
+		return nil
+	}
+
+	n.mu.RLock()
+	config, worker := n.config, n.worker
+	notice, duplicates := n.prepare(ctx, err, opts)
+	n.mu.RUnlock()
+	if duplicates != nil {
+		n.deliver(context.Background(), config, worker, duplicates)
+	}
+	if notice == nil {
+		return nil
+	}
+
+	// Handle testing mode
+	if captureTestingNotice(notice) {
+		return &APIResponse{ID: 0, ProblemID: 0}
+	}
+
+	resp, _ := send(ctx, config, notice)
+	return resp
+}
+
+// send delivers a notice synchronously, counting it as failed if it
+// couldn't be delivered.
+func send(ctx context.Context, config *Configuration, notice *Notice) (*APIResponse, error) {
+	resp, err := NewClient(config).SendWithContext(ctx, notice)
+	if err != nil {
+		countFailed(notice)
+	}
+	return resp, err
+}
+
+// prepare builds the notice for err while the caller holds n.mu for
+// reading. The notice is nil if it shouldn't be sent: reporting is
+// disabled, the error is ignored or sampled out, a BeforeNotify callback
+// rejected it, or SuppressConsecutiveDuplicates holds it back as a repeat
+// of the previous notice. When the notice ends such a streak of repeats,
+// prepare also returns a notice summarizing them, to be delivered first.
+func (n *Notifier) prepare(ctx context.Context, err error, opts []NotifyOption) (notice, duplicates *Notice) {
+	if !n.config.Enabled {
+		return nil, nil
+	}
+
+	// Check if error should be ignored
+	if shouldIgnore(n.config, err) {
+		return nil, nil
+	}
+
+	// Build notice
+	notice = buildNotice(n.config, ctx, err, opts...)
+
+	// Apply sampling
+	if !shouldSample(n.config, notice) {
+		return nil, nil
+	}
+
+	// Run before notify callbacks
+	if !runBeforeNotify(n.config, notice) {
+		return nil, nil
+	}
+
+	// Hold back consecutive duplicates
+	allowed, duplicates := n.config.duplicates.allow(notice)
+	if duplicates != nil {
+		recordRecentNotice(duplicates)
+	}
+	if !allowed {
+		return nil, nil
+	}
+
+	recordRecentNotice(notice)
+	return notice, duplicates
+}
+
+// flushDuplicates delivers the summary of any consecutive duplicates held
+// back since the last notice sent, so they aren't lost on Flush or Stop.
+func (n *Notifier) flushDuplicates(config *Configuration, worker *Worker) {
+	if duplicates := config.duplicates.flush(); duplicates != nil {
+		recordRecentNotice(duplicates)
+		n.deliver(context.Background(), config, worker, duplicates)
+	}
+}
+
+// Flush waits for all queued notices to be sent, giving up after
+// DefaultFlushTimeout.
+func (n *Notifier) Flush() {
+	_ = n.FlushWithTimeout(DefaultFlushTimeout)
+}
+
+// FlushWithTimeout waits up to d for all queued notices to be sent. It
+// returns context.DeadlineExceeded if notices are still pending when d
+// elapses, leaving them queued.
+func (n *Notifier) FlushWithTimeout(d time.Duration) error {
+	w := n.flushedWorker()
+	if w == nil {
+		return nil
+	}
+	return w.FlushWithTimeout(d)
+}
+
+// FlushCtx waits for all queued notices to be sent, returning early if ctx
+// is cancelled. It returns the number of notices still pending.
+func (n *Notifier) FlushCtx(ctx context.Context) int {
+	w := n.flushedWorker()
+	if w == nil {
+		return 0
+	}
+	return w.FlushCtx(ctx)
+}
+
+// Stop stops the worker and waits for pending notices. Notices reported
+// afterwards are sent synchronously.
+func (n *Notifier) Stop() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.flushDuplicates(n.config, n.worker)
+	if n.worker != nil {
+		n.worker.Stop()
+		n.worker = nil
+	}
+	n.detached.Wait()
+}
+
+// Sanitize returns a copy of data with sensitive values redacted using the
+// notifier's filter settings, the same policy applied to notices.
+func (n *Notifier) Sanitize(data map[string]interface{}) map[string]interface{} {
+	return newConfiguredSanitizeFilter(n.config).Filter(data)
+}
+
+// flushedWorker hands any held back duplicates to the worker, or sends
+// them, and returns the worker to flush.
+func (n *Notifier) flushedWorker() *Worker {
+	n.mu.RLock()
+	config, worker := n.config, n.worker
+	n.mu.RUnlock()
+
+	n.flushDuplicates(config, worker)
+	return worker
+}
+
+func (n *Notifier) currentWorker() *Worker {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.worker
+}
+
+// setEnabled toggles reporting.
+func (n *Notifier) setEnabled(enabled bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.config.Enabled = enabled
+}
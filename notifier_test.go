@@ -0,0 +1,370 @@
+package checkend
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestNotifiersAreIndependent(t *testing.T) {
+	first := NewTestTransport()
+	second := NewTestTransport()
+
+	a, err := New(Config{APIKey: "key-a", Enabled: boolPtr(true), Transport: first})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer a.Stop()
+
+	b, err := New(Config{APIKey: "key-b", Enabled: boolPtr(true), Transport: second, AppName: "billing"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer b.Stop()
+
+	a.Notify(errors.New("from a"))
+	b.Notify(errors.New("from b"))
+	a.Flush()
+	b.Flush()
+
+	if len(first.Requests()) != 1 || len(second.Requests()) != 1 {
+		t.Fatalf("Expected one request per notifier, got %d and %d", len(first.Requests()), len(second.Requests()))
+	}
+	if key := first.LastRequest().Header.Get("Checkend-Ingestion-Key"); key != "key-a" {
+		t.Errorf("Expected first notifier to use key-a, got %s", key)
+	}
+	if key := second.LastRequest().Header.Get("Checkend-Ingestion-Key"); key != "key-b" {
+		t.Errorf("Expected second notifier to use key-b, got %s", key)
+	}
+	if GetConfiguration() != nil {
+		t.Error("Expected notifiers not to configure the package-level SDK")
+	}
+}
+
+func TestNotifierNotifySync(t *testing.T) {
+	transport := NewTestTransport()
+	n, err := New(Config{APIKey: "test-key", Enabled: boolPtr(true), Transport: transport})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	n.Stop()
+
+	if resp := n.NotifySync(errors.New("sync")); resp == nil || resp.ID != 1 {
+		t.Errorf("Expected scripted response, got %+v", resp)
+	}
+
+	// Notify falls back to sending synchronously once stopped
+	n.Notify(errors.New("after stop"))
+	if len(transport.Requests()) != 2 {
+		t.Errorf("Expected 2 requests, got %d", len(transport.Requests()))
+	}
+}
+
+func TestNotifierDisabled(t *testing.T) {
+	transport := NewTestTransport()
+	n, err := New(Config{APIKey: "test-key", Enabled: boolPtr(false), Transport: transport})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer n.Stop()
+
+	n.NotifySync(errors.New("ignored"))
+	if len(transport.Requests()) != 0 {
+		t.Errorf("Expected no requests from a disabled notifier, got %d", len(transport.Requests()))
+	}
+}
+
+func TestNewInvalidConfiguration(t *testing.T) {
+	if n, err := New(Config{Enabled: boolPtr(true)}); err == nil || n != nil {
+		t.Errorf("Expected an error for a missing API key, got %v", err)
+	}
+}
+
+func TestNotifierPropagateContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, propagate := range []bool{true, false} {
+		transport := NewTestTransport()
+		n, err := New(Config{
+			APIKey:           "test-key",
+			Enabled:          boolPtr(true),
+			Transport:        transport,
+			PropagateContext: propagate,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		n.NotifyWithContext(ctx, errors.New("request failed"))
+		n.Flush()
+		n.Stop()
+
+		expected := 1
+		if propagate {
+			expected = 0
+		}
+		if len(transport.Requests()) != expected {
+			t.Errorf("PropagateContext %v: expected %d requests, got %d", propagate, expected, len(transport.Requests()))
+		}
+	}
+}
+
+func TestNotifierWithSyncBypassesWorker(t *testing.T) {
+	resetStats()
+	transport := NewTestTransport()
+	n, err := New(Config{APIKey: "test-key", Enabled: boolPtr(true), Transport: transport})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer n.Stop()
+
+	n.Notify(errors.New("urgent"), WithSync())
+
+	if len(transport.Requests()) != 1 {
+		t.Errorf("Expected the notice to be sent before Notify returned, got %d requests", len(transport.Requests()))
+	}
+	if queued := Stats().Queued; queued != 0 {
+		t.Errorf("Expected the worker queue to be bypassed, got %d queued", queued)
+	}
+}
+
+// gatedTransport holds requests until release is closed.
+type gatedTransport struct {
+	*TestTransport
+	release chan struct{}
+}
+
+func (g *gatedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	<-g.release
+	return g.TestTransport.RoundTrip(req)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestNotifierWithAsyncWithoutWorker(t *testing.T) {
+	transport := &gatedTransport{TestTransport: NewTestTransport(), release: make(chan struct{})}
+	cfg := NewConfiguration(Config{APIKey: "test-key", Enabled: boolPtr(true), Transport: transport})
+	cfg.AsyncSend = false
+	n := newNotifier(cfg)
+
+	returned := make(chan struct{})
+	go func() {
+		n.Notify(errors.New("background"), WithAsync())
+		close(returned)
+	}()
+
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		close(transport.release)
+		t.Fatal("Expected Notify to return before the notice was sent")
+	}
+
+	close(transport.release)
+	n.Stop()
+	if len(transport.Requests()) != 1 {
+		t.Errorf("Expected Stop to wait for the background send, got %d requests", len(transport.Requests()))
+	}
+}
+
+func TestNotifierStopDuringSyncSend(t *testing.T) {
+	arrived := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		close(arrived)
+		<-release
+		return NewTestTransport().RoundTrip(req)
+	})
+	n, err := New(Config{APIKey: "test-key", Enabled: boolPtr(true), Transport: transport})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	go n.Notify(errors.New("slow"), WithSync())
+	<-arrived
+
+	stopped := make(chan struct{})
+	go func() {
+		n.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Stop not to wait for an in-flight sync send")
+	}
+}
+
+func TestNotifierCountsFailedSyncSends(t *testing.T) {
+	resetStats()
+	defer resetStats()
+
+	transport := NewTestTransport()
+	transport.RespondWith(422, `{"error": "invalid"}`)
+	n, err := New(Config{APIKey: "test-key", Enabled: boolPtr(true), Transport: transport})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer n.Stop()
+
+	n.Notify(errors.New("rejected"), WithSync())
+	n.NotifySync(errors.New("rejected"))
+
+	if failed := Stats().Failed; failed != 2 {
+		t.Errorf("Expected both sync sends to count as failed, got %d", failed)
+	}
+}
+
+func TestNotifierNestedNotifyOnAnotherNotifier(t *testing.T) {
+	audit := NewTestTransport()
+	auditor, err := New(Config{APIKey: "audit-key", Enabled: boolPtr(true), Transport: audit})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer auditor.Stop()
+
+	transport := NewTestTransport()
+	n, err := New(Config{
+		APIKey:    "test-key",
+		Enabled:   boolPtr(true),
+		Transport: transport,
+		BeforeNotify: []func(*Notice) bool{
+			func(notice *Notice) bool {
+				auditor.NotifySync(errors.New("audited"))
+				return true
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer n.Stop()
+
+	n.NotifySync(errors.New("outer"))
+
+	if len(transport.Requests()) != 1 {
+		t.Errorf("Expected outer notice to be sent, got %d requests", len(transport.Requests()))
+	}
+	if len(audit.Requests()) != 1 {
+		t.Errorf("Expected notice from another notifier's callback to be sent, got %d requests", len(audit.Requests()))
+	}
+}
+
+func TestNotifierTransformPayloadNotifyIsSuppressed(t *testing.T) {
+	transport := NewTestTransport()
+	var n *Notifier
+	n, err := New(Config{
+		APIKey:    "test-key",
+		Enabled:   boolPtr(true),
+		Transport: transport,
+		TransformPayload: func(p *Payload) {
+			n.Notify(errors.New("transform failed"))
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer n.Stop()
+
+	n.NotifySync(errors.New("outer"))
+
+	if len(transport.Requests()) != 1 {
+		t.Errorf("Expected only the outer notice to be sent, got %d requests", len(transport.Requests()))
+	}
+}
+
+func TestNotifierBuildCallbackNotifyIsSuppressed(t *testing.T) {
+	var n *Notifier
+	notifyInside := func() { n.Notify(errors.New("callback failed")) }
+
+	configs := map[string]Config{
+		"Fingerprinter": {
+			Fingerprinter: func(*Notice) string {
+				notifyInside()
+				return "fp"
+			},
+		},
+		"TraceExtractor": {
+			TraceExtractor: func(context.Context) (string, string) {
+				notifyInside()
+				return "trace", "span"
+			},
+		},
+		"NormalizeMessage": {
+			NormalizeMessage: func(message string) string {
+				notifyInside()
+				return message
+			},
+		},
+		"BacktraceFilter": {
+			BacktraceFilter: func(runtime.Frame) bool {
+				notifyInside()
+				return true
+			},
+		},
+	}
+	for name, cfg := range configs {
+		transport := NewTestTransport()
+		cfg.APIKey = "test-key"
+		cfg.Enabled = boolPtr(true)
+		cfg.Transport = transport
+
+		var err error
+		n, err = New(cfg)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+
+		n.NotifySync(errors.New("outer"))
+		n.Stop()
+
+		if len(transport.Requests()) != 1 {
+			t.Errorf("%s: expected only the outer notice to be sent, got %d requests", name, len(transport.Requests()))
+		}
+	}
+}
+
+func BenchmarkNotifierNotifySync(b *testing.B) {
+	n, err := New(Config{
+		APIKey:    "test-key",
+		Enabled:   boolPtr(true),
+		Transport: NewTestTransport(),
+	})
+	if err != nil {
+		b.Fatalf("Unexpected error: %v", err)
+	}
+	defer n.Stop()
+
+	notifyErr := errors.New("benchmark")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		n.NotifySync(notifyErr, WithoutBacktrace())
+	}
+}
+
+func BenchmarkCallbackGuardIdle(b *testing.B) {
+	g := &callbackGuard{}
+	for i := 0; i < b.N; i++ {
+		g.active()
+	}
+}
+
+func BenchmarkCallbackGuardRunning(b *testing.B) {
+	g := &callbackGuard{}
+	g.run(func() {
+		for i := 0; i < b.N; i++ {
+			g.active()
+		}
+	})
+}
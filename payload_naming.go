@@ -0,0 +1,105 @@
+package checkend
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// FieldNaming controls how SDK-defined payload field names are rendered.
+type FieldNaming int
+
+const (
+	// FieldNamingSnakeCase renders field names as snake_case (the default).
+	FieldNamingSnakeCase FieldNaming = iota
+
+	// FieldNamingCamelCase renders field names as camelCase.
+	FieldNamingCamelCase
+)
+
+// sdkSections are the payload sections whose keys are defined by the SDK.
+// User-supplied sections (context, request, user) are never renamed.
+var sdkSections = []string{"error", "notifier", "client", "server"}
+
+// sdkErrorFields are the fields of the error section holding SDK-defined
+// objects, or arrays of them, whose keys are renamed too.
+var sdkErrorFields = []string{"backtrace", "causes", "source"}
+
+// marshalPayload encodes the payload, applying the configured field naming.
+func marshalPayload(payload *Payload, naming FieldNaming, names map[string]string) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if naming == FieldNamingSnakeCase && len(names) == 0 {
+		return data, nil
+	}
+
+	var raw map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	if errorSection, ok := raw["error"].(map[string]interface{}); ok {
+		for _, field := range sdkErrorFields {
+			if value, ok := errorSection[field]; ok {
+				errorSection[field] = renameNestedFields(value, naming, names)
+			}
+		}
+	}
+
+	for _, section := range sdkSections {
+		if m, ok := raw[section].(map[string]interface{}); ok {
+			raw[section] = renameFields(m, naming, names)
+		}
+	}
+
+	return json.Marshal(renameFields(raw, naming, names))
+}
+
+func renameFields(m map[string]interface{}, naming FieldNaming, names map[string]string) map[string]interface{} {
+	result := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		result[renameField(key, naming, names)] = value
+	}
+	return result
+}
+
+// renameNestedFields renames the keys of an object, or of each object in an
+// array.
+func renameNestedFields(value interface{}, naming FieldNaming, names map[string]string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return renameFields(v, naming, names)
+	case []interface{}:
+		for i, item := range v {
+			if m, ok := item.(map[string]interface{}); ok {
+				v[i] = renameFields(m, naming, names)
+			}
+		}
+	}
+	return value
+}
+
+func renameField(key string, naming FieldNaming, names map[string]string) string {
+	if name, ok := names[key]; ok && name != "" {
+		return name
+	}
+	if naming == FieldNamingCamelCase {
+		return toCamelCase(key)
+	}
+	return key
+}
+
+func toCamelCase(s string) string {
+	parts := strings.Split(s, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,141 @@
+package checkend
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func buildTestPayload() *Payload {
+	cfg := NewConfiguration(Config{APIKey: "test-key", AppName: "my-app"})
+	notice := NewNoticeBuilder(cfg).Build(
+		errors.New("test error"),
+		map[string]interface{}{"order_id": 1},
+		nil,
+		nil,
+		"",
+		nil,
+	)
+	return notice.ToPayload()
+}
+
+func decodePayload(t *testing.T, data []byte) map[string]interface{} {
+	t.Helper()
+	var result map[string]interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+	return result
+}
+
+func TestMarshalPayloadDefaultNaming(t *testing.T) {
+	data, err := marshalPayload(buildTestPayload(), FieldNamingSnakeCase, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	errorSection := decodePayload(t, data)["error"].(map[string]interface{})
+	if _, ok := errorSection["occurred_at"]; !ok {
+		t.Error("Expected occurred_at in error section")
+	}
+}
+
+func TestMarshalPayloadCamelCase(t *testing.T) {
+	payload := buildTestPayload()
+	payload.Error.Backtrace = []BacktraceFrame{{
+		File:        "main.go",
+		Line:        2,
+		Function:    "main.run",
+		InApp:       true,
+		PreContext:  []string{"line 1"},
+		ContextLine: "line 2",
+		PostContext: []string{"line 3"},
+	}}
+	payload.Error.Causes = []Cause{{Class: "*errors.errorString", Message: "cause"}}
+	payload.Error.Source = &SourceLocation{File: "main.go", Line: 2}
+
+	data, err := marshalPayload(payload, FieldNamingCamelCase, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	result := decodePayload(t, data)
+	errorSection := result["error"].(map[string]interface{})
+	if _, ok := errorSection["occurredAt"]; !ok {
+		t.Errorf("Expected occurredAt in error section, got %v", errorSection)
+	}
+	if _, ok := errorSection["occurred_at"]; ok {
+		t.Error("Expected occurred_at to be renamed")
+	}
+
+	frame := errorSection["backtrace"].([]interface{})[0].(map[string]interface{})
+	for _, key := range []string{"inApp", "preContext", "contextLine", "postContext"} {
+		if _, ok := frame[key]; !ok {
+			t.Errorf("Expected %s in backtrace frame, got %v", key, frame)
+		}
+	}
+	for _, key := range []string{"in_app", "pre_context", "context_line", "post_context"} {
+		if _, ok := frame[key]; ok {
+			t.Errorf("Expected %s to be renamed in backtrace frame", key)
+		}
+	}
+
+	notifier := result["notifier"].(map[string]interface{})
+	if _, ok := notifier["languageVersion"]; !ok {
+		t.Errorf("Expected languageVersion in notifier section, got %v", notifier)
+	}
+
+	server := result["server"].(map[string]interface{})
+	if server["appName"] != "my-app" {
+		t.Errorf("Expected appName 'my-app', got %v", server["appName"])
+	}
+
+	// User-supplied context keys are left untouched
+	ctx := result["context"].(map[string]interface{})
+	if _, ok := ctx["order_id"]; !ok {
+		t.Errorf("Expected context key order_id to be preserved, got %v", ctx)
+	}
+}
+
+func TestMarshalPayloadCustomFieldNames(t *testing.T) {
+	payload := buildTestPayload()
+	payload.Error.Causes = []Cause{{Class: "*errors.errorString", Message: "cause"}}
+
+	data, err := marshalPayload(payload, FieldNamingSnakeCase, map[string]string{
+		"class":       "error_class",
+		"occurred_at": "timestamp",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	errorSection := decodePayload(t, data)["error"].(map[string]interface{})
+	if _, ok := errorSection["error_class"]; !ok {
+		t.Errorf("Expected error_class in error section, got %v", errorSection)
+	}
+	if _, ok := errorSection["timestamp"]; !ok {
+		t.Errorf("Expected timestamp in error section, got %v", errorSection)
+	}
+	if _, ok := errorSection["class"]; ok {
+		t.Error("Expected class to be renamed")
+	}
+
+	cause := errorSection["causes"].([]interface{})[0].(map[string]interface{})
+	if cause["error_class"] != "*errors.errorString" {
+		t.Errorf("Expected class to be renamed in causes, got %v", cause)
+	}
+}
+
+func TestMarshalPayloadCustomNamesOverrideCamelCase(t *testing.T) {
+	data, err := marshalPayload(buildTestPayload(), FieldNamingCamelCase, map[string]string{
+		"occurred_at": "occurred",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	errorSection := decodePayload(t, data)["error"].(map[string]interface{})
+	if _, ok := errorSection["occurred"]; !ok {
+		t.Errorf("Expected occurred in error section, got %v", errorSection)
+	}
+}
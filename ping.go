@@ -0,0 +1,99 @@
+package checkend
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DefaultPingPath is the API path Ping posts to, relative to Endpoint.
+const DefaultPingPath = "/ingest/v1/ping"
+
+var (
+	// ErrUnauthorized is returned by Ping when the API key is rejected.
+	ErrUnauthorized = errors.New("checkend: invalid API key")
+
+	// ErrEndpointNotFound is returned by Ping when the endpoint doesn't
+	// serve the Checkend API, e.g. because Endpoint is misconfigured.
+	ErrEndpointNotFound = errors.New("checkend: endpoint not found")
+)
+
+// PingError is returned by Ping when the endpoint can't be verified.
+// StatusCode is zero when no response was received, in which case Err is
+// the network error. Otherwise Err is ErrUnauthorized,
+// ErrEndpointNotFound or a generic status error.
+type PingError struct {
+	StatusCode int
+	Err        error
+}
+
+func (e *PingError) Error() string {
+	return fmt.Sprintf("checkend: ping failed: %v", e.Err)
+}
+
+// Unwrap returns the underlying error, for use with errors.Is and errors.As.
+func (e *PingError) Unwrap() error {
+	return e.Err
+}
+
+// Ping verifies that the endpoint is reachable and accepts the API key,
+// without reporting an error, e.g. in a startup health check or in CI. It
+// uses the configured proxy, TLS and timeout settings and PrepareRequest
+// hook, but bypasses the circuit breaker.
+func (c *Client) Ping(ctx context.Context) error {
+	if c.config.APIKey == "" {
+		return errors.New("checkend: api_key not configured")
+	}
+	checkTestingModeRequired(c.config)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.pingEndpoint, bytes.NewReader([]byte("{}")))
+	if err != nil {
+		return &PingError{Err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Checkend-Ingestion-Key", c.config.APIKey)
+	req.Header.Set("User-Agent", fmt.Sprintf("checkend-go/%s", Version))
+	if err := c.prepareRequest(req); err != nil {
+		return &PingError{Err: err}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		c.log("error", fmt.Sprintf("Ping failed: %v", err))
+		return &PingError{Err: err}
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		c.log("debug", "Ping succeeded")
+		return nil
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		err = ErrUnauthorized
+	case resp.StatusCode == http.StatusNotFound:
+		err = ErrEndpointNotFound
+	default:
+		err = fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	c.log("error", fmt.Sprintf("Ping failed: %v", err))
+	return &PingError{StatusCode: resp.StatusCode, Err: err}
+}
+
+// Ping verifies the notifier's endpoint and API key. See Client.Ping.
+func (n *Notifier) Ping(ctx context.Context) error {
+	return NewClient(n.Configuration()).Ping(ctx)
+}
+
+// Ping verifies that the configured endpoint is reachable and accepts the
+// API key. See Client.Ping.
+func Ping() error {
+	n := defaultNotifier()
+	if n == nil {
+		return errors.New("checkend: not configured")
+	}
+	return n.Ping(context.Background())
+}
@@ -0,0 +1,93 @@
+package checkend
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClientPing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != DefaultPingPath {
+			t.Errorf("Expected ping path %s, got %s", DefaultPingPath, r.URL.Path)
+		}
+		if r.Header.Get("Checkend-Ingestion-Key") != "test-key" {
+			t.Errorf("Expected ingestion key header, got '%s'", r.Header.Get("Checkend-Ingestion-Key"))
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := NewConfiguration(Config{APIKey: "test-key", Endpoint: server.URL})
+	if err := NewClient(cfg).Ping(context.Background()); err != nil {
+		t.Errorf("Expected ping to succeed, got %v", err)
+	}
+}
+
+func TestClientPingErrors(t *testing.T) {
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrUnauthorized},
+		{http.StatusNotFound, ErrEndpointNotFound},
+	}
+
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+		}))
+
+		cfg := NewConfiguration(Config{APIKey: "test-key", Endpoint: server.URL})
+		err := NewClient(cfg).Ping(context.Background())
+		server.Close()
+
+		if !errors.Is(err, tt.want) {
+			t.Errorf("Status %d: expected %v, got %v", tt.status, tt.want, err)
+		}
+		var pingErr *PingError
+		if !errors.As(err, &pingErr) || pingErr.StatusCode != tt.status {
+			t.Errorf("Status %d: expected a PingError with the status, got %#v", tt.status, err)
+		}
+	}
+}
+
+func TestClientPingNetworkError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	endpoint := server.URL
+	server.Close()
+
+	cfg := NewConfiguration(Config{APIKey: "test-key", Endpoint: endpoint})
+	err := NewClient(cfg).Ping(context.Background())
+
+	var pingErr *PingError
+	if !errors.As(err, &pingErr) || pingErr.StatusCode != 0 || pingErr.Err == nil {
+		t.Errorf("Expected a PingError wrapping the network error, got %#v", err)
+	}
+}
+
+func TestPingUsesConfiguredTransport(t *testing.T) {
+	defer Reset()
+
+	transport := NewTestTransport()
+	Configure(Config{APIKey: "test-key", Enabled: boolPtr(true), Transport: transport})
+
+	if err := Ping(); err != nil {
+		t.Fatalf("Expected ping to succeed, got %v", err)
+	}
+	if req := transport.LastRequest(); req == nil || !strings.HasSuffix(req.URL, DefaultPingPath) {
+		t.Errorf("Expected the ping to go through the configured transport, got %+v", req)
+	}
+}
+
+func TestPingNotConfigured(t *testing.T) {
+	Reset()
+
+	if err := Ping(); err == nil {
+		t.Error("Expected an error before Configure")
+	}
+}
@@ -0,0 +1,199 @@
+package checkend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// RecoverWithSkip recovers a panic, reports it synchronously, and re-panics.
+// It must be deferred directly:
+//
+//	defer checkend.RecoverWithSkip(0)
+//
+// The reported backtrace starts at the frame that panicked rather than at the
+// recovery handler, so the recover and runtime panic frames are excluded.
+// skip drops that many additional frames from the top, which is useful when
+// the panic is raised by a helper such as an assertion function.
+func RecoverWithSkip(skip int) {
+	if r := recover(); r != nil {
+		reportPanic(context.Background(), r, skip)
+	}
+}
+
+// Recover recovers a panic, reports it synchronously with a backtrace from
+// where the panic originated, and re-panics. Defer it at the top of a
+// goroutine so its panic is reported before the program crashes:
+//
+//	go func() {
+//		defer checkend.Recover()
+//		work()
+//	}()
+//
+// Recover is equivalent to RecoverWithSkip(0). It can't call it, because
+// recover only stops a panic when called directly by the deferred function.
+func Recover() {
+	if r := recover(); r != nil {
+		reportPanic(context.Background(), r, 0)
+	}
+}
+
+// RecoverWithContext is like Recover but reports the panic with the data
+// attached to ctx.
+func RecoverWithContext(ctx context.Context) {
+	if r := recover(); r != nil {
+		reportPanic(ctx, r, 0)
+	}
+}
+
+// reportPanic reports a recovered panic synchronously and re-panics. It
+// must be called by the deferred function that recovered r.
+func reportPanic(ctx context.Context, r interface{}, skip int) {
+	NotifySyncWithContext(ctx, panicToError(r), WithBacktrace(panicCallers(skip)))
+	panic(r)
+}
+
+// Go runs fn in a new goroutine guarded by Recover.
+func Go(fn func()) {
+	go func() {
+		defer Recover()
+		fn()
+	}()
+}
+
+// panicToError converts a recovered panic value to an error.
+func panicToError(recovered interface{}) error {
+	if err, ok := recovered.(error); ok {
+		return err
+	}
+	return fmt.Errorf("panic: %v", recovered)
+}
+
+// runtimeErrorClasses maps runtime errors to stable error classes, so that
+// e.g. every nil map write groups together instead of under the runtime's
+// internal error types. The runtime doesn't export these errors, so each
+// one is captured by triggering it.
+var runtimeErrorClasses = map[error]string{
+	runtimePanic(func() {
+		var m map[string]int
+		m[""] = 1
+	}): "runtime.NilMapWrite",
+	runtimePanic(func() {
+		ch := make(chan int, 1)
+		close(ch)
+		ch <- 1
+	}): "runtime.ClosedChannelSend",
+	runtimePanic(func() {
+		ch := make(chan int)
+		close(ch)
+		close(ch)
+	}): "runtime.ClosedChannelClose",
+	runtimePanic(func() {
+		var ch chan int
+		close(ch)
+	}): "runtime.NilChannelClose",
+	runtimePanic(func() {
+		var p *int
+		*p = 1
+	}): "runtime.NilPointerDereference",
+	runtimePanic(func() {
+		zero := 0
+		_ = 1 / zero
+	}): "runtime.DivideByZero",
+}
+
+// boundsIndex is the code the runtime gives an index out of range error;
+// every other code is a slice bounds error.
+const boundsIndex = 0
+
+// runtimePanic returns the runtime error fn panics with.
+func runtimePanic(fn func()) (err error) {
+	defer func() {
+		err, _ = recover().(runtime.Error)
+	}()
+	fn()
+	return nil
+}
+
+// runtimeErrorClass classifies a runtime panic by its type and value. It
+// returns false for errors that are not runtime errors or are not
+// recognized.
+func runtimeErrorClass(err error) (string, bool) {
+	var runtimeErr runtime.Error
+	if !errors.As(err, &runtimeErr) {
+		return "", false
+	}
+
+	var typeErr *runtime.TypeAssertionError
+	if errors.As(err, &typeErr) {
+		return "runtime.TypeAssertion", true
+	}
+
+	t := reflect.TypeOf(runtimeErr)
+	if t.String() == "runtime.boundsError" {
+		code := reflect.ValueOf(runtimeErr).FieldByName("code")
+		if !code.IsValid() {
+			return "", false
+		}
+		if code.Uint() == boundsIndex {
+			return "runtime.IndexOutOfRange", true
+		}
+		return "runtime.SliceBoundsOutOfRange", true
+	}
+
+	if !t.Comparable() {
+		return "", false
+	}
+	class, ok := runtimeErrorClasses[runtimeErr]
+	return class, ok
+}
+
+// panicCallers returns the program counters of the current goroutine starting
+// at the frame that panicked. It must be called from a deferred function while
+// the panic is in progress; otherwise the full stack is returned.
+func panicCallers(skip int) []uintptr {
+	pcs := make([]uintptr, maxBacktraceLines+16)
+	n := runtime.Callers(2, pcs)
+	pcs = pcs[:n]
+
+	for i, pc := range pcs {
+		if funcName(pc) != "runtime.gopanic" {
+			continue
+		}
+
+		// Skip runtime frames between gopanic and the faulting frame,
+		// such as runtime.panicmem and runtime.sigpanic.
+		start := i + 1
+		for start < len(pcs) && strings.HasPrefix(funcName(pcs[start]), "runtime.") {
+			start++
+		}
+
+		start += skip
+		if start > len(pcs) {
+			start = len(pcs)
+		}
+		return pcs[start:]
+	}
+
+	return pcs
+}
+
+func funcName(pc uintptr) string {
+	fn := runtime.FuncForPC(pc - 1)
+	if fn == nil {
+		return ""
+	}
+	return fn.Name()
+}
+
+// WithPanicStack attaches the stack of the panic being recovered, starting
+// at the frame that panicked rather than at the recovery handler. It must be
+// called while the panic is in progress, from the deferred function that
+// recovered it or a handler that function calls; otherwise the current stack
+// is used.
+func WithPanicStack() NotifyOption {
+	return WithBacktrace(panicCallers(0))
+}
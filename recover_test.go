@@ -0,0 +1,240 @@
+package checkend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func functionNames(pcs []uintptr) []string {
+	var names []string
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		names = append(names, frame.Function)
+		if !more {
+			break
+		}
+	}
+	return names
+}
+
+//go:noinline
+func panickingFunction() {
+	panic("boom")
+}
+
+func panicHelper() {
+	panic("helper boom")
+}
+
+//go:noinline
+func callsPanicHelper() {
+	panicHelper()
+}
+
+func capturePanicCallers(fn func(), skip int) (pcs []uintptr) {
+	defer func() {
+		if r := recover(); r != nil {
+			pcs = panicCallers(skip)
+		}
+	}()
+	fn()
+	return nil
+}
+
+func TestPanicCallersStartAtPanicOrigin(t *testing.T) {
+	names := functionNames(capturePanicCallers(panickingFunction, 0))
+
+	if !strings.HasSuffix(names[0], ".panickingFunction") {
+		t.Errorf("Expected backtrace to start at panickingFunction, got %v", names)
+	}
+	for _, name := range names {
+		if name == "runtime.gopanic" || strings.Contains(name, "capturePanicCallers.func") {
+			t.Errorf("Expected recover frames to be excluded, found %s", name)
+		}
+	}
+}
+
+func TestPanicCallersWithSkip(t *testing.T) {
+	names := functionNames(capturePanicCallers(callsPanicHelper, 1))
+
+	if !strings.HasSuffix(names[0], ".callsPanicHelper") {
+		t.Errorf("Expected backtrace to start at callsPanicHelper, got %v", names)
+	}
+}
+
+func TestRecoverWithSkipReportsAndRepanics(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{
+		APIKey:  "test-key",
+		Enabled: boolPtr(true),
+	})
+
+	var recovered interface{}
+	func() {
+		defer func() {
+			recovered = recover()
+		}()
+		defer RecoverWithSkip(0)
+		panickingFunction()
+	}()
+
+	if recovered != "boom" {
+		t.Errorf("Expected panic to be re-raised, got %v", recovered)
+	}
+
+	notice := TestingLastNotice()
+	if notice == nil {
+		t.Fatal("Expected a notice to be captured")
+	}
+	if notice.Message != "panic: boom" {
+		t.Errorf("Expected message 'panic: boom', got '%s'", notice.Message)
+	}
+	for _, line := range notice.Backtrace {
+		if strings.HasSuffix(line, ".RecoverWithSkip") || strings.HasSuffix(line, "runtime.gopanic") {
+			t.Errorf("Expected recover frames to be excluded, found %s", line)
+		}
+	}
+}
+
+func TestRecoverWithSkipClassifiesRuntimePanics(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{
+		APIKey:  "test-key",
+		Enabled: boolPtr(true),
+	})
+
+	var nilMap map[string]int
+	var nilPointer *struct{ n int }
+	var nilChannel chan int
+	var value interface{} = "text"
+	closed := make(chan int)
+	close(closed)
+	index := 3
+	zero := 0
+
+	tests := []struct {
+		name     string
+		fn       func()
+		expected string
+	}{
+		{"nil map write", func() { nilMap["key"] = 1 }, "runtime.NilMapWrite"},
+		{"closed channel send", func() { closed <- 1 }, "runtime.ClosedChannelSend"},
+		{"index out of range", func() { _ = []int{1, 2}[index] }, "runtime.IndexOutOfRange"},
+		{"slice bounds out of range", func() { _ = []int{1, 2}[index:] }, "runtime.SliceBoundsOutOfRange"},
+		{"nil pointer dereference", func() { nilPointer.n++ }, "runtime.NilPointerDereference"},
+		{"divide by zero", func() { _ = index / zero }, "runtime.DivideByZero"},
+		{"nil channel close", func() { close(nilChannel) }, "runtime.NilChannelClose"},
+		{"type assertion", func() { _ = value.(int) }, "runtime.TypeAssertion"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			TestingClearNotices()
+
+			func() {
+				defer func() { _ = recover() }()
+				defer RecoverWithSkip(0)
+				tt.fn()
+			}()
+
+			notice := TestingLastNotice()
+			if notice == nil {
+				t.Fatal("Expected a notice to be captured")
+			}
+			if notice.ErrorClass != tt.expected {
+				t.Errorf("Expected class %s, got %s", tt.expected, notice.ErrorClass)
+			}
+		})
+	}
+}
+
+func TestRuntimeErrorClassUnwrapsErrors(t *testing.T) {
+	err := fmt.Errorf("worker: %w", runtimePanic(func() {
+		var m map[string]int
+		m["key"] = 1
+	}))
+	if class, ok := runtimeErrorClass(err); !ok || class != "runtime.NilMapWrite" {
+		t.Errorf("Expected wrapped runtime error to be classified, got %q", class)
+	}
+}
+
+func TestRuntimeErrorClassIgnoresOtherErrors(t *testing.T) {
+	if _, ok := runtimeErrorClass(errors.New("index out of range")); ok {
+		t.Error("Expected plain errors not to be classified as runtime errors")
+	}
+}
+
+func TestRecoverReportsPanicOrigin(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{
+		APIKey:  "test-key",
+		Enabled: boolPtr(true),
+	})
+
+	recovered := make(chan interface{})
+	go func() {
+		defer func() { recovered <- recover() }()
+		defer Recover()
+		panickingFunction()
+	}()
+
+	if r := <-recovered; r != "boom" {
+		t.Errorf("Expected panic to be re-raised, got %v", r)
+	}
+
+	notice := TestingLastNotice()
+	if notice == nil {
+		t.Fatal("Expected a notice to be captured")
+	}
+	if len(notice.Backtrace) == 0 || !strings.HasSuffix(notice.Backtrace[0], ".panickingFunction") {
+		t.Errorf("Expected backtrace to start at panickingFunction, got %v", notice.Backtrace)
+	}
+}
+
+func TestRecoverWithContextAttachesContext(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{
+		APIKey:  "test-key",
+		Enabled: boolPtr(true),
+	})
+
+	ctx := SetContext(context.Background(), map[string]interface{}{"job": "reindex"})
+	func() {
+		defer func() { _ = recover() }()
+		defer RecoverWithContext(ctx)
+		panickingFunction()
+	}()
+
+	notice := TestingLastNotice()
+	if notice == nil {
+		t.Fatal("Expected a notice to be captured")
+	}
+	if notice.Context["job"] != "reindex" {
+		t.Errorf("Expected context from ctx, got %v", notice.Context)
+	}
+}
+
+func TestGoRunsFunction(t *testing.T) {
+	done := make(chan struct{})
+	Go(func() { close(done) })
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Go to run the function")
+	}
+}
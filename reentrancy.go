@@ -0,0 +1,67 @@
+package checkend
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// callbackGuard tracks the goroutines running a notifier's callbacks while
+// it builds or encodes a notice, such as TraceExtractor, Fingerprinter,
+// BeforeNotify and TransformPayload, so a notice reported from inside one
+// of them can be dropped instead of recursing.
+type callbackGuard struct {
+	running    atomic.Int32
+	goroutines sync.Map
+}
+
+// enter marks the current goroutine as running a callback.
+func (g *callbackGuard) enter() uint64 {
+	g.running.Add(1)
+	id := goroutineID()
+	g.goroutines.Store(id, struct{}{})
+	return id
+}
+
+// exit clears the mark set by enter.
+func (g *callbackGuard) exit(id uint64) {
+	g.goroutines.Delete(id)
+	g.running.Add(-1)
+}
+
+// active reports whether the current goroutine is running a callback. The
+// goroutine is only looked up while some callback is running, so the
+// common case costs a single atomic load.
+func (g *callbackGuard) active() bool {
+	if g == nil || g.running.Load() == 0 {
+		return false
+	}
+	_, ok := g.goroutines.Load(goroutineID())
+	return ok
+}
+
+// run calls fn with the current goroutine marked as running a callback.
+func (g *callbackGuard) run(fn func()) {
+	if g == nil {
+		fn()
+		return
+	}
+	id := g.enter()
+	defer g.exit(id)
+	fn()
+}
+
+// goroutineID returns the runtime's identifier for the current goroutine.
+func goroutineID() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	// The stack trace starts with "goroutine <id> [".
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	if i := bytes.IndexByte(buf, ' '); i >= 0 {
+		buf = buf[:i]
+	}
+	id, _ := strconv.ParseUint(string(buf), 10, 64)
+	return id
+}
@@ -0,0 +1,233 @@
+package checkend
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Checkend/checkend-go/filters"
+)
+
+// sensitiveHeaders are always filtered from request data, regardless of
+// the configured filter keys.
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+}
+
+// RequestData extracts the URL, method, headers, and query parameters of r
+// for use with WithRequest or SetRequest. The body is included when
+// CaptureRequestBody is enabled; it is read up to MaxRequestBodyBytes and
+// restored so the handler can still read it. Sensitive headers such as
+// Authorization and Cookie are filtered whether they have one value or
+// several, as are headers and parameters matching the configured filter
+// keys.
+func RequestData(r *http.Request) map[string]interface{} {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	fullURL := fmt.Sprintf("%s://%s%s", scheme, r.Host, r.RequestURI)
+
+	headers := make(map[string]interface{})
+	for key, values := range r.Header {
+		if len(values) == 1 {
+			headers[key] = values[0]
+		} else {
+			headers[key] = values
+		}
+	}
+
+	request := map[string]interface{}{
+		"url":     fullURL,
+		"method":  r.Method,
+		"headers": headers,
+	}
+
+	if r.URL.RawQuery != "" {
+		params := make(map[string]interface{})
+		for key, values := range r.URL.Query() {
+			if len(values) == 1 {
+				params[key] = values[0]
+			} else {
+				params[key] = values
+			}
+		}
+		request["params"] = params
+	}
+
+	if cfg := GetConfiguration(); cfg != nil && cfg.SendRequestData && cfg.CaptureRequestBody {
+		if body, ok := captureRequestBody(r, cfg.MaxRequestBodyBytes); ok {
+			request["body"] = body
+		}
+	}
+
+	filter := requestSanitizeFilter()
+	return redactSensitiveHeaders(filter.Filter(request), filter)
+}
+
+// captureRequestBody reads up to maxBytes of the request body and restores
+// it so the handler can still read the whole body. JSON and form bodies are
+// parsed so key filtering applies; other bodies are returned as strings,
+// truncated to maxBytes.
+func captureRequestBody(r *http.Request, maxBytes int) (interface{}, bool) {
+	body := bufferRequestBody(r, maxBytes)
+	if body == nil {
+		return nil, false
+	}
+	return body.parse(), true
+}
+
+// BufferedRequestBody is a copy of the start of a request body, kept so it
+// can be attached to a notice only if the request fails.
+type BufferedRequestBody struct {
+	data        []byte
+	truncated   bool
+	contentType string
+}
+
+// BufferRequestBody copies up to MaxRequestBodyBytes of r's body and
+// restores it so the handler can still read the whole body. The copy is
+// only parsed and filtered when Value is called. It returns nil unless
+// CaptureRequestBodyOnError and SendRequestData are enabled.
+func BufferRequestBody(r *http.Request) *BufferedRequestBody {
+	cfg := GetConfiguration()
+	if cfg == nil || !cfg.SendRequestData || !cfg.CaptureRequestBodyOnError {
+		return nil
+	}
+	return bufferRequestBody(r, cfg.MaxRequestBodyBytes)
+}
+
+// Value returns the buffered body for use as request data, parsed like
+// CaptureRequestBody bodies and filtered with the configured filter keys.
+func (b *BufferedRequestBody) Value() interface{} {
+	if b == nil {
+		return nil
+	}
+	filtered := requestSanitizeFilter().Filter(map[string]interface{}{"body": b.parse()})
+	return filtered["body"]
+}
+
+func bufferRequestBody(r *http.Request, maxBytes int) *BufferedRequestBody {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+
+	// Read one extra byte to tell whether the body was truncated
+	buf, err := io.ReadAll(io.LimitReader(r.Body, int64(maxBytes)+1))
+	r.Body = readCloser{
+		Reader: io.MultiReader(bytes.NewReader(buf), r.Body),
+		Closer: r.Body,
+	}
+	if err != nil || len(buf) == 0 {
+		return nil
+	}
+
+	body := &BufferedRequestBody{
+		data:        buf,
+		contentType: r.Header.Get("Content-Type"),
+	}
+	if len(buf) > maxBytes {
+		body.data = buf[:maxBytes]
+		body.truncated = true
+	}
+	return body
+}
+
+// truncatedBody replaces a JSON or form body over MaxRequestBodyBytes. Its
+// prefix can't be parsed, so key filtering couldn't redact it.
+const truncatedBody = "[TRUNCATED]"
+
+// parse decodes JSON and form bodies into maps; other bodies are returned
+// as strings. A truncated JSON or form body is replaced by truncatedBody.
+func (b *BufferedRequestBody) parse() interface{} {
+	isJSON := strings.Contains(b.contentType, "json")
+	isForm := strings.HasPrefix(b.contentType, "application/x-www-form-urlencoded")
+	if b.truncated {
+		if isJSON || isForm {
+			return truncatedBody
+		}
+		return string(b.data) + "..."
+	}
+
+	switch {
+	case isJSON:
+		var parsed interface{}
+		if err := json.Unmarshal(b.data, &parsed); err == nil {
+			return parsed
+		}
+	case isForm:
+		if values, err := url.ParseQuery(string(b.data)); err == nil {
+			form := make(map[string]interface{}, len(values))
+			for key, vals := range values {
+				if len(vals) == 1 {
+					form[key] = vals[0]
+				} else {
+					form[key] = vals
+				}
+			}
+			return form
+		}
+	}
+
+	return string(b.data)
+}
+
+// readCloser combines a reader with the closer of the original body.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// WithHTTPRequest sets request information extracted from r.
+func WithHTTPRequest(r *http.Request) NotifyOption {
+	return WithRequest(RequestData(r))
+}
+
+// redactSensitiveHeaders replaces the whole value of each sensitive header
+// in request's headers with the filter's placeholder, whether the header has
+// one value or several. request must already be filtered, so headers are a
+// map[string]interface{}.
+func redactSensitiveHeaders(request map[string]interface{}, filter *SanitizeFilter) map[string]interface{} {
+	headers, ok := request["headers"].(map[string]interface{})
+	if !ok {
+		return request
+	}
+
+	placeholder := filter.Placeholder
+	if placeholder == "" {
+		placeholder = filters.FilteredValue
+	}
+	for key := range headers {
+		if isSensitiveHeader(key) {
+			headers[key] = placeholder
+		}
+	}
+	return request
+}
+
+func isSensitiveHeader(key string) bool {
+	for _, header := range sensitiveHeaders {
+		if strings.EqualFold(key, header) {
+			return true
+		}
+	}
+	return false
+}
+
+// requestSanitizeFilter returns a filter for the current configuration,
+// falling back to the default filter keys before Configure is called.
+func requestSanitizeFilter() *SanitizeFilter {
+	if cfg := GetConfiguration(); cfg != nil {
+		return newConfiguredSanitizeFilter(cfg)
+	}
+	return NewSanitizeFilter(DefaultFilterKeys)
+}
@@ -0,0 +1,237 @@
+package checkend
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestData(t *testing.T) {
+	defer Reset()
+
+	Configure(Config{APIKey: "test-key", FilterKeys: []string{"x-internal"}})
+
+	r := httptest.NewRequest("POST", "/orders?page=2&tag=a&tag=b&token=abc", nil)
+	r.Header.Set("Authorization", "Bearer secret")
+	r.Header.Set("Cookie", "session=abc")
+	r.Header.Set("X-Internal-Signature", "sig")
+	r.Header.Set("Accept", "application/json")
+
+	data := RequestData(r)
+
+	if data["url"] != "http://example.com/orders?page=2&tag=a&tag=b&token=abc" {
+		t.Errorf("Unexpected url %v", data["url"])
+	}
+	if data["method"] != "POST" {
+		t.Errorf("Expected method POST, got %v", data["method"])
+	}
+
+	headers := data["headers"].(map[string]interface{})
+	for _, key := range []string{"Authorization", "Cookie", "X-Internal-Signature"} {
+		if headers[key] != "[FILTERED]" {
+			t.Errorf("Expected header %s to be filtered, got %v", key, headers[key])
+		}
+	}
+	if headers["Accept"] != "application/json" {
+		t.Errorf("Expected Accept header to be kept, got %v", headers["Accept"])
+	}
+
+	params := data["params"].(map[string]interface{})
+	if params["page"] != "2" {
+		t.Errorf("Expected page param, got %v", params["page"])
+	}
+	if tags, ok := params["tag"].([]interface{}); !ok || len(tags) != 2 {
+		t.Errorf("Expected multi-value tag param, got %v", params["tag"])
+	}
+	if params["token"] != "[FILTERED]" {
+		t.Errorf("Expected token param to be filtered, got %v", params["token"])
+	}
+}
+
+func TestRequestDataRedactsMultiValueHeaders(t *testing.T) {
+	defer Reset()
+
+	Configure(Config{APIKey: "test-key", FilterPolicy: &FilterPolicy{Placeholder: "[REDACTED]"}})
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Add("Set-Cookie", "session=abc")
+	r.Header.Add("Set-Cookie", "csrf=def")
+	r.Header.Add("Cookie", "session=abc")
+	r.Header.Add("X-Auth-Token", "one")
+	r.Header.Add("X-Auth-Token", "two")
+	r.Header.Add("Accept", "text/html")
+	r.Header.Add("Accept", "application/json")
+
+	headers := RequestData(r)["headers"].(map[string]interface{})
+	for _, key := range []string{"Set-Cookie", "Cookie", "X-Auth-Token"} {
+		if headers[key] != "[REDACTED]" {
+			t.Errorf("Expected header %s to be fully redacted, got %v", key, headers[key])
+		}
+	}
+	if accept, ok := headers["Accept"].([]interface{}); !ok || len(accept) != 2 {
+		t.Errorf("Expected multi-value Accept header to be kept, got %v", headers["Accept"])
+	}
+}
+
+func TestNoticeRedactsMultiValueHeadersFromRequestMaps(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{APIKey: "test-key", Enabled: boolPtr(true)})
+
+	Notify(errors.New("boom"), WithRequest(map[string]interface{}{
+		"headers": map[string]interface{}{
+			"Set-Cookie": []interface{}{"session=abc", "csrf=def"},
+			"cookie":     []string{"session=abc"},
+			"Accept":     []string{"text/html", "application/json"},
+		},
+	}))
+
+	headers := TestingLastNotice().Request["headers"].(map[string]interface{})
+	for _, key := range []string{"Set-Cookie", "cookie"} {
+		if headers[key] != "[FILTERED]" {
+			t.Errorf("Expected header %s to be fully redacted, got %v", key, headers[key])
+		}
+	}
+	if accept, ok := headers["Accept"].([]interface{}); !ok || len(accept) != 2 {
+		t.Errorf("Expected multi-value Accept header to be kept, got %v", headers["Accept"])
+	}
+}
+
+func TestWithHTTPRequest(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{APIKey: "test-key", Enabled: boolPtr(true)})
+
+	r := httptest.NewRequest("GET", "/users/1", nil)
+	Notify(errors.New("test error"), WithHTTPRequest(r))
+
+	notice := TestingLastNotice()
+	if notice.Request["method"] != "GET" {
+		t.Errorf("Expected request method GET, got %v", notice.Request["method"])
+	}
+}
+
+func TestRequestDataCapturesJSONBody(t *testing.T) {
+	defer Reset()
+
+	Configure(Config{APIKey: "test-key", CaptureRequestBody: true})
+
+	body := `{"email": "jane@example.com", "password": "hunter2"}`
+	r := httptest.NewRequest("POST", "/signup", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+
+	data := RequestData(r)
+
+	captured, ok := data["body"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected JSON body to be parsed, got %T", data["body"])
+	}
+	if captured["email"] != "jane@example.com" || captured["password"] != "[FILTERED]" {
+		t.Errorf("Unexpected captured body %v", captured)
+	}
+
+	restored, _ := io.ReadAll(r.Body)
+	if string(restored) != body {
+		t.Errorf("Expected body to be restored, got %q", restored)
+	}
+}
+
+func TestRequestDataCapturesFormBody(t *testing.T) {
+	defer Reset()
+
+	Configure(Config{APIKey: "test-key", CaptureRequestBody: true})
+
+	r := httptest.NewRequest("POST", "/login", strings.NewReader("user=jane&password=hunter2"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	captured := RequestData(r)["body"].(map[string]interface{})
+	if captured["user"] != "jane" || captured["password"] != "[FILTERED]" {
+		t.Errorf("Unexpected captured body %v", captured)
+	}
+}
+
+func TestRequestDataTruncatesLargeBody(t *testing.T) {
+	defer Reset()
+
+	Configure(Config{APIKey: "test-key", CaptureRequestBody: true, MaxRequestBodyBytes: 8})
+
+	body := strings.Repeat("x", 20)
+	r := httptest.NewRequest("POST", "/upload", strings.NewReader(body))
+
+	if captured := RequestData(r)["body"]; captured != "xxxxxxxx..." {
+		t.Errorf("Expected truncated body, got %v", captured)
+	}
+
+	restored, _ := io.ReadAll(r.Body)
+	if string(restored) != body {
+		t.Errorf("Expected full body to be restored, got %q", restored)
+	}
+}
+
+func TestRequestDataDropsTruncatedJSONBody(t *testing.T) {
+	defer Reset()
+
+	Configure(Config{APIKey: "test-key", CaptureRequestBody: true, MaxRequestBodyBytes: 32})
+
+	body := `{"user": "jane", "password": "hunter2", "bio": "` + strings.Repeat("x", 64) + `"}`
+	r := httptest.NewRequest("POST", "/signup", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+
+	captured := RequestData(r)["body"]
+	if captured != "[TRUNCATED]" {
+		t.Errorf("Expected truncated JSON body to be replaced, got %v", captured)
+	}
+	if s, _ := captured.(string); strings.Contains(s, "hunter2") {
+		t.Errorf("Expected password not to be sent, got %v", captured)
+	}
+}
+
+func TestRequestDataBodyCaptureDisabled(t *testing.T) {
+	defer Reset()
+
+	Configure(Config{APIKey: "test-key"})
+
+	r := httptest.NewRequest("POST", "/signup", strings.NewReader(`{"a": 1}`))
+	if _, ok := RequestData(r)["body"]; ok {
+		t.Error("Expected no body without CaptureRequestBody")
+	}
+
+	Configure(Config{APIKey: "test-key", CaptureRequestBody: true, SendRequestData: boolPtr(false)})
+	if _, ok := RequestData(r)["body"]; ok {
+		t.Error("Expected no body when SendRequestData is false")
+	}
+}
+
+func TestBufferRequestBody(t *testing.T) {
+	defer Reset()
+
+	r := httptest.NewRequest("POST", "/upload", strings.NewReader("abc"))
+	Configure(Config{APIKey: "test-key"})
+	if BufferRequestBody(r) != nil {
+		t.Error("Expected no buffering without CaptureRequestBodyOnError")
+	}
+
+	Configure(Config{APIKey: "test-key", CaptureRequestBodyOnError: true, MaxRequestBodyBytes: 4})
+	body := strings.Repeat("x", 10)
+	r = httptest.NewRequest("POST", "/upload", strings.NewReader(body))
+
+	buffered := BufferRequestBody(r)
+	if buffered == nil {
+		t.Fatal("Expected the body to be buffered")
+	}
+	if _, ok := RequestData(r)["body"]; ok {
+		t.Error("Expected RequestData not to capture the body eagerly")
+	}
+	if value := buffered.Value(); value != "xxxx..." {
+		t.Errorf("Expected truncated body, got %v", value)
+	}
+
+	restored, _ := io.ReadAll(r.Body)
+	if string(restored) != body {
+		t.Errorf("Expected full body to be restored, got %q", restored)
+	}
+}
@@ -0,0 +1,50 @@
+package checkend
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// ResourceLeakError is reported when a tracked resource is garbage
+// collected without being released.
+type ResourceLeakError struct {
+	Name string
+}
+
+func (e *ResourceLeakError) Error() string {
+	return fmt.Sprintf("resource leaked: %s", e.Name)
+}
+
+// TrackResource reports a notice tagged "resource_leak" if obj is garbage
+// collected before ReleaseResource is called for it, e.g. a connection or
+// file that was never closed. obj must be a pointer to an allocated object.
+// The notice's backtrace is the stack that called TrackResource.
+//
+// Leaks are only detected when the garbage collector runs, so reports may
+// arrive long after the object became unreachable.
+//
+// Tracking sets a finalizer on obj with runtime.SetFinalizer, and an object
+// can only have one. Don't track objects that already have a finalizer,
+// and don't set one on a tracked object; the runtime throws a fatal error
+// if obj already has one. Tracking the same object twice has the same
+// effect. ReleaseResource removes the finalizer.
+func TrackResource(obj interface{}, name string) {
+	pcs := make([]uintptr, maxBacktraceLines)
+	n := runtime.Callers(2, pcs)
+	pcs = pcs[:n]
+
+	runtime.SetFinalizer(obj, func(interface{}) {
+		Notify(
+			&ResourceLeakError{Name: name},
+			WithTags("resource_leak"),
+			WithContext(map[string]interface{}{"resource": name}),
+			WithBacktrace(pcs),
+		)
+	})
+}
+
+// ReleaseResource stops tracking a resource passed to TrackResource by
+// clearing its finalizer, after which obj may be tracked again.
+func ReleaseResource(obj interface{}) {
+	runtime.SetFinalizer(obj, nil)
+}
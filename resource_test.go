@@ -0,0 +1,75 @@
+package checkend
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+type trackedConn struct {
+	id   int
+	name *string
+}
+
+func trackConn(release bool) {
+	name := "db"
+	conn := &trackedConn{id: 1, name: &name}
+	TrackResource(conn, "db connection")
+	if release {
+		ReleaseResource(conn)
+	}
+}
+
+func waitForGC(t *testing.T, done func() bool) bool {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		runtime.GC()
+		if done() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+func TestTrackResourceReportsLeak(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{APIKey: "test-key", Enabled: boolPtr(true)})
+
+	trackConn(false)
+
+	if !waitForGC(t, TestingHasNotices) {
+		t.Fatal("Expected a leak notice after the resource was collected")
+	}
+
+	notice := TestingLastNotice()
+	if notice.Message != "resource leaked: db connection" {
+		t.Errorf("Expected leak message, got '%s'", notice.Message)
+	}
+	if len(notice.Tags) != 1 || notice.Tags[0] != "resource_leak" {
+		t.Errorf("Expected resource_leak tag, got %v", notice.Tags)
+	}
+	if notice.Context["resource"] != "db connection" {
+		t.Errorf("Expected resource name in context, got %v", notice.Context["resource"])
+	}
+}
+
+func TestTrackResourceReleased(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{APIKey: "test-key", Enabled: boolPtr(true)})
+
+	trackConn(true)
+
+	for i := 0; i < 5; i++ {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+	if TestingHasNotices() {
+		t.Error("Expected no leak notice for a released resource")
+	}
+}
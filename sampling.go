@@ -0,0 +1,155 @@
+package checkend
+
+import (
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// lockedRand is a random source that is safe for concurrent use.
+type lockedRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func newLockedRand(seed int64) *lockedRand {
+	return &lockedRand{r: rand.New(rand.NewSource(seed))} //nolint:gosec // Sampling does not need a secure source
+}
+
+// Float64 returns a pseudo-random number in [0.0, 1.0).
+func (l *lockedRand) Float64() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Float64()
+}
+
+// Seed resets the source, making subsequent values deterministic.
+func (l *lockedRand) Seed(seed int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.r = rand.New(rand.NewSource(seed)) //nolint:gosec // Sampling does not need a secure source
+}
+
+var random = newLockedRand(time.Now().UnixNano())
+
+// shouldSample decides whether a notice is sent based on the configured
+// sample rates, then on the adaptive rate if TargetNoticesPerSecond is set.
+func shouldSample(config *Configuration, notice *Notice) bool {
+	if !sampled(sampleRate(config, notice.ErrorClass)) {
+		return false
+	}
+	return config.sampler.sample()
+}
+
+// sampled makes a random decision at the given rate.
+func sampled(rate float64) bool {
+	if rate >= 1 {
+		return true
+	}
+	if rate <= 0 {
+		return false
+	}
+	return random.Float64() < rate
+}
+
+// adaptiveSamplerWindow is the period over which notice arrivals are
+// counted before the arrival rate estimate is updated.
+const adaptiveSamplerWindow = time.Second
+
+// adaptiveSampler lowers the sample rate while notices arrive faster than
+// a target rate. It keeps a moving average of the arrival rate, updated once
+// per window, and samples at target/rate. A nil sampler samples everything.
+type adaptiveSampler struct {
+	mu          sync.Mutex
+	target      float64
+	arrivalRate float64
+	windowStart time.Time
+	arrivals    int
+	now         func() time.Time
+}
+
+func newAdaptiveSampler(target float64) *adaptiveSampler {
+	if target <= 0 {
+		return nil
+	}
+	return &adaptiveSampler{target: target, now: time.Now}
+}
+
+// sample records an arrival and decides whether it is sent.
+func (s *adaptiveSampler) sample() bool {
+	if s == nil {
+		return true
+	}
+
+	s.mu.Lock()
+	s.advance()
+	s.arrivals++
+	rate := s.rateLocked()
+	s.mu.Unlock()
+
+	return sampled(rate)
+}
+
+// rate returns the sample rate currently applied.
+func (s *adaptiveSampler) rate() float64 {
+	if s == nil {
+		return 1
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.advance()
+	return s.rateLocked()
+}
+
+// advance folds completed windows into the arrival rate average.
+func (s *adaptiveSampler) advance() {
+	now := s.now()
+	if s.windowStart.IsZero() {
+		s.windowStart = now
+		return
+	}
+
+	elapsed := now.Sub(s.windowStart)
+	if elapsed < adaptiveSamplerWindow {
+		return
+	}
+
+	observed := float64(s.arrivals) / elapsed.Seconds()
+	s.arrivalRate = (s.arrivalRate + observed) / 2
+	s.arrivals = 0
+	s.windowStart = now
+}
+
+// rateLocked returns target/arrival rate, capped at 1. Arrivals in the
+// current window count as soon as they exceed the average, so a burst is
+// throttled before its window completes.
+func (s *adaptiveSampler) rateLocked() float64 {
+	arrivalRate := s.arrivalRate
+	if current := float64(s.arrivals) / adaptiveSamplerWindow.Seconds(); current > arrivalRate {
+		arrivalRate = current
+	}
+	if arrivalRate <= s.target {
+		return 1
+	}
+	return s.target / arrivalRate
+}
+
+// sampleRate returns the rate for an error class, falling back to the
+// global SampleRate.
+func sampleRate(cfg *Configuration, class string) float64 {
+	if rate, ok := cfg.SampleRatesByClass[class]; ok {
+		return rate
+	}
+
+	// Match the unqualified type name, e.g. "PaymentError" for
+	// "github.com/acme/billing.PaymentError"
+	if i := strings.LastIndex(class, "."); i >= 0 {
+		if rate, ok := cfg.SampleRatesByClass[class[i+1:]]; ok {
+			return rate
+		}
+	}
+
+	return cfg.SampleRate
+}
@@ -0,0 +1,166 @@
+package checkend
+
+import (
+	"testing"
+	"time"
+)
+
+type paymentError struct{}
+
+func (e *paymentError) Error() string { return "payment failed" }
+
+type timeoutError struct{}
+
+func (e *timeoutError) Error() string { return "timed out" }
+
+func TestSampleRatesByClass(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{
+		APIKey:    "test-key",
+		Enabled:   boolPtr(true),
+		AsyncSend: false,
+		SampleRatesByClass: map[string]float64{
+			"paymentError": 1.0,
+			"timeoutError": 0.05,
+		},
+	})
+	random.Seed(42)
+
+	for i := 0; i < 1000; i++ {
+		Notify(&paymentError{})
+	}
+	if TestingNoticeCount() != 1000 {
+		t.Errorf("Expected all 1000 payment errors, got %d", TestingNoticeCount())
+	}
+
+	TestingClearNotices()
+	for i := 0; i < 1000; i++ {
+		Notify(&timeoutError{})
+	}
+	count := TestingNoticeCount()
+	if count < 25 || count > 75 {
+		t.Errorf("Expected roughly 50 of 1000 timeout errors, got %d", count)
+	}
+}
+
+func TestSampleRateFallsBackToGlobal(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{
+		APIKey:             "test-key",
+		Enabled:            boolPtr(true),
+		AsyncSend:          false,
+		SampleRate:         0.5,
+		SampleRatesByClass: map[string]float64{"paymentError": 1.0},
+	})
+	random.Seed(7)
+
+	for i := 0; i < 1000; i++ {
+		Notify(&timeoutError{})
+	}
+	count := TestingNoticeCount()
+	if count < 400 || count > 600 {
+		t.Errorf("Expected roughly 500 of 1000 notices, got %d", count)
+	}
+}
+
+func TestSampleRateZeroClassDropsAll(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{
+		APIKey:             "test-key",
+		Enabled:            boolPtr(true),
+		AsyncSend:          false,
+		SampleRatesByClass: map[string]float64{"timeoutError": 0},
+	})
+
+	Notify(&timeoutError{})
+	if TestingHasNotices() {
+		t.Error("Expected class with rate 0 to be dropped")
+	}
+}
+
+func TestSampleRateDefault(t *testing.T) {
+	cfg := NewConfiguration(Config{APIKey: "test-key"})
+	if cfg.SampleRate != 1 {
+		t.Errorf("Expected default sample rate 1, got %v", cfg.SampleRate)
+	}
+}
+
+func TestAdaptiveSamplerConvergesToTarget(t *testing.T) {
+	random.Seed(7)
+
+	now := time.Unix(0, 0)
+	sampler := newAdaptiveSampler(10)
+	sampler.now = func() time.Time { return now }
+
+	// 200 notices per second for 20 seconds
+	sentPerSecond := make([]int, 20)
+	for second := range sentPerSecond {
+		for i := 0; i < 200; i++ {
+			if sampler.sample() {
+				sentPerSecond[second]++
+			}
+			now = now.Add(5 * time.Millisecond)
+		}
+	}
+
+	total := 0
+	for _, sent := range sentPerSecond[5:] {
+		total += sent
+	}
+	average := float64(total) / float64(len(sentPerSecond[5:]))
+	if average < 7 || average > 13 {
+		t.Errorf("Expected the send rate to converge near 10/s, got %.1f/s (%v)", average, sentPerSecond)
+	}
+	if rate := sampler.rate(); rate < 0.03 || rate > 0.07 {
+		t.Errorf("Expected an effective rate near 0.05, got %f", rate)
+	}
+}
+
+func TestAdaptiveSamplerSendsEverythingBelowTarget(t *testing.T) {
+	now := time.Unix(0, 0)
+	sampler := newAdaptiveSampler(10)
+	sampler.now = func() time.Time { return now }
+
+	for i := 0; i < 50; i++ {
+		if !sampler.sample() {
+			t.Fatalf("Expected notice %d to be sent below the target rate", i)
+		}
+		now = now.Add(200 * time.Millisecond)
+	}
+	if sampler.rate() != 1 {
+		t.Errorf("Expected an effective rate of 1, got %f", sampler.rate())
+	}
+}
+
+func TestTargetNoticesPerSecondThrottlesBursts(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{
+		APIKey:                 "test-key",
+		Enabled:                boolPtr(true),
+		TargetNoticesPerSecond: 5,
+	})
+	random.Seed(42)
+
+	if Stats().EffectiveSampleRate != 1 {
+		t.Errorf("Expected an effective rate of 1 before any traffic, got %f", Stats().EffectiveSampleRate)
+	}
+
+	for i := 0; i < 500; i++ {
+		Notify(&timeoutError{})
+	}
+
+	if count := TestingNoticeCount(); count >= 100 {
+		t.Errorf("Expected the burst to be throttled, got %d of 500 notices", count)
+	}
+	if rate := Stats().EffectiveSampleRate; rate >= 0.1 {
+		t.Errorf("Expected a lowered effective rate, got %f", rate)
+	}
+}
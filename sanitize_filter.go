@@ -12,9 +12,24 @@ func NewSanitizeFilter(filterKeys []string) *SanitizeFilter {
 	return filters.NewSanitizeFilter(filterKeys)
 }
 
+// newConfiguredSanitizeFilter creates a SanitizeFilter applying every
+// filtering option of the configuration.
+func newConfiguredSanitizeFilter(config *Configuration) *SanitizeFilter {
+	f := NewSanitizeFilter(config.FilterKeys)
+	f.KeyPatterns = config.FilterKeyPatterns
+	f.ValuePatterns = config.ValueFilters
+	f.AllowKeys = config.FilterAllowlist
+	f.Placeholder = config.FilterPlaceholder
+	f.ExactKeys = config.ExactFilterKeys
+	return f
+}
+
 // IgnoreFilter wraps the filters package IgnoreFilter for internal use.
 type IgnoreFilter = filters.IgnoreFilter
 
+// IgnoreRule ignores errors by type name and/or message.
+type IgnoreRule = filters.IgnoreRule
+
 // NewIgnoreFilter creates a new IgnoreFilter.
 func NewIgnoreFilter(patterns []interface{}) *IgnoreFilter {
 	return filters.NewIgnoreFilter(patterns)
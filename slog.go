@@ -0,0 +1,196 @@
+package checkend
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"strings"
+)
+
+// SlogHandler is a slog.Handler that passes every record to a wrapped
+// handler and reports records at or above a minimum level to Checkend.
+type SlogHandler struct {
+	next   slog.Handler
+	level  slog.Leveler
+	attrs  map[string]interface{}
+	groups []string
+}
+
+// SlogOption configures a SlogHandler.
+type SlogOption func(*SlogHandler)
+
+// WithSlogLevel sets the minimum level reported to Checkend (default: slog.LevelError).
+func WithSlogLevel(level slog.Leveler) SlogOption {
+	return func(h *SlogHandler) {
+		h.level = level
+	}
+}
+
+// NewSlogHandler wraps next with Checkend reporting.
+//
+// Usage:
+//
+//	logger := slog.New(checkend.NewSlogHandler(slog.NewJSONHandler(os.Stderr, nil)))
+//	logger.Error("charge failed", "error", err, "order_id", orderID)
+//
+// Record attributes are added to the notice context. An "error" or "err"
+// attribute holding an error value becomes the reported error; otherwise the
+// record message is reported.
+func NewSlogHandler(next slog.Handler, opts ...SlogOption) *SlogHandler {
+	h := &SlogHandler{
+		next:  next,
+		level: slog.LevelError,
+		attrs: make(map[string]interface{}),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Enabled reports whether either the wrapped handler or Checkend handles level.
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level() || h.next.Enabled(ctx, level)
+}
+
+// Handle passes the record to the wrapped handler and reports it to Checkend
+// if it is at or above the configured level.
+func (h *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level >= h.level.Level() {
+		h.notify(ctx, record)
+	}
+
+	if !h.next.Enabled(ctx, record.Level) {
+		return nil
+	}
+	return h.next.Handle(ctx, record)
+}
+
+// WithAttrs returns a handler that includes attrs in every record.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := h.clone()
+	clone.next = h.next.WithAttrs(attrs)
+	target := clone.groupMap()
+	for _, attr := range attrs {
+		addSlogAttr(target, attr)
+	}
+	return clone
+}
+
+// WithGroup returns a handler that nests subsequent attributes under name.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := h.clone()
+	clone.next = h.next.WithGroup(name)
+	clone.groups = append(clone.groups, name)
+	return clone
+}
+
+func (h *SlogHandler) notify(ctx context.Context, record slog.Record) {
+	clone := h.clone()
+	target := clone.groupMap()
+
+	var err error
+	record.Attrs(func(attr slog.Attr) bool {
+		if err == nil && len(h.groups) == 0 && (attr.Key == "error" || attr.Key == "err") {
+			if e, ok := attr.Value.Resolve().Any().(error); ok {
+				err = e
+				return true
+			}
+		}
+		addSlogAttr(target, attr)
+		return true
+	})
+
+	opts := []NotifyOption{
+		WithSeverity(strings.ToLower(record.Level.String())),
+		WithContext(clone.attrs),
+	}
+
+	if err == nil {
+		err = &messageError{message: record.Message}
+		opts = append(opts, WithErrorClass("Message"))
+	} else if record.Message != "" {
+		clone.attrs["log_message"] = record.Message
+	}
+
+	if record.PC != 0 {
+		opts = append(opts, WithBacktrace(callersFrom(record.PC)))
+	}
+
+	NotifyWithContext(ctx, err, opts...)
+}
+
+// clone returns a deep copy of the handler's accumulated attributes.
+func (h *SlogHandler) clone() *SlogHandler {
+	return &SlogHandler{
+		next:   h.next,
+		level:  h.level,
+		attrs:  copySlogMap(h.attrs),
+		groups: append([]string{}, h.groups...),
+	}
+}
+
+// groupMap returns the map attributes are added to for the current group.
+func (h *SlogHandler) groupMap() map[string]interface{} {
+	target := h.attrs
+	for _, group := range h.groups {
+		nested, ok := target[group].(map[string]interface{})
+		if !ok {
+			nested = make(map[string]interface{})
+			target[group] = nested
+		}
+		target = nested
+	}
+	return target
+}
+
+func addSlogAttr(target map[string]interface{}, attr slog.Attr) {
+	value := attr.Value.Resolve()
+	if value.Kind() != slog.KindGroup {
+		if attr.Key != "" {
+			target[attr.Key] = value.Any()
+		}
+		return
+	}
+
+	// Inline groups with empty keys, per the slog.Handler contract
+	group := target
+	if attr.Key != "" {
+		nested, ok := target[attr.Key].(map[string]interface{})
+		if !ok {
+			nested = make(map[string]interface{})
+			target[attr.Key] = nested
+		}
+		group = nested
+	}
+	for _, a := range value.Group() {
+		addSlogAttr(group, a)
+	}
+}
+
+func copySlogMap(m map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if nested, ok := v.(map[string]interface{}); ok {
+			v = copySlogMap(nested)
+		}
+		result[k] = v
+	}
+	return result
+}
+
+// callersFrom returns the current goroutine's stack starting at the frame
+// with the given program counter, falling back to that single frame.
+func callersFrom(pc uintptr) []uintptr {
+	pcs := make([]uintptr, maxBacktraceLines+16)
+	n := runtime.Callers(2, pcs)
+	for i, p := range pcs[:n] {
+		if p == pc {
+			return pcs[i:n]
+		}
+	}
+	return []uintptr{pc}
+}
@@ -0,0 +1,134 @@
+package checkend
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func setupSlogTest(t *testing.T) (*slog.Logger, *bytes.Buffer) {
+	t.Helper()
+
+	SetupTesting()
+	Configure(Config{
+		APIKey:    "test-key",
+		Enabled:   boolPtr(true),
+		AsyncSend: false,
+	})
+	t.Cleanup(Reset)
+
+	var out bytes.Buffer
+	return slog.New(NewSlogHandler(slog.NewTextHandler(&out, nil))), &out
+}
+
+func TestSlogHandlerReportsErrors(t *testing.T) {
+	logger, out := setupSlogTest(t)
+
+	logger.Error("charge failed", "error", errors.New("card declined"), "order_id", 42)
+
+	if !strings.Contains(out.String(), "charge failed") {
+		t.Errorf("Expected record to reach wrapped handler, got '%s'", out.String())
+	}
+
+	notice := TestingLastNotice()
+	if notice == nil {
+		t.Fatal("Expected a notice to be captured")
+	}
+	if notice.Message != "card declined" {
+		t.Errorf("Expected message 'card declined', got '%s'", notice.Message)
+	}
+	if notice.Context["order_id"] != int64(42) {
+		t.Errorf("Expected order_id 42, got %v", notice.Context["order_id"])
+	}
+	if notice.Context["log_message"] != "charge failed" {
+		t.Errorf("Expected log_message 'charge failed', got %v", notice.Context["log_message"])
+	}
+	if _, ok := notice.Context["error"]; ok {
+		t.Error("Expected error attribute to be removed from context")
+	}
+	if notice.Severity != "error" {
+		t.Errorf("Expected severity 'error', got '%s'", notice.Severity)
+	}
+}
+
+func TestSlogHandlerIgnoresLowerLevels(t *testing.T) {
+	logger, out := setupSlogTest(t)
+
+	logger.Info("all good")
+	logger.Warn("careful")
+
+	if TestingHasNotices() {
+		t.Error("Expected no notices below error level")
+	}
+	if !strings.Contains(out.String(), "careful") {
+		t.Error("Expected records to reach wrapped handler")
+	}
+}
+
+func TestSlogHandlerMessageOnly(t *testing.T) {
+	logger, _ := setupSlogTest(t)
+
+	logger.Error("queue is stuck")
+
+	notice := TestingLastNotice()
+	if notice.ErrorClass != "Message" {
+		t.Errorf("Expected error class 'Message', got '%s'", notice.ErrorClass)
+	}
+	if notice.Message != "queue is stuck" {
+		t.Errorf("Expected message 'queue is stuck', got '%s'", notice.Message)
+	}
+}
+
+func TestSlogHandlerGroupsAndAttrs(t *testing.T) {
+	logger, _ := setupSlogTest(t)
+
+	logger.With("service", "billing").WithGroup("request").Error("failed", "path", "/charge")
+
+	notice := TestingLastNotice()
+	if notice.Context["service"] != "billing" {
+		t.Errorf("Expected service 'billing', got %v", notice.Context["service"])
+	}
+	request, ok := notice.Context["request"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected request group, got %v", notice.Context["request"])
+	}
+	if request["path"] != "/charge" {
+		t.Errorf("Expected path '/charge', got %v", request["path"])
+	}
+}
+
+func TestSlogHandlerCustomLevel(t *testing.T) {
+	SetupTesting()
+	Configure(Config{
+		APIKey:    "test-key",
+		Enabled:   boolPtr(true),
+		AsyncSend: false,
+	})
+	defer Reset()
+
+	var out bytes.Buffer
+	logger := slog.New(NewSlogHandler(slog.NewTextHandler(&out, nil), WithSlogLevel(slog.LevelWarn)))
+	logger.Warn("disk almost full")
+
+	notice := TestingLastNotice()
+	if notice == nil {
+		t.Fatal("Expected warning to be reported")
+	}
+	if notice.Severity != "warn" {
+		t.Errorf("Expected severity 'warn', got '%s'", notice.Severity)
+	}
+}
+
+func TestSlogHandlerBacktraceStartsAtCallSite(t *testing.T) {
+	logger, _ := setupSlogTest(t)
+
+	logger.Error("failed")
+
+	for _, line := range TestingLastNotice().Backtrace {
+		if strings.Contains(line, "log/slog") {
+			t.Errorf("Expected slog frames to be excluded, found %s", line)
+		}
+	}
+}
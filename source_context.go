@@ -0,0 +1,126 @@
+package checkend
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+)
+
+// MaxSourceContextBytes caps the bytes of source read for a single notice
+// when SourceContextLines is set.
+const MaxSourceContextBytes = 1 << 20
+
+// sourceFile is the part of a file read for source context.
+type sourceFile struct {
+	lines []string
+
+	// complete is false if reading stopped early, so lines past the end
+	// of lines may exist but weren't read.
+	complete bool
+
+	// size is the number of bytes deducted from the budget for lines.
+	size int
+}
+
+// unreadableSource stands in for a file that couldn't be read, so it isn't
+// tried again for later frames.
+var unreadableSource = &sourceFile{complete: true}
+
+// isSourceFrame reports whether source context is read for frame: frames
+// in AppPackages when it is set, otherwise frames outside the standard
+// library, the module cache, and vendor directories.
+func (b *NoticeBuilder) isSourceFrame(frame BacktraceFrame) bool {
+	if len(b.config.AppPackages) > 0 {
+		return frame.InApp
+	}
+	return !isLibraryFile(frame.File)
+}
+
+// addSourceContext attaches the source around each application frame's
+// line, reading each file once and at most MaxSourceContextBytes in total.
+// Frames whose file can't be read are left unchanged.
+func (b *NoticeBuilder) addSourceContext(frames []BacktraceFrame) {
+	n := b.config.SourceContextLines
+	budget := MaxSourceContextBytes
+	files := make(map[string]*sourceFile)
+
+	for i := range frames {
+		frame := &frames[i]
+		if !b.isSourceFrame(*frame) || frame.Line <= 0 || frame.File == "" {
+			continue
+		}
+
+		path := b.sourcePath(frame.File)
+		file, ok := files[path]
+		if !ok || !file.complete && len(file.lines) < frame.Line+n {
+			if ok {
+				// An earlier frame needed fewer lines; read further,
+				// counting the lines read before only once
+				budget += file.size
+			}
+			file = readSourceLines(path, frame.Line+n, &budget)
+			if file == nil {
+				file = unreadableSource
+			}
+			files[path] = file
+		}
+		if frame.Line > len(file.lines) {
+			continue
+		}
+
+		line := frame.Line - 1
+		start := line - n
+		if start < 0 {
+			start = 0
+		}
+		end := line + 1 + n
+		if end > len(file.lines) {
+			end = len(file.lines)
+		}
+
+		frame.PreContext = append([]string{}, file.lines[start:line]...)
+		frame.ContextLine = file.lines[line]
+		frame.PostContext = append([]string{}, file.lines[line+1:end]...)
+	}
+}
+
+// sourcePath returns the path to read for a backtrace file, undoing the
+// RootPath cleaning of relative paths.
+func (b *NoticeBuilder) sourcePath(file string) string {
+	if filepath.IsAbs(file) || b.config.RootPath == "" {
+		return file
+	}
+	return filepath.Join(b.config.RootPath, file)
+}
+
+// readSourceLines reads up to maxLines lines of path, deducting the bytes
+// read from budget. It returns nil if the file can't be read or the budget
+// is spent.
+func readSourceLines(path string, maxLines int, budget *int) *sourceFile {
+	if *budget <= 0 {
+		return nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	file := &sourceFile{}
+	scanner := bufio.NewScanner(f)
+	for len(file.lines) < maxLines {
+		if !scanner.Scan() {
+			file.complete = scanner.Err() == nil
+			return file
+		}
+		size := len(scanner.Bytes()) + 1
+		*budget -= size
+		file.size += size
+		if *budget < 0 {
+			return file
+		}
+		file.lines = append(file.lines, scanner.Text())
+	}
+	return file
+}
@@ -0,0 +1,157 @@
+package checkend
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeSourceFile(t *testing.T, dir string, lines int) string {
+	t.Helper()
+	var b strings.Builder
+	for i := 1; i <= lines; i++ {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte(b.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSourceContextLines(t *testing.T) {
+	defer Reset()
+
+	dir := t.TempDir()
+	path := writeSourceFile(t, dir, 10)
+
+	SetupTesting()
+	Configure(Config{
+		APIKey:             "test-key",
+		Enabled:            boolPtr(true),
+		AppPackages:        []string{"example.com/app"},
+		SourceContextLines: 2,
+	})
+
+	Notify(errors.New("boom"), WithStackTrace([]string{
+		path + ":5 in example.com/app.run",
+		path + ":1 in example.com/app.main",
+		path + ":10 in example.com/app.main",
+		path + ":5 in example.com/lib.Call",
+		filepath.Join(dir, "missing.go") + ":5 in example.com/app.gone",
+	}))
+
+	frames := TestingLastNotice().Frames
+	if len(frames) != 5 {
+		t.Fatalf("Expected 5 frames, got %d", len(frames))
+	}
+
+	expected := []struct {
+		pre     []string
+		context string
+		post    []string
+	}{
+		{[]string{"line 3", "line 4"}, "line 5", []string{"line 6", "line 7"}},
+		{[]string{}, "line 1", []string{"line 2", "line 3"}},
+		{[]string{"line 8", "line 9"}, "line 10", []string{}},
+	}
+	for i, want := range expected {
+		frame := frames[i]
+		if !reflect.DeepEqual(frame.PreContext, want.pre) || frame.ContextLine != want.context || !reflect.DeepEqual(frame.PostContext, want.post) {
+			t.Errorf("Frame %d: expected %v / %q / %v, got %v / %q / %v",
+				i, want.pre, want.context, want.post, frame.PreContext, frame.ContextLine, frame.PostContext)
+		}
+	}
+
+	if frames[3].ContextLine != "" {
+		t.Errorf("Expected no source context for a library frame, got %q", frames[3].ContextLine)
+	}
+	if frames[4].ContextLine != "" || frames[4].PreContext != nil {
+		t.Errorf("Expected no source context for a missing file, got %+v", frames[4])
+	}
+}
+
+func TestSourceContextSkipsMissingFile(t *testing.T) {
+	defer Reset()
+
+	missing := filepath.Join(t.TempDir(), "app", "main.go")
+
+	SetupTesting()
+	Configure(Config{
+		APIKey:             "test-key",
+		Enabled:            boolPtr(true),
+		AppPackages:        []string{"example.com/app"},
+		SourceContextLines: 2,
+	})
+	Notify(errors.New("boom"), WithStackTrace([]string{
+		missing + ":5 in example.com/app.run",
+		missing + ":20 in example.com/app.main",
+	}))
+
+	notice := TestingLastNotice()
+	if notice == nil {
+		t.Fatal("Expected a notice")
+	}
+	for i, frame := range notice.Frames {
+		if frame.ContextLine != "" || frame.PreContext != nil || frame.PostContext != nil {
+			t.Errorf("Frame %d: expected no source context for a missing file, got %+v", i, frame)
+		}
+	}
+}
+
+func TestSourceContextUsesRootPath(t *testing.T) {
+	defer Reset()
+
+	dir := t.TempDir()
+	path := writeSourceFile(t, dir, 3)
+
+	SetupTesting()
+	Configure(Config{
+		APIKey:             "test-key",
+		Enabled:            boolPtr(true),
+		RootPath:           dir,
+		AppPackages:        []string{"example.com/app"},
+		SourceContextLines: 1,
+	})
+	Notify(errors.New("boom"), WithStackTrace([]string{path + ":2 in example.com/app.run"}))
+
+	frame := TestingLastNotice().Frames[0]
+	if frame.File != "main.go" {
+		t.Errorf("Expected the cleaned file, got %q", frame.File)
+	}
+	if frame.ContextLine != "line 2" {
+		t.Errorf("Expected source context for a cleaned path, got %q", frame.ContextLine)
+	}
+}
+
+func TestSourceContextOffByDefault(t *testing.T) {
+	defer Reset()
+
+	path := writeSourceFile(t, t.TempDir(), 3)
+
+	SetupTesting()
+	Configure(Config{APIKey: "test-key", Enabled: boolPtr(true), AppPackages: []string{"example.com/app"}})
+	Notify(errors.New("boom"), WithStackTrace([]string{path + ":2 in example.com/app.run"}))
+
+	if frame := TestingLastNotice().Frames[0]; frame.ContextLine != "" {
+		t.Errorf("Expected no source context by default, got %q", frame.ContextLine)
+	}
+}
+
+func TestReadSourceLinesBudget(t *testing.T) {
+	path := writeSourceFile(t, t.TempDir(), 10)
+
+	budget := len("line 1\nline 2\n")
+	file := readSourceLines(path, 10, &budget)
+	if file == nil || len(file.lines) != 2 || file.complete {
+		t.Fatalf("Expected reading to stop after 2 lines, got %+v", file)
+	}
+
+	if file := readSourceLines(path, 10, &budget); file != nil {
+		t.Errorf("Expected nothing to be read once the budget is spent, got %+v", file)
+	}
+}
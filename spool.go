@@ -0,0 +1,241 @@
+package checkend
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultMaxSpoolFiles is the default maximum number of notices kept in the spool.
+const DefaultMaxSpoolFiles = 100
+
+// maxSpoolAttempts is the number of times a spooled notice is retried
+// before it is discarded.
+const maxSpoolAttempts = 3
+
+// staleSpoolFileAge is how old a temporary or claimed spool file must be
+// before it is assumed to belong to a process that crashed.
+const staleSpoolFileAge = time.Hour
+
+// inflightSuffix marks a spool file claimed by a running worker, so other
+// processes sharing the spool directory don't replay it too.
+const inflightSuffix = ".inflight"
+
+// spoolRecord is the on-disk representation of a spooled notice.
+type spoolRecord struct {
+	Attempts int     `json:"attempts"`
+	Notice   *Notice `json:"notice"`
+}
+
+type spoolEntry struct {
+	path     string
+	attempts int
+}
+
+// spool persists notices that could not be sent so they can be replayed
+// on the next start. A nil spool discards everything.
+type spool struct {
+	config  *Configuration
+	dir     string
+	maxSize int
+	mu      sync.Mutex
+	entries map[*Notice]spoolEntry
+}
+
+var spoolSeq uint64
+
+func newSpool(config *Configuration) *spool {
+	if config.SpoolDir == "" {
+		return nil
+	}
+	return &spool{
+		config:  config,
+		dir:     config.SpoolDir,
+		maxSize: config.MaxSpoolFiles,
+		entries: make(map[*Notice]spoolEntry),
+	}
+}
+
+// load claims and reads the spooled notices, discarding unreadable files
+// and notices that have already failed too many times. Stale temporary
+// files are deleted, and files claimed by a process that crashed are
+// released first.
+func (s *spool) load() []*Notice {
+	if s == nil {
+		return nil
+	}
+
+	if err := s.cleanup(); err != nil {
+		if !os.IsNotExist(err) {
+			logMessage(s.config, "error", fmt.Sprintf("Failed to read spool: %v", err))
+		}
+		return nil
+	}
+
+	paths, err := s.files()
+	if err != nil {
+		logMessage(s.config, "error", fmt.Sprintf("Failed to read spool: %v", err))
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var notices []*Notice
+	for _, path := range paths {
+		// Another process sharing the directory may have claimed it first
+		claimed := path + inflightSuffix
+		if err := os.Rename(path, claimed); err != nil {
+			continue
+		}
+		now := time.Now()
+		os.Chtimes(claimed, now, now)
+
+		data, err := os.ReadFile(claimed)
+		if err != nil {
+			continue
+		}
+
+		var record spoolRecord
+		if err := json.Unmarshal(data, &record); err != nil || record.Notice == nil {
+			logMessage(s.config, "warning", fmt.Sprintf("Discarding unreadable spool file %s", path))
+			os.Remove(claimed)
+			continue
+		}
+		if record.Attempts >= maxSpoolAttempts {
+			logMessage(s.config, "warning", fmt.Sprintf("Discarding spool file %s after %d attempts", path, record.Attempts))
+			os.Remove(claimed)
+			continue
+		}
+
+		s.entries[record.Notice] = spoolEntry{path: claimed, attempts: record.Attempts}
+		notices = append(notices, record.Notice)
+	}
+	return notices
+}
+
+// cleanup deletes temporary files left by interrupted writes and releases
+// claims held for longer than staleSpoolFileAge.
+func (s *spool) cleanup() error {
+	dirEntries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range dirEntries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, "notice-") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || time.Since(info.ModTime()) < staleSpoolFileAge {
+			continue
+		}
+
+		path := filepath.Join(s.dir, name)
+		switch {
+		case strings.HasSuffix(name, ".tmp"):
+			os.Remove(path)
+		case strings.HasSuffix(name, inflightSuffix):
+			os.Rename(path, strings.TrimSuffix(path, inflightSuffix))
+		}
+	}
+	return nil
+}
+
+// save writes a notice that could not be sent. A notice that was already
+// spooled has its attempt count increased, and is discarded once it
+// reaches maxSpoolAttempts.
+func (s *spool) save(notice *Notice) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[notice]
+	if ok {
+		entry.attempts++
+		if entry.attempts >= maxSpoolAttempts {
+			logMessage(s.config, "warning", fmt.Sprintf("Discarding spool file %s after %d attempts", entry.path, entry.attempts))
+			os.Remove(entry.path)
+			delete(s.entries, notice)
+			return
+		}
+		// Release the claim so the notice is replayed on the next start
+		os.Remove(entry.path)
+		entry.path = strings.TrimSuffix(entry.path, inflightSuffix)
+	} else {
+		paths, _ := s.files()
+		if len(paths) >= s.maxSize {
+			logMessage(s.config, "warning", "Spool is full, dropping notice")
+			return
+		}
+		if err := os.MkdirAll(s.dir, 0o700); err != nil {
+			logMessage(s.config, "error", fmt.Sprintf("Failed to create spool directory: %v", err))
+			return
+		}
+		name := fmt.Sprintf("notice-%d-%d.json", time.Now().UnixNano(), atomic.AddUint64(&spoolSeq, 1))
+		entry = spoolEntry{path: filepath.Join(s.dir, name), attempts: 1}
+	}
+
+	if err := writeSpoolFile(entry.path, spoolRecord{Attempts: entry.attempts, Notice: notice}); err != nil {
+		logMessage(s.config, "error", fmt.Sprintf("Failed to write spool file: %v", err))
+		return
+	}
+	s.entries[notice] = entry
+}
+
+// remove deletes the spool file of a notice that was sent successfully.
+func (s *spool) remove(notice *Notice) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[notice]; ok {
+		os.Remove(entry.path)
+		delete(s.entries, notice)
+	}
+}
+
+// files returns the unclaimed spool files, oldest first.
+func (s *spool) files() ([]string, error) {
+	dirEntries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, e := range dirEntries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), "notice-") && strings.HasSuffix(e.Name(), ".json") {
+			paths = append(paths, filepath.Join(s.dir, e.Name()))
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// writeSpoolFile writes the record to a temporary file and renames it into
+// place so a crash never leaves a partially written notice.
+func writeSpoolFile(path string, record spoolRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
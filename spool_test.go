@@ -0,0 +1,186 @@
+package checkend
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// spoolFiles returns the spool files in dir, claimed or not.
+func spoolFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join(dir, "notice-*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	claimed, err := filepath.Glob(filepath.Join(dir, "notice-*.json"+inflightSuffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(paths, claimed...)
+}
+
+func waitForSpoolFiles(t *testing.T, dir string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for len(spoolFiles(t, dir)) != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d spool files, got %d", n, len(spoolFiles(t, dir)))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSpoolPersistsAndReplaysNotices(t *testing.T) {
+	dir := t.TempDir()
+
+	failing := NewTestTransport()
+	failing.RespondWith(503, `{"error": "unavailable"}`)
+	cfg := NewConfiguration(Config{APIKey: "test-key", Transport: failing, SpoolDir: dir})
+
+	worker := NewWorker(cfg)
+	worker.Start()
+	worker.Push(newTestNotice(cfg))
+	waitForSpoolFiles(t, dir, 1)
+	worker.Stop()
+
+	// Next start replays the spooled notice and deletes it once sent
+	succeeding := NewTestTransport()
+	cfg = NewConfiguration(Config{APIKey: "test-key", Transport: succeeding, SpoolDir: dir})
+
+	worker = NewWorker(cfg)
+	worker.Start()
+	defer worker.Stop()
+	waitForSpoolFiles(t, dir, 0)
+
+	if len(succeeding.Requests()) != 1 {
+		t.Fatalf("Expected 1 replayed request, got %d", len(succeeding.Requests()))
+	}
+	errorPayload, _ := succeeding.LastRequest().Payload["error"].(map[string]interface{})
+	if errorPayload["message"] != "test error" {
+		t.Errorf("Expected replayed message 'test error', got %v", errorPayload["message"])
+	}
+}
+
+func TestSpoolDiscardsRepeatedlyFailingNotices(t *testing.T) {
+	dir := t.TempDir()
+	cfg := NewConfiguration(Config{APIKey: "test-key", SpoolDir: dir})
+
+	path := filepath.Join(dir, "notice-1-1.json")
+	if err := writeSpoolFile(path, spoolRecord{Attempts: maxSpoolAttempts, Notice: newTestNotice(cfg)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if notices := newSpool(cfg).load(); len(notices) != 0 {
+		t.Errorf("Expected exhausted notice to be skipped, got %d", len(notices))
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected exhausted spool file to be deleted")
+	}
+}
+
+func TestSpoolIncrementsAttempts(t *testing.T) {
+	dir := t.TempDir()
+	cfg := NewConfiguration(Config{APIKey: "test-key", SpoolDir: dir})
+
+	s := newSpool(cfg)
+	s.save(newTestNotice(cfg))
+
+	s = newSpool(cfg)
+	notices := s.load()
+	if len(notices) != 1 {
+		t.Fatalf("Expected 1 spooled notice, got %d", len(notices))
+	}
+	s.save(notices[0])
+
+	data, err := os.ReadFile(spoolFiles(t, dir)[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var record spoolRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatal(err)
+	}
+	if record.Attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", record.Attempts)
+	}
+	if len(spoolFiles(t, dir)) != 1 {
+		t.Errorf("Expected the existing file to be updated, got %d files", len(spoolFiles(t, dir)))
+	}
+}
+
+func TestSpoolIsBounded(t *testing.T) {
+	dir := t.TempDir()
+	cfg := NewConfiguration(Config{APIKey: "test-key", SpoolDir: dir, MaxSpoolFiles: 2})
+
+	s := newSpool(cfg)
+	for i := 0; i < 5; i++ {
+		s.save(newTestNotice(cfg))
+	}
+
+	if n := len(spoolFiles(t, dir)); n != 2 {
+		t.Errorf("Expected spool to be capped at 2 files, got %d", n)
+	}
+}
+
+func TestSpoolClaimsFilesOnce(t *testing.T) {
+	dir := t.TempDir()
+	cfg := NewConfiguration(Config{APIKey: "test-key", SpoolDir: dir})
+
+	newSpool(cfg).save(newTestNotice(cfg))
+
+	// Two workers sharing the directory replay each file only once
+	if notices := newSpool(cfg).load(); len(notices) != 1 {
+		t.Fatalf("Expected 1 spooled notice, got %d", len(notices))
+	}
+	if notices := newSpool(cfg).load(); len(notices) != 0 {
+		t.Errorf("Expected claimed notice not to be replayed again, got %d", len(notices))
+	}
+}
+
+func TestSpoolCleansUpStaleFiles(t *testing.T) {
+	dir := t.TempDir()
+	cfg := NewConfiguration(Config{APIKey: "test-key", SpoolDir: dir})
+
+	tmp := filepath.Join(dir, "notice-1-1.json.tmp")
+	if err := os.WriteFile(tmp, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	claimed := filepath.Join(dir, "notice-2-2.json"+inflightSuffix)
+	if err := writeSpoolFile(claimed, spoolRecord{Attempts: 1, Notice: newTestNotice(cfg)}); err != nil {
+		t.Fatal(err)
+	}
+	stale := time.Now().Add(-2 * staleSpoolFileAge)
+	for _, path := range []string{tmp, claimed} {
+		if err := os.Chtimes(path, stale, stale); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if notices := newSpool(cfg).load(); len(notices) != 1 {
+		t.Errorf("Expected the abandoned claim to be replayed, got %d notices", len(notices))
+	}
+	if _, err := os.Stat(tmp); !os.IsNotExist(err) {
+		t.Error("Expected stale temporary file to be deleted")
+	}
+}
+
+func TestSpoolSkipsRejectedNotices(t *testing.T) {
+	dir := t.TempDir()
+
+	transport := NewTestTransport()
+	transport.RespondWith(422, `{"error": "invalid payload"}`)
+	cfg := NewConfiguration(Config{APIKey: "test-key", Transport: transport, SpoolDir: dir})
+
+	worker := NewWorker(cfg)
+	worker.Start()
+	worker.Push(newTestNotice(cfg))
+	worker.Flush()
+	worker.Stop()
+
+	if n := len(spoolFiles(t, dir)); n != 0 {
+		t.Errorf("Expected rejected notice not to be spooled, got %d files", n)
+	}
+}
@@ -0,0 +1,158 @@
+package checkend
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// DeliveryStats is a snapshot of the SDK's delivery counters.
+type DeliveryStats struct {
+	// Queued is the number of notices added to the async queue.
+	Queued int64
+
+	// Sent is the number of notices delivered to Checkend.
+	Sent int64
+
+	// Failed is the number of notices that could not be delivered after
+	// every retry. Async notices that fail are spooled if SpoolDir is set.
+	Failed int64
+
+	// Dropped is the number of notices discarded without being sent,
+	// because the queue was full or they were older than NoticeTTL.
+	Dropped int64
+
+	// Retried is the number of notice send attempts that were retries.
+	Retried int64
+
+	// CurrentQueueDepth is the number of notices waiting in the queue.
+	CurrentQueueDepth int
+
+	// EffectiveSampleRate is the adaptive sample rate currently applied to
+	// keep under TargetNoticesPerSecond. It is 1 when adaptive sampling is
+	// off or traffic is below the target.
+	EffectiveSampleRate float64
+
+	// BySeverity breaks the notice counters down by notice severity.
+	// Notices without an explicit severity are counted under "error".
+	BySeverity map[string]SeverityStats
+}
+
+// SeverityStats holds the delivery counters for a single severity.
+type SeverityStats struct {
+	Queued  int64
+	Sent    int64
+	Failed  int64
+	Dropped int64
+}
+
+// counters is a set of delivery counters that can be updated concurrently.
+type counters struct {
+	queued  atomic.Int64
+	sent    atomic.Int64
+	failed  atomic.Int64
+	dropped atomic.Int64
+	retried atomic.Int64
+}
+
+func (c *counters) reset() {
+	c.queued.Store(0)
+	c.sent.Store(0)
+	c.failed.Store(0)
+	c.dropped.Store(0)
+	c.retried.Store(0)
+}
+
+// deliveryCounters are updated by the worker and client as notices move
+// through the pipeline. severityCounters holds a *counters per severity.
+var (
+	deliveryCounters counters
+	severityCounters sync.Map
+)
+
+// severityLabel returns the severity a notice is counted under.
+func severityLabel(notice *Notice) string {
+	if notice == nil || notice.Severity == "" {
+		return "error"
+	}
+	return notice.Severity
+}
+
+func countersFor(notice *Notice) *counters {
+	label := severityLabel(notice)
+	if c, ok := severityCounters.Load(label); ok {
+		return c.(*counters)
+	}
+	c, _ := severityCounters.LoadOrStore(label, &counters{})
+	return c.(*counters)
+}
+
+func countQueued(notice *Notice) {
+	deliveryCounters.queued.Add(1)
+	countersFor(notice).queued.Add(1)
+}
+
+func countSent(notices ...*Notice) {
+	for _, notice := range notices {
+		deliveryCounters.sent.Add(1)
+		countersFor(notice).sent.Add(1)
+	}
+}
+
+func countFailed(notice *Notice) {
+	deliveryCounters.failed.Add(1)
+	countersFor(notice).failed.Add(1)
+}
+
+func countDropped(notice *Notice) {
+	deliveryCounters.dropped.Add(1)
+	countersFor(notice).dropped.Add(1)
+}
+
+func countRetried(n int) {
+	deliveryCounters.retried.Add(int64(n))
+}
+
+// Stats returns the current delivery counters. It is safe to call
+// concurrently; counters are reset by Reset. The counters cover every
+// Notifier in the process, while CurrentQueueDepth and
+// EffectiveSampleRate describe the default one set up by Configure.
+func Stats() DeliveryStats {
+	stats := DeliveryStats{
+		Queued:              deliveryCounters.queued.Load(),
+		Sent:                deliveryCounters.sent.Load(),
+		Failed:              deliveryCounters.failed.Load(),
+		Dropped:             deliveryCounters.dropped.Load(),
+		Retried:             deliveryCounters.retried.Load(),
+		EffectiveSampleRate: 1,
+		BySeverity:          make(map[string]SeverityStats),
+	}
+
+	severityCounters.Range(func(key, value interface{}) bool {
+		c := value.(*counters)
+		stats.BySeverity[key.(string)] = SeverityStats{
+			Queued:  c.queued.Load(),
+			Sent:    c.sent.Load(),
+			Failed:  c.failed.Load(),
+			Dropped: c.dropped.Load(),
+		}
+		return true
+	})
+
+	if n := defaultNotifier(); n != nil {
+		stats.EffectiveSampleRate = n.Configuration().sampler.rate()
+		if w := n.currentWorker(); w != nil {
+			stats.CurrentQueueDepth = len(w.queue)
+		}
+	}
+
+	return stats
+}
+
+// resetStats zeroes the delivery counters.
+func resetStats() {
+	deliveryCounters.reset()
+	severityCounters.Range(func(key, _ interface{}) bool {
+		severityCounters.Delete(key)
+		return true
+	})
+}
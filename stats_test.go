@@ -0,0 +1,128 @@
+package checkend
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestStatsCountsAsyncDeliveries(t *testing.T) {
+	defer Reset()
+
+	resetStats()
+	Configure(Config{
+		APIKey:    "test-key",
+		Enabled:   boolPtr(true),
+		Transport: NewTestTransport(),
+	})
+
+	Notify(errors.New("first"))
+	Notify(errors.New("second"))
+	Flush()
+
+	stats := Stats()
+	if stats.Queued != 2 || stats.Sent != 2 {
+		t.Errorf("Expected 2 queued and 2 sent, got %+v", stats)
+	}
+	if stats.Failed != 0 || stats.Dropped != 0 || stats.Retried != 0 {
+		t.Errorf("Expected no failures, got %+v", stats)
+	}
+	if stats.CurrentQueueDepth != 0 {
+		t.Errorf("Expected an empty queue, got depth %d", stats.CurrentQueueDepth)
+	}
+}
+
+func TestStatsCountsFailuresAndRetries(t *testing.T) {
+	defer Reset()
+
+	resetStats()
+	transport := NewTestTransport()
+	transport.RespondWith(http.StatusServiceUnavailable, `{}`)
+	Configure(Config{
+		APIKey:    "test-key",
+		Enabled:   boolPtr(true),
+		Transport: transport,
+	})
+
+	Notify(errors.New("unreachable"))
+	Flush()
+
+	stats := Stats()
+	if stats.Failed != 1 {
+		t.Errorf("Expected 1 failed notice, got %d", stats.Failed)
+	}
+	if stats.Retried != 2 {
+		t.Errorf("Expected 2 retries, got %d", stats.Retried)
+	}
+	if stats.Sent != 0 {
+		t.Errorf("Expected nothing sent, got %d", stats.Sent)
+	}
+}
+
+func TestStatsCountsSyncFailures(t *testing.T) {
+	defer Reset()
+
+	resetStats()
+	transport := NewTestTransport()
+	transport.RespondWith(http.StatusUnprocessableEntity, `{}`)
+	Configure(Config{
+		APIKey:    "test-key",
+		Enabled:   boolPtr(true),
+		Transport: transport,
+	})
+
+	NotifySync(errors.New("rejected"))
+
+	if stats := Stats(); stats.Failed != 1 || stats.Sent != 0 {
+		t.Errorf("Expected 1 failed and none sent, got %+v", stats)
+	}
+}
+
+func TestStatsResetOnReset(t *testing.T) {
+	resetStats()
+	Configure(Config{
+		APIKey:    "test-key",
+		Enabled:   boolPtr(true),
+		Transport: NewTestTransport(),
+	})
+
+	NotifySync(errors.New("sent"))
+	if Stats().Sent != 1 {
+		t.Fatalf("Expected 1 sent notice, got %d", Stats().Sent)
+	}
+
+	Reset()
+
+	stats := Stats()
+	if len(stats.BySeverity) != 0 {
+		t.Errorf("Expected no severity counters after Reset, got %v", stats.BySeverity)
+	}
+	stats.BySeverity = nil
+	if !reflect.DeepEqual(stats, DeliveryStats{EffectiveSampleRate: 1}) {
+		t.Errorf("Expected zeroed stats after Reset, got %+v", stats)
+	}
+}
+
+func TestStatsBySeverity(t *testing.T) {
+	defer Reset()
+
+	resetStats()
+	Configure(Config{
+		APIKey:    "test-key",
+		Enabled:   boolPtr(true),
+		Transport: NewTestTransport(),
+	})
+
+	NotifySync(errors.New("default"))
+	NotifySync(errors.New("warning"), WithSeverity("warning"))
+	NotifySync(errors.New("another warning"), WithSeverity("warning"))
+
+	stats := Stats()
+	if stats.BySeverity["error"].Sent != 1 {
+		t.Errorf("Expected 1 error sent, got %+v", stats.BySeverity["error"])
+	}
+	if stats.BySeverity["warning"].Sent != 2 {
+		t.Errorf("Expected 2 warnings sent, got %+v", stats.BySeverity["warning"])
+	}
+}
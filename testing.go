@@ -1,16 +1,48 @@
 package checkend
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 var (
 	testingEnabled bool
 	testingNotices []*Notice
 	testingMu      sync.Mutex
+
+	testingModeRequired atomic.Bool
 )
 
-// SetupTesting enables testing mode.
+// RequireTestingMode makes any attempt to send a notice over the network
+// panic, catching tests that forgot to call SetupTesting. Call it from
+// TestMain. Requests through a TestTransport are still allowed.
+func RequireTestingMode() {
+	testingModeRequired.Store(true)
+}
+
+// checkTestingModeRequired panics if RequireTestingMode is in effect and the
+// client would send a real request.
+func checkTestingModeRequired(config *Configuration) {
+	if !testingModeRequired.Load() {
+		return
+	}
+	if _, ok := config.Transport.(*TestTransport); ok {
+		return
+	}
+	panic("checkend: attempted to send a notice over the network while RequireTestingMode is enabled; " +
+		"call checkend.SetupTesting() or use a TestTransport")
+}
+
+// SetupTesting enables testing mode. It applies to every Notifier in the
+// process: notices from all of them are captured instead of being sent.
 func SetupTesting() {
 	testingMu.Lock()
 	defer testingMu.Unlock()
@@ -34,6 +66,18 @@ func ClearTesting() {
 	testingNotices = nil
 }
 
+// captureTestingNotice records notice instead of sending it when testing
+// mode is enabled, reporting whether it did.
+func captureTestingNotice(notice *Notice) bool {
+	testingMu.Lock()
+	defer testingMu.Unlock()
+	if !testingEnabled {
+		return false
+	}
+	testingNotices = append(testingNotices, notice)
+	return true
+}
+
 // TestingNotices returns all captured notices.
 func TestingNotices() []*Notice {
 	testingMu.Lock()
@@ -83,3 +127,258 @@ func TestingClearNotices() {
 	defer testingMu.Unlock()
 	testingNotices = nil
 }
+
+// TestingFindNotice returns the first captured notice for which match
+// returns true, or nil.
+func TestingFindNotice(match func(*Notice) bool) *Notice {
+	for _, notice := range TestingNotices() {
+		if match(notice) {
+			return notice
+		}
+	}
+	return nil
+}
+
+// TestingNoticesWithTag returns the captured notices tagged with tag.
+func TestingNoticesWithTag(tag string) []*Notice {
+	var result []*Notice
+	for _, notice := range TestingNotices() {
+		for _, t := range notice.Tags {
+			if t == tag {
+				result = append(result, notice)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// TestingT is the part of *testing.T used by the assertion helpers, so the
+// SDK doesn't import the testing package.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// TestingAssertNotified fails t unless a captured notice's error class or
+// message contains substring, and returns the first such notice.
+func TestingAssertNotified(t TestingT, substring string) *Notice {
+	t.Helper()
+	notice := TestingFindNotice(func(n *Notice) bool {
+		return strings.Contains(n.ErrorClass, substring) || strings.Contains(n.Message, substring)
+	})
+	if notice == nil {
+		t.Errorf("checkend: expected a notice matching %q, %s", substring, describeTestingNotices())
+	}
+	return notice
+}
+
+// TestingAssertNotNotified fails t if any notice was captured.
+func TestingAssertNotNotified(t TestingT) {
+	t.Helper()
+	if TestingHasNotices() {
+		t.Errorf("checkend: expected no notices, %s", describeTestingNotices())
+	}
+}
+
+// describeTestingNotices lists the captured notices for failure messages.
+func describeTestingNotices() string {
+	notices := TestingNotices()
+	if len(notices) == 0 {
+		return "captured none"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "captured %d:", len(notices))
+	for _, notice := range notices {
+		fmt.Fprintf(&b, "\n\t%s: %s", notice.ErrorClass, notice.Message)
+		if len(notice.Tags) > 0 {
+			fmt.Fprintf(&b, " %v", notice.Tags)
+		}
+	}
+	return b.String()
+}
+
+// NoticeMatcher compares notices in tests while ignoring fields that vary
+// between otherwise identical reports. Fields are named by their JSON keys.
+type NoticeMatcher struct {
+	ignored map[string]bool
+}
+
+// NewNoticeMatcher creates a NoticeMatcher that ignores occurred_at,
+// hostname, backtrace, and frames.
+func NewNoticeMatcher() *NoticeMatcher {
+	return &NoticeMatcher{ignored: map[string]bool{
+		"occurred_at": true,
+		"hostname":    true,
+		"backtrace":   true,
+		"frames":      true,
+	}}
+}
+
+// Ignore excludes the named fields from comparison.
+func (m *NoticeMatcher) Ignore(fields ...string) *NoticeMatcher {
+	for _, field := range fields {
+		m.ignored[field] = true
+	}
+	return m
+}
+
+// Include compares the named fields again, undoing Ignore or a default.
+func (m *NoticeMatcher) Include(fields ...string) *NoticeMatcher {
+	for _, field := range fields {
+		delete(m.ignored, field)
+	}
+	return m
+}
+
+// Equal reports whether a and b match on every field that is not ignored.
+func (m *NoticeMatcher) Equal(a, b *Notice) bool {
+	return len(m.Diff(a, b)) == 0
+}
+
+// Diff returns the names of the fields that differ between a and b.
+func (m *NoticeMatcher) Diff(a, b *Notice) []string {
+	if a == nil || b == nil {
+		if a == b {
+			return nil
+		}
+		return []string{"notice"}
+	}
+
+	va, vb := reflect.ValueOf(*a), reflect.ValueOf(*b)
+	var diff []string
+	for i := 0; i < va.NumField(); i++ {
+		field := va.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = field.Name
+		}
+		if m.ignored[name] {
+			continue
+		}
+		if !reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			diff = append(diff, name)
+		}
+	}
+	return diff
+}
+
+// RecordedRequest is an outbound request captured by a TestTransport.
+type RecordedRequest struct {
+	Method  string
+	URL     string
+	Header  http.Header
+	Payload map[string]interface{}
+}
+
+type scriptedResponse struct {
+	statusCode int
+	body       string
+}
+
+// TestTransport is an http.RoundTripper that records outbound requests
+// instead of sending them over the network. Inject it via Config.Transport.
+type TestTransport struct {
+	mu       sync.Mutex
+	requests []RecordedRequest
+	queued   []scriptedResponse
+	fallback scriptedResponse
+}
+
+// NewTestTransport creates a TestTransport that responds with 201 Created.
+func NewTestTransport() *TestTransport {
+	return &TestTransport{
+		fallback: scriptedResponse{
+			statusCode: http.StatusCreated,
+			body:       `{"id": 1, "problem_id": 1}`,
+		},
+	}
+}
+
+// RespondWith sets the response returned once all queued responses are used.
+func (t *TestTransport) RespondWith(statusCode int, body string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.fallback = scriptedResponse{statusCode: statusCode, body: body}
+}
+
+// QueueResponse adds a response to be returned by the next unanswered request.
+func (t *TestTransport) QueueResponse(statusCode int, body string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.queued = append(t.queued, scriptedResponse{statusCode: statusCode, body: body})
+}
+
+// RoundTrip records the request and returns the next scripted response.
+func (t *TestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded := RecordedRequest{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Header: req.Header.Clone(),
+	}
+
+	if req.Body != nil {
+		body, err := readRequestBody(req)
+		if err != nil {
+			return nil, err
+		}
+		_ = json.Unmarshal(body, &recorded.Payload)
+	}
+
+	t.mu.Lock()
+	t.requests = append(t.requests, recorded)
+	resp := t.fallback
+	if len(t.queued) > 0 {
+		resp = t.queued[0]
+		t.queued = t.queued[1:]
+	}
+	t.mu.Unlock()
+
+	return &http.Response{
+		StatusCode: resp.statusCode,
+		Status:     http.StatusText(resp.statusCode),
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewBufferString(resp.body)),
+		Request:    req,
+	}, nil
+}
+
+// readRequestBody reads and closes the body of req, decompressing it if it
+// was gzipped.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	defer req.Body.Close()
+
+	var body io.Reader = req.Body
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		body = zr
+	}
+	return io.ReadAll(body)
+}
+
+// Requests returns all recorded requests.
+func (t *TestTransport) Requests() []RecordedRequest {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	result := make([]RecordedRequest, len(t.requests))
+	copy(result, t.requests)
+	return result
+}
+
+// LastRequest returns the most recently recorded request.
+func (t *TestTransport) LastRequest() *RecordedRequest {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.requests) == 0 {
+		return nil
+	}
+	req := t.requests[len(t.requests)-1]
+	return &req
+}
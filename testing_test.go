@@ -0,0 +1,224 @@
+package checkend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTestTransportCapturesNotice(t *testing.T) {
+	defer Reset()
+
+	transport := NewTestTransport()
+	Configure(Config{
+		APIKey:    "test-key",
+		Enabled:   boolPtr(true),
+		Endpoint:  "https://checkend.example.com",
+		Transport: transport,
+	})
+
+	resp := NotifySync(errors.New("test error"), WithTags("checkout"))
+	if resp == nil || resp.ID != 1 {
+		t.Fatalf("Expected scripted response, got %+v", resp)
+	}
+
+	req := transport.LastRequest()
+	if req == nil {
+		t.Fatal("Expected a recorded request")
+	}
+	if req.Method != "POST" {
+		t.Errorf("Expected method POST, got %s", req.Method)
+	}
+	if req.URL != "https://checkend.example.com/ingest/v1/errors" {
+		t.Errorf("Unexpected URL %s", req.URL)
+	}
+	if req.Header.Get("Checkend-Ingestion-Key") != "test-key" {
+		t.Errorf("Expected ingestion key header, got '%s'", req.Header.Get("Checkend-Ingestion-Key"))
+	}
+	if req.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Expected JSON content type, got '%s'", req.Header.Get("Content-Type"))
+	}
+
+	errorPayload := req.Payload["error"].(map[string]interface{})
+	if errorPayload["message"] != "test error" {
+		t.Errorf("Expected message 'test error', got %v", errorPayload["message"])
+	}
+	tags := errorPayload["tags"].([]interface{})
+	if len(tags) != 1 || tags[0] != "checkout" {
+		t.Errorf("Expected tags [checkout], got %v", tags)
+	}
+}
+
+func TestTestTransportScriptedResponses(t *testing.T) {
+	defer Reset()
+
+	transport := NewTestTransport()
+	transport.QueueResponse(http.StatusUnprocessableEntity, `{"error": "invalid"}`)
+
+	Configure(Config{
+		APIKey:    "test-key",
+		Enabled:   boolPtr(true),
+		Transport: transport,
+	})
+
+	if resp := NotifySync(errors.New("first")); resp != nil {
+		t.Errorf("Expected nil response for queued 422, got %+v", resp)
+	}
+	if resp := NotifySync(errors.New("second")); resp == nil {
+		t.Error("Expected default response after queue is exhausted")
+	}
+	if len(transport.Requests()) != 2 {
+		t.Errorf("Expected 2 recorded requests, got %d", len(transport.Requests()))
+	}
+}
+
+func TestRequireTestingMode(t *testing.T) {
+	RequireTestingMode()
+	defer testingModeRequired.Store(false)
+	defer Reset()
+
+	Configure(Config{
+		APIKey:   "test-key",
+		Enabled:  boolPtr(true),
+		Endpoint: "http://127.0.0.1:0",
+	})
+
+	func() {
+		defer func() {
+			r := recover()
+			if r == nil {
+				t.Fatal("Expected a network send to panic")
+			}
+			if !strings.Contains(fmt.Sprint(r), "RequireTestingMode") {
+				t.Errorf("Expected a clear panic message, got %v", r)
+			}
+		}()
+		NotifySync(errors.New("test error"))
+	}()
+
+	SetupTesting()
+	NotifySync(errors.New("test error"))
+	if TestingNoticeCount() != 1 {
+		t.Errorf("Expected notice to be captured in testing mode, got %d", TestingNoticeCount())
+	}
+}
+
+func TestRequireTestingModeAllowsTestTransport(t *testing.T) {
+	RequireTestingMode()
+	defer testingModeRequired.Store(false)
+
+	transport := NewTestTransport()
+	cfg := NewConfiguration(Config{APIKey: "test-key", Transport: transport})
+
+	if _, err := NewClient(cfg).SendWithContext(context.Background(), newTestNotice(cfg)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(transport.Requests()) != 1 {
+		t.Errorf("Expected 1 recorded request, got %d", len(transport.Requests()))
+	}
+}
+
+func TestNoticeMatcher(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{APIKey: "test-key", Enabled: boolPtr(true)})
+
+	Notify(errors.New("connection refused"), WithTags("db"))
+	Notify(errors.New("connection refused"), WithTags("db"))
+	notices := TestingNotices()
+	first, second := notices[0], notices[1]
+
+	// Reported from different lines, at different times, on different hosts
+	second.Hostname = "other-host"
+	second.OccurredAt = first.OccurredAt.Add(time.Minute)
+
+	matcher := NewNoticeMatcher()
+	if !matcher.Equal(first, second) {
+		t.Errorf("Expected notices differing only in volatile fields to match, got diff %v", matcher.Diff(first, second))
+	}
+
+	third := *second
+	third.Message = "connection reset"
+	if matcher.Equal(first, &third) {
+		t.Error("Expected notices with different messages not to match")
+	}
+	if diff := matcher.Diff(first, &third); len(diff) != 1 || diff[0] != "message" {
+		t.Errorf("Expected diff [message], got %v", diff)
+	}
+
+	if NewNoticeMatcher().Include("hostname").Equal(first, second) {
+		t.Error("Expected included hostname to be compared")
+	}
+	if !NewNoticeMatcher().Ignore("message").Equal(first, &third) {
+		t.Error("Expected ignored message not to be compared")
+	}
+}
+
+// recordingT records assertion failures instead of failing the test.
+type recordingT struct {
+	failures []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestTestingFindNoticeAndTags(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{APIKey: "test-key", Enabled: boolPtr(true)})
+
+	Notify(errors.New("charge declined"), WithTags("payments"), WithContext(map[string]interface{}{"order_id": 42}))
+	Notify(errors.New("cache miss"))
+	Notify(errors.New("refund failed"), WithTags("payments", "refunds"))
+
+	notice := TestingFindNotice(func(n *Notice) bool { return n.Context["order_id"] == 42 })
+	if notice == nil || notice.Message != "charge declined" {
+		t.Errorf("Expected to find the notice by context, got %v", notice)
+	}
+	if TestingFindNotice(func(n *Notice) bool { return false }) != nil {
+		t.Error("Expected no notice when nothing matches")
+	}
+
+	if tagged := TestingNoticesWithTag("payments"); len(tagged) != 2 {
+		t.Errorf("Expected 2 notices tagged payments, got %d", len(tagged))
+	}
+	if tagged := TestingNoticesWithTag("refunds"); len(tagged) != 1 || tagged[0].Message != "refund failed" {
+		t.Errorf("Expected the refund notice, got %v", tagged)
+	}
+}
+
+func TestTestingAssertions(t *testing.T) {
+	defer Reset()
+
+	SetupTesting()
+	Configure(Config{APIKey: "test-key", Enabled: boolPtr(true)})
+
+	rt := &recordingT{}
+	TestingAssertNotNotified(rt)
+	if TestingAssertNotified(rt, "declined") != nil {
+		t.Error("Expected no notice before any were reported")
+	}
+	if len(rt.failures) != 1 || !strings.Contains(rt.failures[0], `"declined"`) || !strings.Contains(rt.failures[0], "captured none") {
+		t.Errorf("Expected one failure for the missing notice, got %q", rt.failures)
+	}
+
+	Notify(errors.New("charge declined"), WithTags("payments"))
+
+	rt = &recordingT{}
+	if notice := TestingAssertNotified(rt, "declined"); notice == nil || notice.Message != "charge declined" {
+		t.Errorf("Expected the matching notice, got %v", notice)
+	}
+	TestingAssertNotNotified(rt)
+	if len(rt.failures) != 1 || !strings.Contains(rt.failures[0], "charge declined [payments]") {
+		t.Errorf("Expected the failure to list the captured notice, got %q", rt.failures)
+	}
+}
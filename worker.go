@@ -2,32 +2,69 @@ package checkend
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// clock returns the current time. Tests replace it to simulate delays.
+var clock = time.Now
+
+// QueueFullPolicy controls what Push does when the async queue is full.
+type QueueFullPolicy int
+
+const (
+	// QueueFullDrop discards the new notice (the default).
+	QueueFullDrop QueueFullPolicy = iota
+
+	// QueueFullBlock blocks the caller until there is room in the queue or
+	// QueueFullTimeout elapses. This adds latency to the code reporting the
+	// error whenever the queue is backed up.
+	QueueFullBlock
+
+	// QueueFullDropOldest discards the oldest queued notice to make room
+	// for the new one.
+	QueueFullDropOldest
+)
+
 // Worker handles asynchronous sending of notices.
 type Worker struct {
 	config    *Configuration
 	client    *Client
+	fallback  *Client
 	queue     chan *Notice
 	done      chan struct{}
 	wg        sync.WaitGroup
 	flushCh   chan chan struct{}
+	spool     *spool
+	pending   int64
+	dropped   int64
 	running   bool
 	runningMu sync.Mutex
 }
 
 // NewWorker creates a new Worker.
 func NewWorker(config *Configuration) *Worker {
-	return &Worker{
+	queueSize := config.MaxQueueSize
+	if queueSize < MinQueueSize {
+		logMessage(config, "warning", fmt.Sprintf("Invalid MaxQueueSize %d, clamping to %d", queueSize, MinQueueSize))
+		queueSize = MinQueueSize
+	}
+
+	w := &Worker{
 		config:  config,
 		client:  NewClient(config),
-		queue:   make(chan *Notice, config.MaxQueueSize),
+		queue:   make(chan *Notice, queueSize),
 		done:    make(chan struct{}),
 		flushCh: make(chan chan struct{}),
+		spool:   newSpool(config),
+	}
+	if config.FallbackEndpoint != "" {
+		w.fallback = newClient(config, config.FallbackEndpoint, config.fallbackBreaker)
 	}
+	return w
 }
 
 // Start starts the worker goroutine.
@@ -42,6 +79,18 @@ func (w *Worker) Start() {
 	w.running = true
 	w.wg.Add(1)
 	go w.run()
+
+	w.replaySpool()
+}
+
+// replaySpool re-enqueues notices spooled by a previous run. Notices that
+// don't fit in the queue stay on disk for the next start.
+func (w *Worker) replaySpool() {
+	for _, notice := range w.spool.load() {
+		if !w.enqueue(notice) {
+			return
+		}
+	}
 }
 
 // Stop stops the worker and waits for pending notices with timeout.
@@ -84,33 +133,208 @@ func (w *Worker) Push(notice *Notice) bool {
 		return false
 	}
 
+	var ok bool
+	switch w.config.QueueFullPolicy {
+	case QueueFullBlock:
+		ok = w.enqueueBlocking(notice, w.config.QueueFullTimeout)
+	case QueueFullDropOldest:
+		ok = w.enqueueDroppingOldest(notice)
+	default:
+		ok = w.enqueue(notice)
+	}
+
+	if !ok {
+		w.countDropped(notice)
+		logMessage(w.config, "warning", "Queue is full, dropping notice")
+	}
+	return ok
+}
+
+// enqueue adds a notice to the queue without blocking, reporting whether
+// there was room for it.
+func (w *Worker) enqueue(notice *Notice) bool {
+	// Count the notice before the worker can dequeue it
+	atomic.AddInt64(&w.pending, 1)
+
 	select {
 	case w.queue <- notice:
+		countQueued(notice)
 		return true
 	default:
 		// Queue full
+		atomic.AddInt64(&w.pending, -1)
 		return false
 	}
 }
 
+// enqueueBlocking adds a notice to the queue, waiting up to timeout for room.
+func (w *Worker) enqueueBlocking(notice *Notice, timeout time.Duration) bool {
+	atomic.AddInt64(&w.pending, 1)
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case w.queue <- notice:
+		countQueued(notice)
+		return true
+	case <-timer.C:
+	case <-w.done:
+	}
+	atomic.AddInt64(&w.pending, -1)
+	return false
+}
+
+// enqueueDroppingOldest adds a notice to the queue, discarding the oldest
+// queued notices to make room. It gives up if concurrent pushes keep the
+// queue full.
+func (w *Worker) enqueueDroppingOldest(notice *Notice) bool {
+	for attempt := 0; attempt < 3; attempt++ {
+		if w.enqueue(notice) {
+			return true
+		}
+
+		select {
+		case oldest := <-w.queue:
+			atomic.AddInt64(&w.pending, -1)
+			w.countDropped(oldest)
+			w.spool.remove(oldest)
+			logMessage(w.config, "debug", "Queue is full, dropping oldest notice")
+		default:
+		}
+	}
+	return false
+}
+
+// stopped reports whether the worker is not running, because it was never
+// started or has been stopped.
+func (w *Worker) stopped() bool {
+	w.runningMu.Lock()
+	defer w.runningMu.Unlock()
+	return !w.running
+}
+
+// Pending returns the number of pushed notices that haven't finished
+// sending yet.
+func (w *Worker) Pending() int {
+	return int(atomic.LoadInt64(&w.pending))
+}
+
+// Dropped returns the number of notices discarded without being sent,
+// either because the queue was full or because they were older than the
+// configured NoticeTTL.
+func (w *Worker) Dropped() int {
+	return int(atomic.LoadInt64(&w.dropped))
+}
+
+// countDropped records a notice discarded without being sent.
+func (w *Worker) countDropped(notice *Notice) {
+	atomic.AddInt64(&w.dropped, 1)
+	countDropped(notice)
+}
+
+// fail records a notice that could not be delivered. It is spooled for a
+// later run if err is retryable, and discarded if the API rejected it.
+func (w *Worker) fail(notice *Notice, err error) {
+	countFailed(notice)
+	if !retryable(err) {
+		w.spool.remove(notice)
+		return
+	}
+	w.spool.save(notice)
+}
+
+// isStale reports whether a notice is older than the configured NoticeTTL.
+func (w *Worker) isStale(notice *Notice) bool {
+	return w.config.NoticeTTL > 0 && clock().Sub(notice.OccurredAt) > w.config.NoticeTTL
+}
+
+// expired reports whether a notice is no longer worth sending, because it
+// is stale or the context it was reported with is done.
+func (w *Worker) expired(notice *Notice) bool {
+	return w.isStale(notice) || notice.abandoned()
+}
+
+// drop discards a notice without sending it.
+func (w *Worker) drop(notice *Notice) {
+	w.countDropped(notice)
+	w.spool.remove(notice)
+	if notice.abandoned() {
+		logMessage(w.config, "debug", "Dropping notice whose context is done")
+		return
+	}
+	logMessage(w.config, "debug", fmt.Sprintf("Dropping stale notice from %s", notice.OccurredAt.Format(time.RFC3339)))
+}
+
+// filterStale drops stale and abandoned notices, returning the ones still
+// worth sending.
+func (w *Worker) filterStale(notices []*Notice) []*Notice {
+	fresh := notices[:0:0]
+	for _, notice := range notices {
+		if w.expired(notice) {
+			w.drop(notice)
+			continue
+		}
+		fresh = append(fresh, notice)
+	}
+	return fresh
+}
+
 // Flush waits for all queued notices to be sent.
 func (w *Worker) Flush() {
+	w.FlushCtx(context.Background())
+}
+
+// FlushCtx waits for all queued notices to be sent, returning early if ctx
+// is cancelled. It returns the number of notices still pending.
+func (w *Worker) FlushCtx(ctx context.Context) int {
 	w.runningMu.Lock()
 	running := w.running
 	w.runningMu.Unlock()
 
-	if !running || len(w.queue) == 0 {
-		return
+	// In batch mode the worker may hold a partial batch even when the
+	// queue is empty, so always ask it to flush.
+	if !running || (w.Pending() == 0 && !w.batching()) {
+		return w.Pending()
 	}
 
 	done := make(chan struct{})
-	w.flushCh <- done
-	<-done
+	select {
+	case w.flushCh <- done:
+	case <-ctx.Done():
+		return w.Pending()
+	}
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+	return w.Pending()
+}
+
+// FlushWithTimeout waits up to d for all queued notices to be sent. It
+// returns context.DeadlineExceeded if notices are still pending when d
+// elapses; those notices stay queued.
+func (w *Worker) FlushWithTimeout(d time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+
+	if w.FlushCtx(ctx) > 0 {
+		return context.DeadlineExceeded
+	}
+	return nil
 }
 
 func (w *Worker) run() {
 	defer w.wg.Done()
 
+	w.waitGracePeriod()
+
+	if w.batching() {
+		w.runBatched()
+		return
+	}
+
 	for {
 		select {
 		case <-w.done:
@@ -135,18 +359,236 @@ func (w *Worker) run() {
 	}
 }
 
+// waitGracePeriod holds queued notices until the configured
+// StartupGracePeriod has elapsed or the worker is stopped. Flush requests
+// are not served meanwhile, so they wait for the period to end.
+func (w *Worker) waitGracePeriod() {
+	if w.config.StartupGracePeriod <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(w.config.StartupGracePeriod)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-w.done:
+	}
+}
+
+// batching reports whether queued notices are coalesced into batches.
+func (w *Worker) batching() bool {
+	return w.config.BatchSize > 1
+}
+
+// flushInterval returns how often a partial batch is sent.
+func (w *Worker) flushInterval() time.Duration {
+	if w.config.FlushInterval > 0 {
+		return w.config.FlushInterval
+	}
+	return w.config.BatchInterval
+}
+
+func (w *Worker) runBatched() {
+	ticker := time.NewTicker(w.flushInterval())
+	defer ticker.Stop()
+
+	var batch []*Notice
+	sendBatch := func() {
+		if len(batch) > 0 {
+			w.sendBatchWithRetry(batch, 3)
+			batch = nil
+		}
+	}
+
+	for {
+		select {
+		case <-w.done:
+			batch = append(batch, w.dequeueAll()...)
+			w.drainBatch(batch)
+			return
+
+		case notice := <-w.queue:
+			batch = append(batch, notice)
+			if len(batch) >= w.config.BatchSize {
+				sendBatch()
+			}
+
+		case <-ticker.C:
+			sendBatch()
+
+		case done := <-w.flushCh:
+			batch = append(batch, w.dequeueAll()...)
+			for _, chunk := range w.splitBatches(batch) {
+				w.sendBatchWithRetry(chunk, 3)
+			}
+			batch = nil
+			close(done)
+		}
+	}
+}
+
+// dequeueAll removes and returns every notice currently in the queue.
+func (w *Worker) dequeueAll() []*Notice {
+	var notices []*Notice
+	for {
+		select {
+		case notice := <-w.queue:
+			notices = append(notices, notice)
+		default:
+			return notices
+		}
+	}
+}
+
+func (w *Worker) sendBatchWithRetry(notices []*Notice, maxRetries int) {
+	defer atomic.AddInt64(&w.pending, -int64(len(notices)))
+
+	notices = w.filterStale(notices)
+	if len(notices) == 0 {
+		return
+	}
+
+	var err error
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		_, err = w.client.SendBatch(context.Background(), notices)
+		if err == nil {
+			for _, notice := range notices {
+				w.spool.remove(notice)
+			}
+			return
+		}
+		if !retryable(err) {
+			// The API rejected the batch; neither a retry nor the
+			// fallback endpoint would accept it
+			for _, notice := range notices {
+				w.fail(notice, err)
+			}
+			return
+		}
+		if errors.Is(err, ErrCircuitOpen) {
+			break
+		}
+
+		if attempt < maxRetries-1 {
+			time.Sleep(w.retryDelay(attempt))
+			countRetried(len(notices))
+		}
+	}
+
+	if w.fallback != nil {
+		logMessage(w.config, "warning", "Primary endpoint failed, sending batch to fallback endpoint")
+		if _, err = w.fallback.SendBatch(context.Background(), notices); err == nil {
+			for _, notice := range notices {
+				w.spool.remove(notice)
+			}
+			return
+		}
+	}
+
+	for _, notice := range notices {
+		w.fail(notice, err)
+	}
+}
+
+// drainBatch sends the remaining notices in batches until the shutdown timeout.
+func (w *Worker) drainBatch(notices []*Notice) {
+	ctx, cancel := context.WithTimeout(context.Background(), w.config.ShutdownTimeout)
+	defer cancel()
+
+	fresh := w.filterStale(notices)
+	atomic.AddInt64(&w.pending, -int64(len(notices)-len(fresh)))
+
+	for _, chunk := range w.splitBatches(fresh) {
+		atomic.AddInt64(&w.pending, -int64(len(chunk)))
+		err := ctx.Err()
+		if err == nil {
+			if _, err = w.client.SendBatch(ctx, chunk); err == nil {
+				for _, notice := range chunk {
+					w.spool.remove(notice)
+				}
+				continue
+			}
+		}
+		for _, notice := range chunk {
+			w.fail(notice, err)
+		}
+	}
+}
+
+// splitBatches splits notices into chunks of at most BatchSize.
+func (w *Worker) splitBatches(notices []*Notice) [][]*Notice {
+	var batches [][]*Notice
+	for len(notices) > 0 {
+		n := len(notices)
+		if n > w.config.BatchSize {
+			n = w.config.BatchSize
+		}
+		batches = append(batches, notices[:n])
+		notices = notices[n:]
+	}
+	return batches
+}
+
+// retryDelay returns how long to wait before retrying after the given
+// failed attempt: a random duration up to RetryBackoffBase doubled for
+// each earlier attempt, capped at RetryBackoffMax.
+func (w *Worker) retryDelay(attempt int) time.Duration {
+	backoff := w.config.RetryBackoffBase
+	for i := 0; i < attempt && backoff < w.config.RetryBackoffMax; i++ {
+		backoff *= 2
+	}
+	if backoff > w.config.RetryBackoffMax {
+		backoff = w.config.RetryBackoffMax
+	}
+	return time.Duration(random.Float64() * float64(backoff))
+}
+
 func (w *Worker) sendWithRetry(notice *Notice, maxRetries int) {
+	defer atomic.AddInt64(&w.pending, -1)
+
+	if w.expired(notice) {
+		w.drop(notice)
+		return
+	}
+
+	ctx := notice.sendContext()
+	var err error
 	for attempt := 0; attempt < maxRetries; attempt++ {
-		resp := w.client.Send(notice)
-		if resp != nil {
+		_, err = w.client.SendWithContext(ctx, notice)
+		if err == nil {
+			w.spool.remove(notice)
 			return
 		}
+		if notice.abandoned() {
+			w.drop(notice)
+			return
+		}
+		if !retryable(err) {
+			// The API rejected the notice; neither a retry nor the
+			// fallback endpoint would accept it
+			w.fail(notice, err)
+			return
+		}
+		if errors.Is(err, ErrCircuitOpen) {
+			break
+		}
 
 		if attempt < maxRetries-1 {
-			delay := time.Duration(1<<uint(attempt)) * 100 * time.Millisecond
-			time.Sleep(delay)
+			time.Sleep(w.retryDelay(attempt))
+			countRetried(1)
+		}
+	}
+
+	if w.fallback != nil {
+		logMessage(w.config, "warning", "Primary endpoint failed, sending notice to fallback endpoint")
+		if _, err = w.fallback.SendWithContext(ctx, notice); err == nil {
+			w.spool.remove(notice)
+			return
 		}
 	}
+
+	w.fail(notice, err)
 }
 
 func (w *Worker) drain() {
@@ -155,10 +597,21 @@ func (w *Worker) drain() {
 
 	for {
 		select {
-		case <-ctx.Done():
-			return
 		case notice := <-w.queue:
-			w.client.Send(notice)
+			atomic.AddInt64(&w.pending, -1)
+			if w.expired(notice) {
+				w.drop(notice)
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				w.fail(notice, err)
+				continue
+			}
+			if _, err := w.client.SendWithContext(ctx, notice); err != nil {
+				w.fail(notice, err)
+				continue
+			}
+			w.spool.remove(notice)
 		default:
 			return
 		}
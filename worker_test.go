@@ -0,0 +1,748 @@
+package checkend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingServer records the path and decoded body of each request.
+type recordingServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	paths    []string
+	bodies   []interface{}
+	requests chan struct{}
+}
+
+func newRecordingServer(t *testing.T, respond func(w http.ResponseWriter, r *http.Request)) *recordingServer {
+	t.Helper()
+	rs := &recordingServer{requests: make(chan struct{}, 100)}
+	rs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := readRequestBody(r)
+		var decoded interface{}
+		_ = json.Unmarshal(body, &decoded)
+
+		rs.mu.Lock()
+		rs.paths = append(rs.paths, r.URL.Path)
+		rs.bodies = append(rs.bodies, decoded)
+		rs.mu.Unlock()
+
+		respond(w, r)
+		rs.requests <- struct{}{}
+	}))
+	t.Cleanup(rs.Close)
+	return rs
+}
+
+func (rs *recordingServer) waitForRequests(t *testing.T, n int, timeout time.Duration) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-rs.requests:
+		case <-time.After(timeout):
+			t.Fatalf("Timed out waiting for request %d of %d", i+1, n)
+		}
+	}
+}
+
+func (rs *recordingServer) recorded() ([]string, []interface{}) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return append([]string{}, rs.paths...), append([]interface{}{}, rs.bodies...)
+}
+
+func respondCreated(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusCreated)
+	if r.URL.Path == "/ingest/v1/errors/batch" {
+		w.Write([]byte(`[{"id": 1, "problem_id": 1}]`))
+		return
+	}
+	w.Write([]byte(`{"id": 1, "problem_id": 1}`))
+}
+
+func TestWorkerBatchesBySize(t *testing.T) {
+	server := newRecordingServer(t, respondCreated)
+
+	cfg := NewConfiguration(Config{
+		APIKey:        "test-key",
+		Endpoint:      server.URL,
+		BatchSize:     3,
+		BatchInterval: time.Hour,
+	})
+	worker := NewWorker(cfg)
+	worker.Start()
+	defer worker.Stop()
+
+	for i := 0; i < 3; i++ {
+		worker.Push(newTestNotice(cfg))
+	}
+
+	server.waitForRequests(t, 1, 2*time.Second)
+
+	paths, bodies := server.recorded()
+	if len(paths) != 1 || paths[0] != "/ingest/v1/errors/batch" {
+		t.Fatalf("Expected a single batch request, got %v", paths)
+	}
+	if batch, ok := bodies[0].([]interface{}); !ok || len(batch) != 3 {
+		t.Errorf("Expected a batch of 3 notices, got %v", bodies[0])
+	}
+}
+
+func TestWorkerBatchesByInterval(t *testing.T) {
+	server := newRecordingServer(t, respondCreated)
+
+	cfg := NewConfiguration(Config{
+		APIKey:        "test-key",
+		Endpoint:      server.URL,
+		BatchSize:     10,
+		BatchInterval: 50 * time.Millisecond,
+	})
+	worker := NewWorker(cfg)
+	worker.Start()
+	defer worker.Stop()
+
+	worker.Push(newTestNotice(cfg))
+
+	server.waitForRequests(t, 1, 2*time.Second)
+
+	_, bodies := server.recorded()
+	if batch, ok := bodies[0].([]interface{}); !ok || len(batch) != 1 {
+		t.Errorf("Expected a batch of 1 notice, got %v", bodies[0])
+	}
+}
+
+func TestWorkerFlushesOnFlushInterval(t *testing.T) {
+	server := newRecordingServer(t, respondCreated)
+
+	cfg := NewConfiguration(Config{
+		APIKey:        "test-key",
+		Endpoint:      server.URL,
+		BatchSize:     10,
+		BatchInterval: time.Hour,
+		FlushInterval: 50 * time.Millisecond,
+	})
+	worker := NewWorker(cfg)
+	worker.Start()
+	defer worker.Stop()
+
+	worker.Push(newTestNotice(cfg))
+
+	server.waitForRequests(t, 1, 2*time.Second)
+
+	_, bodies := server.recorded()
+	if batch, ok := bodies[0].([]interface{}); !ok || len(batch) != 1 {
+		t.Errorf("Expected a batch of 1 notice, got %v", bodies[0])
+	}
+}
+
+func TestWorkerFlushSendsPartialBatch(t *testing.T) {
+	server := newRecordingServer(t, respondCreated)
+
+	cfg := NewConfiguration(Config{
+		APIKey:        "test-key",
+		Endpoint:      server.URL,
+		BatchSize:     10,
+		BatchInterval: time.Hour,
+	})
+	worker := NewWorker(cfg)
+	worker.Start()
+	defer worker.Stop()
+
+	worker.Push(newTestNotice(cfg))
+	worker.Push(newTestNotice(cfg))
+	worker.Flush()
+
+	paths, _ := server.recorded()
+	if len(paths) != 1 {
+		t.Errorf("Expected flush to send 1 batch request, got %d", len(paths))
+	}
+}
+
+func TestWorkerWithoutBatching(t *testing.T) {
+	server := newRecordingServer(t, respondCreated)
+
+	cfg := NewConfiguration(Config{APIKey: "test-key", Endpoint: server.URL})
+	worker := NewWorker(cfg)
+	worker.Start()
+	defer worker.Stop()
+
+	worker.Push(newTestNotice(cfg))
+	server.waitForRequests(t, 1, 2*time.Second)
+
+	paths, _ := server.recorded()
+	if paths[0] != "/ingest/v1/errors" {
+		t.Errorf("Expected single notice endpoint, got %s", paths[0])
+	}
+}
+
+func TestWorkerClampsInvalidQueueSize(t *testing.T) {
+	server := newRecordingServer(t, respondCreated)
+
+	for _, size := range []int{0, -5} {
+		cfg := NewConfiguration(Config{APIKey: "test-key", Endpoint: server.URL})
+		cfg.MaxQueueSize = size
+
+		worker := NewWorker(cfg)
+		if cap(worker.queue) != MinQueueSize {
+			t.Errorf("MaxQueueSize %d: expected queue capacity %d, got %d", size, MinQueueSize, cap(worker.queue))
+		}
+		worker.Start()
+		worker.Push(newTestNotice(cfg))
+		worker.Stop()
+	}
+}
+
+func TestWorkerFallsBackToSecondaryEndpoint(t *testing.T) {
+	primary := newRecordingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	fallback := newRecordingServer(t, respondCreated)
+
+	cfg := NewConfiguration(Config{
+		APIKey:           "test-key",
+		Endpoint:         primary.URL,
+		FallbackEndpoint: fallback.URL,
+	})
+	worker := NewWorker(cfg)
+	worker.Start()
+	defer worker.Stop()
+
+	worker.Push(newTestNotice(cfg))
+	primary.waitForRequests(t, 3, 2*time.Second)
+	fallback.waitForRequests(t, 1, 2*time.Second)
+
+	paths, _ := fallback.recorded()
+	if paths[0] != "/ingest/v1/errors" {
+		t.Errorf("Expected fallback to receive the notice, got %s", paths[0])
+	}
+}
+
+func TestWorkerFallsBackToSecondaryEndpointForBatches(t *testing.T) {
+	primary := newRecordingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	fallback := newRecordingServer(t, respondCreated)
+
+	cfg := NewConfiguration(Config{
+		APIKey:           "test-key",
+		Endpoint:         primary.URL,
+		FallbackEndpoint: fallback.URL,
+		BatchSize:        2,
+	})
+	worker := NewWorker(cfg)
+	worker.Start()
+	defer worker.Stop()
+
+	worker.Push(newTestNotice(cfg))
+	worker.Push(newTestNotice(cfg))
+	primary.waitForRequests(t, 3, 2*time.Second)
+	fallback.waitForRequests(t, 1, 2*time.Second)
+
+	paths, bodies := fallback.recorded()
+	if paths[0] != "/ingest/v1/errors/batch" {
+		t.Errorf("Expected fallback to receive the batch, got %s", paths[0])
+	}
+	if batch, ok := bodies[0].([]interface{}); !ok || len(batch) != 2 {
+		t.Errorf("Expected a batch of 2 notices, got %v", bodies[0])
+	}
+}
+
+func TestWorkerDoesNotRetryRejectedNotices(t *testing.T) {
+	resetStats()
+	defer resetStats()
+
+	primary := newRecordingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	})
+	fallback := newRecordingServer(t, respondCreated)
+
+	for _, batchSize := range []int{0, 2} {
+		cfg := NewConfiguration(Config{
+			APIKey:           "test-key",
+			Endpoint:         primary.URL,
+			FallbackEndpoint: fallback.URL,
+			BatchSize:        batchSize,
+		})
+		worker := NewWorker(cfg)
+		worker.Start()
+		worker.Push(newTestNotice(cfg))
+		worker.Push(newTestNotice(cfg))
+		worker.Stop()
+	}
+
+	if paths, _ := primary.recorded(); len(paths) != 3 {
+		t.Errorf("Expected each request to be sent once, got %d requests", len(paths))
+	}
+	if paths, _ := fallback.recorded(); len(paths) != 0 {
+		t.Errorf("Expected rejected notices not to be sent to the fallback, got %d requests", len(paths))
+	}
+	if stats := Stats(); stats.Retried != 0 || stats.Failed != 4 {
+		t.Errorf("Expected 4 failed notices and no retries, got %+v", stats)
+	}
+}
+
+func TestWorkerDropsNoticesWithDoneContext(t *testing.T) {
+	server := newRecordingServer(t, respondCreated)
+
+	cfg := NewConfiguration(Config{APIKey: "test-key", Endpoint: server.URL})
+	worker := NewWorker(cfg)
+	worker.Start()
+	defer worker.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	abandoned := newTestNotice(cfg)
+	abandoned.ctx = ctx
+	live := newTestNotice(cfg)
+	live.ctx = context.Background()
+
+	worker.Push(abandoned)
+	worker.Push(live)
+	worker.Flush()
+
+	if paths, _ := server.recorded(); len(paths) != 1 {
+		t.Errorf("Expected only the live notice to be sent, got %d requests", len(paths))
+	}
+	if worker.Dropped() != 1 {
+		t.Errorf("Expected 1 dropped notice, got %d", worker.Dropped())
+	}
+}
+
+// newStalledWorker returns a worker that accepts pushes but never sends.
+func newStalledWorker(cfg *Configuration) *Worker {
+	worker := NewWorker(cfg)
+	worker.running = true
+	return worker
+}
+
+func TestWorkerQueueFullDrop(t *testing.T) {
+	resetStats()
+	defer resetStats()
+
+	cfg := NewConfiguration(Config{APIKey: "test-key", MaxQueueSize: 1})
+	worker := newStalledWorker(cfg)
+
+	first := newTestNotice(cfg)
+	if !worker.Push(first) {
+		t.Fatal("Expected first notice to be queued")
+	}
+	if worker.Push(newTestNotice(cfg)) {
+		t.Error("Expected second notice to be dropped")
+	}
+	if worker.Dropped() != 1 {
+		t.Errorf("Expected 1 dropped notice, got %d", worker.Dropped())
+	}
+	if Stats().Dropped != 1 {
+		t.Errorf("Expected Stats to report 1 dropped notice, got %d", Stats().Dropped)
+	}
+	if queued := <-worker.queue; queued != first {
+		t.Error("Expected the first notice to stay queued")
+	}
+}
+
+func TestWorkerQueueFullDropOldest(t *testing.T) {
+	cfg := NewConfiguration(Config{
+		APIKey:          "test-key",
+		MaxQueueSize:    1,
+		QueueFullPolicy: QueueFullDropOldest,
+	})
+	worker := newStalledWorker(cfg)
+
+	worker.Push(newTestNotice(cfg))
+	second := newTestNotice(cfg)
+	if !worker.Push(second) {
+		t.Fatal("Expected second notice to be queued")
+	}
+	if worker.Dropped() != 1 {
+		t.Errorf("Expected 1 dropped notice, got %d", worker.Dropped())
+	}
+	if worker.Pending() != 1 {
+		t.Errorf("Expected 1 pending notice, got %d", worker.Pending())
+	}
+	if queued := <-worker.queue; queued != second {
+		t.Error("Expected the newest notice to be queued")
+	}
+}
+
+func TestWorkerQueueFullBlockTimesOut(t *testing.T) {
+	cfg := NewConfiguration(Config{
+		APIKey:           "test-key",
+		MaxQueueSize:     1,
+		QueueFullPolicy:  QueueFullBlock,
+		QueueFullTimeout: 50 * time.Millisecond,
+	})
+	worker := newStalledWorker(cfg)
+
+	worker.Push(newTestNotice(cfg))
+
+	start := time.Now()
+	if worker.Push(newTestNotice(cfg)) {
+		t.Error("Expected push to give up on a full queue")
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Expected push to block for the timeout, returned after %v", elapsed)
+	}
+	if worker.Dropped() != 1 {
+		t.Errorf("Expected 1 dropped notice, got %d", worker.Dropped())
+	}
+}
+
+func TestWorkerQueueFullBlockWaitsForRoom(t *testing.T) {
+	cfg := NewConfiguration(Config{
+		APIKey:           "test-key",
+		MaxQueueSize:     1,
+		QueueFullPolicy:  QueueFullBlock,
+		QueueFullTimeout: 2 * time.Second,
+	})
+	worker := newStalledWorker(cfg)
+
+	worker.Push(newTestNotice(cfg))
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		<-worker.queue
+	}()
+
+	if !worker.Push(newTestNotice(cfg)) {
+		t.Error("Expected push to succeed once the queue had room")
+	}
+	if worker.Dropped() != 0 {
+		t.Errorf("Expected no dropped notices, got %d", worker.Dropped())
+	}
+}
+
+func TestParseBatchResponse(t *testing.T) {
+	resps, err := parseBatchResponse([]byte(`[{"id": 1, "problem_id": 10}, {"id": 2, "problem_id": 20}]`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(resps) != 2 || resps[1].ProblemID != 20 {
+		t.Errorf("Unexpected responses %+v", resps)
+	}
+
+	resps, err = parseBatchResponse([]byte(`{"id": 3, "problem_id": 30}`))
+	if err != nil || len(resps) != 1 || resps[0].ID != 3 {
+		t.Errorf("Expected single object to be accepted, got %+v (%v)", resps, err)
+	}
+
+	if _, err := parseBatchResponse([]byte(`not json`)); err == nil {
+		t.Error("Expected error for invalid response")
+	}
+}
+
+// stopBlockedWorker unblocks the test server and waits for the worker to
+// exit, so its shutdown doesn't leak into later tests.
+func stopBlockedWorker(worker *Worker, release chan struct{}) {
+	close(release)
+	worker.Stop()
+	worker.wg.Wait()
+}
+
+func TestWorkerFlushCtxReturnsOnCancel(t *testing.T) {
+	release := make(chan struct{})
+	server := newRecordingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		respondCreated(w, r)
+	})
+
+	cfg := NewConfiguration(Config{
+		APIKey:          "test-key",
+		Endpoint:        server.URL,
+		ShutdownTimeout: 50 * time.Millisecond,
+	})
+	worker := NewWorker(cfg)
+	worker.Start()
+	defer stopBlockedWorker(worker, release)
+
+	for i := 0; i < 5; i++ {
+		worker.Push(newTestNotice(cfg))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	pending := worker.FlushCtx(ctx)
+	elapsed := time.Since(start)
+
+	if elapsed > time.Second {
+		t.Errorf("Expected FlushCtx to return promptly, took %v", elapsed)
+	}
+	if pending != 5 {
+		t.Errorf("Expected 5 pending notices, got %d", pending)
+	}
+}
+
+func TestWorkerFlushCtxCompletes(t *testing.T) {
+	server := newRecordingServer(t, respondCreated)
+
+	cfg := NewConfiguration(Config{APIKey: "test-key", Endpoint: server.URL})
+	worker := NewWorker(cfg)
+	worker.Start()
+	defer worker.Stop()
+
+	for i := 0; i < 3; i++ {
+		worker.Push(newTestNotice(cfg))
+	}
+
+	if pending := worker.FlushCtx(context.Background()); pending != 0 {
+		t.Errorf("Expected no pending notices after flush, got %d", pending)
+	}
+}
+
+func TestWorkerFlushWithTimeoutExpires(t *testing.T) {
+	release := make(chan struct{})
+	server := newRecordingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		respondCreated(w, r)
+	})
+
+	cfg := NewConfiguration(Config{
+		APIKey:          "test-key",
+		Endpoint:        server.URL,
+		ShutdownTimeout: 50 * time.Millisecond,
+	})
+	worker := NewWorker(cfg)
+	worker.Start()
+	defer stopBlockedWorker(worker, release)
+
+	worker.Push(newTestNotice(cfg))
+	worker.Push(newTestNotice(cfg))
+
+	err := worker.FlushWithTimeout(50 * time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if worker.Pending() != 2 {
+		t.Errorf("Expected notices to stay queued, got %d pending", worker.Pending())
+	}
+}
+
+func TestWorkerFlushWithTimeoutCompletes(t *testing.T) {
+	server := newRecordingServer(t, respondCreated)
+
+	cfg := NewConfiguration(Config{APIKey: "test-key", Endpoint: server.URL})
+	worker := NewWorker(cfg)
+	worker.Start()
+	defer worker.Stop()
+
+	worker.Push(newTestNotice(cfg))
+
+	if err := worker.FlushWithTimeout(2 * time.Second); err != nil {
+		t.Errorf("Expected flush to complete, got %v", err)
+	}
+}
+
+func TestWorkerDropsStaleNotices(t *testing.T) {
+	server := newRecordingServer(t, respondCreated)
+
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock = func() time.Time { return base.Add(90 * time.Minute) }
+	defer func() { clock = time.Now }()
+
+	cfg := NewConfiguration(Config{
+		APIKey:    "test-key",
+		Endpoint:  server.URL,
+		NoticeTTL: time.Hour,
+	})
+	worker := NewWorker(cfg)
+	worker.Start()
+
+	stale := newTestNotice(cfg)
+	stale.OccurredAt = base
+	stale.Message = "stale"
+	fresh := newTestNotice(cfg)
+	fresh.OccurredAt = base.Add(time.Hour)
+	fresh.Message = "fresh"
+
+	worker.Push(stale)
+	worker.Push(fresh)
+	worker.Flush()
+	worker.Stop()
+
+	_, bodies := server.recorded()
+	if len(bodies) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(bodies))
+	}
+	errorPayload := bodies[0].(map[string]interface{})["error"].(map[string]interface{})
+	if errorPayload["message"] != "fresh" {
+		t.Errorf("Expected the fresh notice to be sent, got %v", errorPayload["message"])
+	}
+	if worker.Dropped() != 1 {
+		t.Errorf("Expected 1 dropped notice, got %d", worker.Dropped())
+	}
+}
+
+func TestWorkerDropsStaleNoticesFromBatch(t *testing.T) {
+	server := newRecordingServer(t, respondCreated)
+
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock = func() time.Time { return base.Add(2 * time.Hour) }
+	defer func() { clock = time.Now }()
+
+	cfg := NewConfiguration(Config{
+		APIKey:        "test-key",
+		Endpoint:      server.URL,
+		NoticeTTL:     time.Hour,
+		BatchSize:     10,
+		BatchInterval: time.Hour,
+	})
+	worker := NewWorker(cfg)
+	worker.Start()
+
+	for _, age := range []time.Duration{3 * time.Hour, 30 * time.Minute, 2*time.Hour + time.Second} {
+		notice := newTestNotice(cfg)
+		notice.OccurredAt = base.Add(2*time.Hour - age)
+		worker.Push(notice)
+	}
+	worker.Flush()
+	worker.Stop()
+
+	_, bodies := server.recorded()
+	if len(bodies) != 1 {
+		t.Fatalf("Expected 1 batch request, got %d", len(bodies))
+	}
+	if batch := bodies[0].([]interface{}); len(batch) != 1 {
+		t.Errorf("Expected 1 notice in the batch, got %d", len(batch))
+	}
+	if worker.Dropped() != 2 {
+		t.Errorf("Expected 2 dropped notices, got %d", worker.Dropped())
+	}
+	if worker.Pending() != 0 {
+		t.Errorf("Expected no pending notices, got %d", worker.Pending())
+	}
+}
+
+func TestWorkerUsesConfiguredIngestPaths(t *testing.T) {
+	tests := []struct {
+		name      string
+		batchSize int
+		expected  string
+	}{
+		{"single", 1, "/v2/errors"},
+		{"batch", 2, "/v2/errors/bulk"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newRecordingServer(t, respondCreated)
+
+			cfg := NewConfiguration(Config{
+				APIKey:          "test-key",
+				Endpoint:        server.URL,
+				IngestPath:      "/v2/errors",
+				BatchIngestPath: "/v2/errors/bulk",
+				BatchSize:       tt.batchSize,
+				BatchInterval:   time.Hour,
+			})
+			worker := NewWorker(cfg)
+			worker.Start()
+			defer worker.Stop()
+
+			for i := 0; i < tt.batchSize; i++ {
+				worker.Push(newTestNotice(cfg))
+			}
+
+			server.waitForRequests(t, 1, 2*time.Second)
+
+			paths, _ := server.recorded()
+			if len(paths) != 1 || paths[0] != tt.expected {
+				t.Errorf("Expected a request to %s, got %v", tt.expected, paths)
+			}
+		})
+	}
+}
+
+func TestWorkerHoldsNoticesDuringStartupGracePeriod(t *testing.T) {
+	server := newRecordingServer(t, respondCreated)
+
+	cfg := NewConfiguration(Config{
+		APIKey:             "test-key",
+		Endpoint:           server.URL,
+		StartupGracePeriod: 300 * time.Millisecond,
+	})
+	worker := NewWorker(cfg)
+	start := time.Now()
+	worker.Start()
+	defer worker.Stop()
+
+	worker.Push(newTestNotice(cfg))
+	worker.Push(newTestNotice(cfg))
+
+	time.Sleep(100 * time.Millisecond)
+	if paths, _ := server.recorded(); len(paths) != 0 {
+		t.Fatalf("Expected no requests during the grace period, got %d", len(paths))
+	}
+	if pending := worker.Pending(); pending != 2 {
+		t.Errorf("Expected 2 held notices, got %d", pending)
+	}
+
+	worker.Flush()
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+		t.Errorf("Expected flush to wait for the grace period, returned after %v", elapsed)
+	}
+	if paths, _ := server.recorded(); len(paths) != 2 {
+		t.Errorf("Expected held notices to be sent after the grace period, got %d requests", len(paths))
+	}
+}
+
+func TestWorkerStopEndsStartupGracePeriod(t *testing.T) {
+	server := newRecordingServer(t, respondCreated)
+
+	cfg := NewConfiguration(Config{
+		APIKey:             "test-key",
+		Endpoint:           server.URL,
+		StartupGracePeriod: time.Hour,
+	})
+	worker := NewWorker(cfg)
+	worker.Start()
+
+	worker.Push(newTestNotice(cfg))
+
+	start := time.Now()
+	worker.Stop()
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected stop to end the grace period, took %v", elapsed)
+	}
+	if paths, _ := server.recorded(); len(paths) != 1 {
+		t.Errorf("Expected the held notice to be sent on stop, got %d requests", len(paths))
+	}
+}
+
+func TestWorkerRetryDelayJitter(t *testing.T) {
+	defer random.Seed(time.Now().UnixNano())
+
+	cfg := NewConfiguration(Config{
+		APIKey:           "test-key",
+		RetryBackoffBase: 100 * time.Millisecond,
+		RetryBackoffMax:  300 * time.Millisecond,
+	})
+	worker := NewWorker(cfg)
+
+	random.Seed(42)
+	var delays []time.Duration
+	for attempt := 0; attempt < 5; attempt++ {
+		delay := worker.retryDelay(attempt)
+		limit := 100 * time.Millisecond << uint(attempt)
+		if limit > 300*time.Millisecond {
+			limit = 300 * time.Millisecond
+		}
+		if delay < 0 || delay > limit {
+			t.Errorf("Attempt %d: expected a delay up to %v, got %v", attempt, limit, delay)
+		}
+		delays = append(delays, delay)
+	}
+
+	random.Seed(42)
+	for attempt, want := range delays {
+		if got := worker.retryDelay(attempt); got != want {
+			t.Errorf("Attempt %d: expected the same seed to give %v, got %v", attempt, want, got)
+		}
+	}
+}